@@ -0,0 +1,189 @@
+package app
+
+import (
+	"context"
+	"financial-track/authtoken"
+	"financial-track/controller"
+	"financial-track/job"
+	"financial-track/loginguard"
+	"financial-track/mailer"
+	"financial-track/middleware"
+	"financial-track/oidc"
+	"financial-track/ratelimit"
+	"financial-track/repository"
+	"financial-track/route"
+	"financial-track/usecase"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Deps are the external dependencies. DB and Cursors are required; other empty
+// fields get local defaults (log mailer, in-memory login guard, no OIDC).
+// NewDeps builds them from the config.
+type Deps struct {
+	DB      *gorm.DB
+	Cursors usecase.CursorCodec
+	Tokens  *authtoken.Service
+	Mailer  mailer.Mailer
+	Guard   *loginguard.Guard
+	OIDC    *oidc.Registry
+	Clock   usecase.Clock
+	App     usecase.AppInfo
+	// Location is the zone for client dates; nil means time.Local.
+	Location *time.Location
+}
+
+// App is the composition root. Apps are independent, so several can live in
+// one process (tests).
+type App struct {
+	DB     *gorm.DB
+	Tokens *authtoken.Service
+
+	Users       *usecase.UserUseCase
+	Expenses    *usecase.ExpenseUseCase
+	Sessions    *usecase.SessionUseCase
+	APIKeys     *usecase.APIKeyUseCase
+	Audit       *usecase.AuditUseCase
+	SocialLogin *usecase.SocialLoginUseCase
+
+	userRepo        *repository.UserRepository
+	tokenRepo       *repository.TokenRepository
+	sessionRepo     *repository.SessionRepository
+	idempotencyRepo *repository.IdempotencyRepository
+	guard           *loginguard.Guard
+	clock           usecase.Clock
+
+	jobs sync.WaitGroup
+}
+
+// RouterConfig holds the middleware policies of the HTTP server.
+type RouterConfig struct {
+	Limiter        *ratelimit.Limiter
+	AuthRateLimit  ratelimit.Policy
+	APIRateLimit   ratelimit.Policy
+	IdempotencyTTL time.Duration
+	RequestTimeout time.Duration
+	// TrustedProxies may set X-Forwarded-For for c.ClientIP().
+	TrustedProxies []string
+}
+
+func New(deps Deps) *App {
+	if deps.Mailer == nil {
+		deps.Mailer = mailer.NewLogMailer("")
+	}
+	if deps.OIDC == nil {
+		deps.OIDC = oidc.NewRegistry(nil)
+	}
+	if deps.Clock == nil {
+		deps.Clock = usecase.SystemClock{}
+	}
+	if deps.Guard == nil {
+		deps.Guard = loginguard.NewGuard(loginguard.NewMemoryStore(), deps.Clock)
+	}
+	if deps.Location == nil {
+		deps.Location = time.Local
+	}
+	// CLI commands have no token service; pass a nil interface, not a nil
+	// *authtoken.Service.
+	var tokens usecase.TokenIssuer
+	if deps.Tokens != nil {
+		tokens = deps.Tokens
+	}
+
+	a := &App{
+		DB:              deps.DB,
+		Tokens:          deps.Tokens,
+		userRepo:        repository.NewUserRepository(deps.DB, deps.Clock),
+		tokenRepo:       repository.NewTokenRepository(deps.DB, deps.Clock),
+		sessionRepo:     repository.NewSessionRepository(deps.DB, deps.Clock),
+		idempotencyRepo: repository.NewIdempotencyRepository(deps.DB, deps.Clock),
+		guard:           deps.Guard,
+		clock:           deps.Clock,
+	}
+
+	apiKeyRepo := repository.NewAPIKeyRepository(deps.DB, deps.Clock)
+	a.Users = usecase.NewUserUseCase(
+		a.userRepo,
+		a.tokenRepo,
+		a.sessionRepo,
+		apiKeyRepo,
+		repository.NewUserTokenRepository(deps.DB, deps.Clock),
+		repository.NewTwoFactorRepository(deps.DB, deps.Clock),
+		repository.NewSecurityEventRepository(deps.DB),
+		deps.Mailer,
+		deps.Guard,
+		tokens,
+		deps.Clock,
+		deps.App,
+	)
+	a.Expenses = usecase.NewExpenseUseCase(repository.NewExpenseRepository(deps.DB), deps.Clock, deps.Cursors, deps.Location)
+	a.Sessions = usecase.NewSessionUseCase(a.sessionRepo)
+	a.APIKeys = usecase.NewAPIKeyUseCase(apiKeyRepo, deps.Clock)
+	a.Audit = usecase.NewAuditUseCase(repository.NewAuditRepository(deps.DB))
+	a.SocialLogin = usecase.NewSocialLoginUseCase(a.Users, repository.NewIdentityRepository(deps.DB, deps.Clock), deps.OIDC)
+
+	return a
+}
+
+// Router builds the gin engine with every route. Tokens must be set.
+func (a *App) Router(config RouterConfig) *gin.Engine {
+	controllers := route.Controllers{
+		User:    controller.NewUserController(a.Users),
+		Session: controller.NewSessionController(a.Sessions),
+		Profile: controller.NewProfileController(a.Users),
+		OIDC:    controller.NewOIDCController(a.SocialLogin),
+		APIKey:  controller.NewAPIKeyController(a.APIKeys),
+		Audit:   controller.NewAuditController(a.Audit),
+		Expense: controller.NewExpenseController(a.Expenses),
+	}
+
+	server := gin.Default()
+	if err := server.SetTrustedProxies(config.TrustedProxies); err != nil {
+		// An invalid list makes gin trust no proxy.
+		log.Println("⚠️ Invalid trusted proxies, ignoring X-Forwarded-For:", err)
+	}
+	server.Use(middleware.RequestID(), middleware.Timeout(config.RequestTimeout))
+
+	route.RegisterHealthRoutes(server)
+	route.RegisterWellKnownRoutes(server, a.Tokens)
+	route.RegisterUserRoutes(server, controllers, middleware.RateLimitByIP(config.Limiter, config.AuthRateLimit))
+
+	auth := server.Group("/")
+	auth.Use(
+		middleware.AuthMiddleware(a.Tokens, a.userRepo, a.tokenRepo, a.sessionRepo, a.APIKeys, a.clock),
+		middleware.RateLimitByUser(config.Limiter, config.APIRateLimit),
+		middleware.Idempotency(a.idempotencyRepo, config.IdempotencyTTL, a.clock),
+	)
+
+	// Authenticated routes
+	route.RegisterAuthenticatedUserRoutes(auth, controllers)
+	route.RegisterExpenseRoutes(auth, controllers.Expense)
+
+	return server
+}
+
+// StartJobs runs the maintenance jobs until ctx is done.
+func (a *App) StartJobs(ctx context.Context, trashRetention time.Duration) {
+	a.jobs.Add(3)
+	go func() {
+		defer a.jobs.Done()
+		job.RunTrashPurge(ctx, a.Expenses, trashRetention, time.Hour)
+	}()
+	go func() {
+		defer a.jobs.Done()
+		job.RunIdempotencyCleanup(ctx, a.idempotencyRepo, time.Hour)
+	}()
+	go func() {
+		defer a.jobs.Done()
+		job.RunLoginAttemptCleanup(ctx, a.guard, time.Hour)
+	}()
+}
+
+// WaitJobs waits for the StartJobs goroutines after their ctx is done.
+func (a *App) WaitJobs() {
+	a.jobs.Wait()
+}
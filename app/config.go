@@ -0,0 +1,90 @@
+package app
+
+import (
+	"financial-track/config"
+	"financial-track/loginguard"
+	"financial-track/mailer"
+	"financial-track/oidc"
+	"financial-track/ratelimit"
+	"financial-track/usecase"
+	"financial-track/utils"
+
+	"gorm.io/gorm"
+)
+
+// NewDeps builds the dependencies from a validated config. Tokens is left
+// empty; only the server loads JWT keys.
+func NewDeps(cfg *config.Config, db *gorm.DB) Deps {
+	clock := usecase.SystemClock{}
+	return Deps{
+		DB:       db,
+		Cursors:  utils.NewCursorCodec([]byte(cfg.Cursor.Secret)),
+		Mailer:   newMailer(cfg.Mail),
+		Guard:    newLoginGuard(cfg.LoginGuard, db, clock),
+		OIDC:     newOIDCRegistry(cfg.OIDC),
+		Clock:    clock,
+		App:      usecase.AppInfo{Name: cfg.App.Name, URL: cfg.App.URL},
+		Location: cfg.Location(),
+	}
+}
+
+// NewRouterConfig builds the middleware policies from a validated config.
+func NewRouterConfig(cfg *config.Config, db *gorm.DB) (RouterConfig, error) {
+	authPolicy, err := ratelimit.ParsePolicy("auth", cfg.RateLimit.Auth)
+	if err != nil {
+		return RouterConfig{}, err
+	}
+	apiPolicy, err := ratelimit.ParsePolicy("api", cfg.RateLimit.API)
+	if err != nil {
+		return RouterConfig{}, err
+	}
+
+	var store ratelimit.Store = ratelimit.NewMemoryStore()
+	if cfg.RateLimit.Store == "postgres" {
+		store = ratelimit.NewPostgresStore(db)
+	}
+
+	return RouterConfig{
+		Limiter:        ratelimit.NewLimiter(store),
+		AuthRateLimit:  authPolicy,
+		APIRateLimit:   apiPolicy,
+		IdempotencyTTL: cfg.Idempotency.TTL.Duration,
+		RequestTimeout: cfg.Server.RequestTimeout.Duration,
+		TrustedProxies: cfg.Server.TrustedProxies,
+	}, nil
+}
+
+func newMailer(cfg config.MailConfig) mailer.Mailer {
+	if cfg.Driver == "smtp" {
+		return mailer.NewSMTPMailer(mailer.SMTPConfig{
+			Host:     cfg.SMTP.Host,
+			Port:     cfg.SMTP.Port,
+			Username: cfg.SMTP.Username,
+			Password: cfg.SMTP.Password,
+			From:     cfg.From,
+		})
+	}
+	return mailer.NewLogMailer(cfg.LogDir)
+}
+
+func newLoginGuard(cfg config.LoginGuardConfig, db *gorm.DB, clock loginguard.Clock) *loginguard.Guard {
+	if cfg.Store == "memory" {
+		return loginguard.NewGuard(loginguard.NewMemoryStore(), clock)
+	}
+	return loginguard.NewGuard(loginguard.NewPostgresStore(db), clock)
+}
+
+func newOIDCRegistry(cfg config.OIDCConfig) *oidc.Registry {
+	configs := make([]oidc.Config, 0, len(cfg.Providers))
+	for _, p := range cfg.Providers {
+		configs = append(configs, oidc.Config{
+			Name:         p.Name,
+			Issuer:       p.Issuer,
+			ClientID:     p.ClientID,
+			ClientSecret: p.ClientSecret,
+			RedirectURL:  p.RedirectURL,
+			Scopes:       p.Scopes,
+		})
+	}
+	return oidc.NewRegistry(configs)
+}
@@ -0,0 +1,58 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"financial-track/config"
+	"log"
+	"net"
+	"net/http"
+	"time"
+)
+
+// Serve serves handler until ctx is done, then drains open connections for up
+// to cfg.ShutdownTimeout.
+func Serve(ctx context.Context, handler http.Handler, cfg config.ServerConfig) error {
+	server := &http.Server{
+		Addr:              ":" + cfg.Port,
+		Handler:           handler,
+		ReadHeaderTimeout: cfg.ReadHeaderTimeout.Duration,
+		ReadTimeout:       cfg.ReadTimeout.Duration,
+		WriteTimeout:      cfg.WriteTimeout.Duration,
+		IdleTimeout:       cfg.IdleTimeout.Duration,
+	}
+
+	listener, err := net.Listen("tcp", server.Addr)
+	if err != nil {
+		return err
+	}
+	return serve(ctx, server, listener, cfg.ShutdownTimeout.Duration)
+}
+
+// serve runs server on listener until ctx is done, then drains it.
+func serve(ctx context.Context, server *http.Server, listener net.Listener, shutdownTimeout time.Duration) error {
+	errCh := make(chan error, 1)
+	go func() {
+		log.Printf("🚀 Server listening on %s", listener.Addr())
+		errCh <- server.Serve(listener)
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+	}
+
+	log.Println("🛑 Shutting down server, draining connections...")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		// Deadline passed: drop the remaining connections.
+		server.Close()
+		return err
+	}
+	if err := <-errCh; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
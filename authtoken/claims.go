@@ -0,0 +1,88 @@
+package authtoken
+
+import (
+	"errors"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+const preAuthPurpose = "2fa"
+
+type AccessClaims struct {
+	UserID    string
+	SessionID string
+	JTI       string
+	ExpiresAt time.Time
+}
+
+func (s *Service) IssueAccessToken(userID uuid.UUID, sessionID uuid.UUID) (string, error) {
+	now := s.clock.Now()
+	return s.Sign(jwt.MapClaims{
+		"userId": userID,
+		"sid":    sessionID,
+		"jti":    uuid.NewString(),
+		"exp":    now.Add(AccessTokenTTL).Unix(),
+		"iat":    now.Unix(),
+	})
+}
+
+func (s *Service) ParseAccessToken(tokenStr string) (*AccessClaims, error) {
+	claims, err := s.Verify(tokenStr)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := claims["purpose"]; ok {
+		return nil, ErrInvalidToken
+	}
+
+	userID, _ := claims["userId"].(string)
+	if _, err := uuid.Parse(userID); err != nil {
+		return nil, errors.New("invalid user id in token")
+	}
+	sessionID, _ := claims["sid"].(string)
+	if _, err := uuid.Parse(sessionID); err != nil {
+		return nil, errors.New("invalid session id in token")
+	}
+	jti, _ := claims["jti"].(string)
+	if jti == "" {
+		return nil, errors.New("invalid token id")
+	}
+	exp, err := claims.GetExpirationTime()
+	if err != nil || exp == nil {
+		return nil, errors.New("invalid token expiration")
+	}
+
+	return &AccessClaims{
+		UserID:    userID,
+		SessionID: sessionID,
+		JTI:       jti,
+		ExpiresAt: exp.Time,
+	}, nil
+}
+
+// IssuePreAuthToken issues the intermediate 2FA login token. It has no jti or
+// sid, so it is never accepted as an access token.
+func (s *Service) IssuePreAuthToken(userID uuid.UUID) (string, error) {
+	now := s.clock.Now()
+	return s.Sign(jwt.MapClaims{
+		"userId":  userID,
+		"purpose": preAuthPurpose,
+		"exp":     now.Add(PreAuthTokenTTL).Unix(),
+		"iat":     now.Unix(),
+	})
+}
+
+func (s *Service) ParsePreAuthToken(tokenStr string) (uuid.UUID, error) {
+	claims, err := s.Verify(tokenStr)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	if claims["purpose"] != preAuthPurpose {
+		return uuid.Nil, ErrInvalidToken
+	}
+
+	userID, _ := claims["userId"].(string)
+	return uuid.Parse(userID)
+}
@@ -0,0 +1,118 @@
+package authtoken
+
+import (
+	"crypto"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Load builds the service from:
+//
+//   - keysDir (JWT_KEYS_DIR): PEM keys (RSA or Ed25519), with the file name
+//     without extension as kid. Private keys sign; public keys (*.pub.pem) only
+//     verify older tokens.
+//   - activeKID (JWT_ACTIVE_KID): kid of the signing key. Optional when the
+//     directory has a single private key.
+//
+// Without keysDir it uses HS256 with secret (JWT_SECRET).
+func Load(keysDir, activeKID string, secret []byte, clock Clock) (*Service, error) {
+	if keysDir == "" {
+		return NewHMACService(secret, clock)
+	}
+	return LoadFromDir(keysDir, activeKID, clock)
+}
+
+func LoadFromDir(dir string, activeKID string, clock Clock) (*Service, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.pem"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+
+	var keys []*Key
+	var privateKIDs []string
+	for _, path := range paths {
+		key, err := loadKeyFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", filepath.Base(path), err)
+		}
+		keys = append(keys, key)
+		if key.PrivateKey != nil {
+			privateKIDs = append(privateKIDs, key.ID)
+		}
+	}
+
+	if activeKID == "" {
+		if len(privateKIDs) != 1 {
+			return nil, fmt.Errorf("JWT_ACTIVE_KID is required when %s has %d private keys", dir, len(privateKIDs))
+		}
+		activeKID = privateKIDs[0]
+	}
+
+	var signing *Key
+	for _, key := range keys {
+		if key.ID == activeKID && key.PrivateKey != nil {
+			signing = key
+		}
+	}
+	if signing == nil {
+		return nil, fmt.Errorf("private key %q not found in %s", activeKID, dir)
+	}
+
+	return NewService(signing, keys, clock)
+}
+
+func loadKeyFile(path string) (*Key, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("invalid PEM file")
+	}
+
+	kid := strings.TrimSuffix(strings.TrimSuffix(filepath.Base(path), ".pem"), ".pub")
+	key := &Key{ID: kid}
+
+	switch block.Type {
+	case "PUBLIC KEY":
+		pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		key.PublicKey = pub
+	case "PRIVATE KEY", "RSA PRIVATE KEY":
+		var parsed interface{}
+		if block.Type == "RSA PRIVATE KEY" {
+			parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+		} else {
+			parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+		}
+		if err != nil {
+			return nil, err
+		}
+		signer, ok := parsed.(crypto.Signer)
+		if !ok {
+			return nil, errors.New("unsupported private key")
+		}
+		key.PrivateKey = signer
+		key.PublicKey = signer.Public()
+	default:
+		return nil, fmt.Errorf("unsupported PEM block %q", block.Type)
+	}
+
+	method, err := methodFor(key.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+	key.Method = method
+	return key, nil
+}
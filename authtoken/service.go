@@ -0,0 +1,117 @@
+package authtoken
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	AccessTokenTTL  = 15 * time.Minute
+	RefreshTokenTTL = 30 * 24 * time.Hour
+	PreAuthTokenTTL = 5 * time.Minute
+)
+
+var ErrInvalidToken = errors.New("invalid or expired token")
+
+// Key is a signing or verification key named by kid. Public-only keys just
+// verify (e.g. the previous key during rotation).
+type Key struct {
+	ID         string
+	Method     jwt.SigningMethod
+	PrivateKey crypto.Signer
+	PublicKey  crypto.PublicKey
+	secret     []byte
+}
+
+// Clock supplies the time for iat/exp and expiry checks (usecase.SystemClock).
+type Clock interface {
+	Now() time.Time
+}
+
+// Service issues and verifies the app's JWTs.
+type Service struct {
+	signing      *Key
+	verification map[string]*Key
+	clock        Clock
+}
+
+func NewService(signing *Key, verification []*Key, clock Clock) (*Service, error) {
+	if signing == nil || (signing.PrivateKey == nil && signing.secret == nil) {
+		return nil, errors.New("a signing key is required")
+	}
+
+	s := &Service{signing: signing, verification: map[string]*Key{signing.ID: signing}, clock: clock}
+	for _, key := range verification {
+		if _, exists := s.verification[key.ID]; exists && key != signing {
+			return nil, fmt.Errorf("duplicated key id %q", key.ID)
+		}
+		s.verification[key.ID] = key
+	}
+	return s, nil
+}
+
+// NewHMACService keeps the HS256 setup through JWT_SECRET working.
+func NewHMACService(secret []byte, clock Clock) (*Service, error) {
+	if len(secret) == 0 {
+		return nil, errors.New("JWT_SECRET is empty")
+	}
+	return NewService(&Key{ID: "hs256", Method: jwt.SigningMethodHS256, secret: secret}, nil, clock)
+}
+
+func (s *Service) Sign(claims jwt.MapClaims) (string, error) {
+	token := jwt.NewWithClaims(s.signing.Method, claims)
+	token.Header["kid"] = s.signing.ID
+
+	var key interface{} = s.signing.PrivateKey
+	if s.signing.secret != nil {
+		key = s.signing.secret
+	}
+
+	signed, err := token.SignedString(key)
+	if err != nil {
+		return "", errors.New("failed to generate token")
+	}
+	return signed, nil
+}
+
+// Verify checks signature and expiry, picking the key by the header kid.
+func (s *Service) Verify(tokenStr string) (jwt.MapClaims, error) {
+	claims := jwt.MapClaims{}
+	token, err := jwt.ParseWithClaims(tokenStr, claims, s.keyFunc, jwt.WithExpirationRequired(), jwt.WithTimeFunc(s.clock.Now))
+	if err != nil || !token.Valid {
+		return nil, ErrInvalidToken
+	}
+	return claims, nil
+}
+
+func (s *Service) keyFunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	key, ok := s.verification[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown key id %q", kid)
+	}
+	// Block algorithm swaps (e.g. HS256 signed with the RSA public key).
+	if token.Method.Alg() != key.Method.Alg() {
+		return nil, errors.New("unexpected signing method")
+	}
+	if key.secret != nil {
+		return key.secret, nil
+	}
+	return key.PublicKey, nil
+}
+
+func methodFor(key crypto.PublicKey) (jwt.SigningMethod, error) {
+	switch key.(type) {
+	case *rsa.PublicKey:
+		return jwt.SigningMethodRS256, nil
+	case ed25519.PublicKey:
+		return jwt.SigningMethodEdDSA, nil
+	}
+	return nil, errors.New("unsupported key type, use RSA or Ed25519")
+}
@@ -0,0 +1,274 @@
+package authtoken
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+type testClock struct {
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	return c.now
+}
+
+// writeKey writes signer's private key (or only the public one) to
+// dir/<kid>.pem or dir/<kid>.pub.pem.
+func writeKey(t *testing.T, dir, kid string, signer crypto.Signer, public bool) {
+	t.Helper()
+	var block *pem.Block
+	var name string
+	if public {
+		der, err := x509.MarshalPKIXPublicKey(signer.Public())
+		if err != nil {
+			t.Fatal(err)
+		}
+		block, name = &pem.Block{Type: "PUBLIC KEY", Bytes: der}, kid+".pub.pem"
+	} else {
+		der, err := x509.MarshalPKCS8PrivateKey(signer)
+		if err != nil {
+			t.Fatal(err)
+		}
+		block, name = &pem.Block{Type: "PRIVATE KEY", Bytes: der}, kid+".pem"
+	}
+	if err := os.WriteFile(filepath.Join(dir, name), pem.EncodeToMemory(block), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func newRSAKey(t *testing.T) *rsa.PrivateKey {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func newEd25519Key(t *testing.T) ed25519.PrivateKey {
+	t.Helper()
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func TestAccessTokenRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	writeKey(t, dir, "rsa", newRSAKey(t), false)
+	writeKey(t, dir, "ed", newEd25519Key(t), false)
+	clock := &testClock{now: time.Now()}
+
+	load := map[string]func() (*Service, error){
+		"HS256": func() (*Service, error) {
+			return Load("", "", []byte("0123456789abcdef0123456789abcdef"), clock)
+		},
+		"RS256": func() (*Service, error) { return Load(dir, "rsa", nil, clock) },
+		"EdDSA": func() (*Service, error) { return Load(dir, "ed", nil, clock) },
+	}
+	for alg, newService := range load {
+		t.Run(alg, func(t *testing.T) {
+			s, err := newService()
+			if err != nil {
+				t.Fatal(err)
+			}
+			userID, sessionID := uuid.New(), uuid.New()
+
+			token, err := s.IssueAccessToken(userID, sessionID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			parsed, _, _ := jwt.NewParser().ParseUnverified(token, jwt.MapClaims{})
+			if parsed.Method.Alg() != alg {
+				t.Errorf("alg = %s, want %s", parsed.Method.Alg(), alg)
+			}
+
+			claims, err := s.ParseAccessToken(token)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if claims.UserID != userID.String() || claims.SessionID != sessionID.String() || claims.JTI == "" {
+				t.Fatalf("claims = %+v", claims)
+			}
+			if want := clock.now.Add(AccessTokenTTL).Truncate(time.Second); !claims.ExpiresAt.Equal(want) {
+				t.Errorf("ExpiresAt = %s, want %s", claims.ExpiresAt, want)
+			}
+		})
+	}
+}
+
+func TestKeyRotation(t *testing.T) {
+	dir := t.TempDir()
+	old, next := newEd25519Key(t), newRSAKey(t)
+	writeKey(t, dir, "2024", old, false)
+	clock := &testClock{now: time.Now()}
+
+	before, err := LoadFromDir(dir, "", clock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldToken, err := before.IssueAccessToken(uuid.New(), uuid.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Rotation: the new key signs and the old one keeps only its public half.
+	os.Remove(filepath.Join(dir, "2024.pem"))
+	writeKey(t, dir, "2024", old, true)
+	writeKey(t, dir, "2025", next, false)
+	after, err := LoadFromDir(dir, "", clock)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := after.ParseAccessToken(oldToken); err != nil {
+		t.Fatalf("token signed with the previous key rejected: %v", err)
+	}
+	newToken, err := after.IssueAccessToken(uuid.New(), uuid.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, _, _ := jwt.NewParser().ParseUnverified(newToken, jwt.MapClaims{})
+	if parsed.Header["kid"] != "2025" {
+		t.Fatalf("new token kid = %v, want 2025", parsed.Header["kid"])
+	}
+	if _, err := before.ParseAccessToken(newToken); err == nil {
+		t.Fatal("service without the new key accepted its token")
+	}
+
+	jwks := after.JWKS()
+	if len(jwks.Keys) != 2 || jwks.Keys[0].Kid != "2024" || jwks.Keys[0].Kty != "OKP" || jwks.Keys[1].Kid != "2025" || jwks.Keys[1].Alg != "RS256" {
+		t.Fatalf("JWKS = %+v, want the Ed25519 and RSA public keys", jwks)
+	}
+}
+
+func TestLoadFromDirRequiresActiveKID(t *testing.T) {
+	dir := t.TempDir()
+	writeKey(t, dir, "a", newEd25519Key(t), false)
+	writeKey(t, dir, "b", newEd25519Key(t), false)
+
+	if _, err := LoadFromDir(dir, "", &testClock{}); err == nil {
+		t.Fatal("LoadFromDir chose a signing key among two private keys")
+	}
+	if _, err := LoadFromDir(dir, "c", &testClock{}); err == nil {
+		t.Fatal("LoadFromDir accepted an unknown active kid")
+	}
+	if _, err := LoadFromDir(dir, "b", &testClock{}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestVerifyPinsAlgorithmAndKey(t *testing.T) {
+	dir := t.TempDir()
+	rsaKey := newRSAKey(t)
+	writeKey(t, dir, "rsa", rsaKey, false)
+	writeKey(t, dir, "ed", newEd25519Key(t), true)
+	clock := &testClock{now: time.Now()}
+	s, err := LoadFromDir(dir, "rsa", clock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicDER, err := x509.MarshalPKIXPublicKey(rsaKey.Public())
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER})
+
+	claims := func() jwt.MapClaims {
+		return jwt.MapClaims{
+			"userId": uuid.NewString(),
+			"sid":    uuid.NewString(),
+			"jti":    uuid.NewString(),
+			"exp":    clock.now.Add(time.Minute).Unix(),
+		}
+	}
+	sign := func(method jwt.SigningMethod, kid string, key interface{}, claims jwt.MapClaims) string {
+		token := jwt.NewWithClaims(method, claims)
+		if kid != "" {
+			token.Header["kid"] = kid
+		}
+		signed, err := token.SignedString(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return signed
+	}
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{"alg none", sign(jwt.SigningMethodNone, "rsa", jwt.UnsafeAllowNoneSignatureType, claims())},
+		{"HS256 with the RSA public key as secret", sign(jwt.SigningMethodHS256, "rsa", publicPEM, claims())},
+		{"RS256 under the Ed25519 kid", sign(jwt.SigningMethodRS256, "ed", rsaKey, claims())},
+		{"unknown kid", sign(jwt.SigningMethodRS256, "other", rsaKey, claims())},
+		{"without kid", sign(jwt.SigningMethodRS256, "", rsaKey, claims())},
+		{"signed by another RSA key", sign(jwt.SigningMethodRS256, "rsa", newRSAKey(t), claims())},
+		{"without exp", sign(jwt.SigningMethodRS256, "rsa", rsaKey, jwt.MapClaims{"userId": uuid.NewString(), "sid": uuid.NewString(), "jti": "x"})},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := s.ParseAccessToken(tt.token); !errors.Is(err, ErrInvalidToken) {
+				t.Fatalf("ParseAccessToken = %v, want ErrInvalidToken", err)
+			}
+		})
+	}
+
+	if _, err := s.ParseAccessToken(sign(jwt.SigningMethodRS256, "rsa", rsaKey, claims())); err != nil {
+		t.Fatalf("well-formed token rejected: %v", err)
+	}
+}
+
+func TestVerifyUsesClock(t *testing.T) {
+	clock := &testClock{now: time.Now()}
+	s, err := NewHMACService([]byte("0123456789abcdef0123456789abcdef"), clock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err := s.IssueAccessToken(uuid.New(), uuid.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	clock.now = clock.now.Add(AccessTokenTTL + time.Second)
+	if _, err := s.ParseAccessToken(token); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("expired token = %v, want ErrInvalidToken", err)
+	}
+}
+
+func TestTokenPurposesAreNotInterchangeable(t *testing.T) {
+	s, err := NewHMACService([]byte("0123456789abcdef0123456789abcdef"), &testClock{now: time.Now()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	userID := uuid.New()
+	preAuth, _ := s.IssuePreAuthToken(userID)
+	access, _ := s.IssueAccessToken(userID, uuid.New())
+
+	if _, err := s.ParseAccessToken(preAuth); err == nil {
+		t.Error("pre-auth token accepted as access token")
+	}
+	if _, err := s.ParsePreAuthToken(access); err == nil {
+		t.Error("access token accepted as pre-auth token")
+	}
+	if id, err := s.ParsePreAuthToken(preAuth); err != nil || id != userID {
+		t.Errorf("ParsePreAuthToken = %s, %v, want %s", id, err, userID)
+	}
+	if keys := s.JWKS().Keys; len(keys) != 0 {
+		t.Errorf("JWKS exposes the HMAC key: %+v", keys)
+	}
+}
@@ -1,42 +1,82 @@
 package main
 
 import (
+	"context"
+	"financial-track/app"
+	"financial-track/authtoken"
+	"financial-track/config"
 	"financial-track/database"
-	"financial-track/middleware"
-	"financial-track/repository"
-	"financial-track/route"
 	"log"
 	"os"
-
-	"github.com/gin-gonic/gin"
-	"github.com/joho/godotenv"
+	"os/signal"
+	"strings"
+	"syscall"
 )
 
 func main() {
-	var userRepository *repository.UserRepository = repository.NewUserRepository()
-	err := godotenv.Load()
+	cfg, err := config.Load()
 	if err != nil {
-		log.Println("⚠️ File .env not found, using environment variables")
+		log.Fatal("❌ ", err)
+	}
+	// No subcommand (or only flags) starts the server, as before.
+	name, args := "serve", os.Args[1:]
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		name, args = args[0], args[1:]
+	}
+	os.Exit(runCommand(cfg, name, args))
+}
+
+func runServe(cfg *config.Config, args []string) int {
+	flags := newFlagSet("serve")
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+
+	if err := cfg.Validate(); err != nil {
+		log.Fatal("❌ ", err)
 	}
+	log.Printf("⚙️ Effective configuration:\n%s", cfg)
 
-	database.Connect()
-	database.Migrate()
+	db := database.Connect(cfg.Database.URL, cfg.Location())
+	if cfg.Database.AutoMigrate {
+		database.Migrate(db)
+	}
 
-	port := os.Getenv("SERVER_PORT")
-	if port == "" {
-		port = "81"
+	deps := app.NewDeps(cfg, db)
+	tokens, err := authtoken.Load(cfg.JWT.KeysDir, cfg.JWT.ActiveKID, []byte(cfg.JWT.Secret), deps.Clock)
+	if err != nil {
+		log.Fatal("❌ Error to load JWT keys: ", err)
 	}
+	deps.Tokens = tokens
 
-	server := gin.Default()
+	routerConfig, err := app.NewRouterConfig(cfg, db)
+	if err != nil {
+		log.Fatal("❌ ", err)
+	}
+	application := app.New(deps)
+	router := application.Router(routerConfig)
 
-	route.RegisterHealthRoutes(server)
-	route.RegisterUserRoutes(server)
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
 
-	auth := server.Group("/")
-	auth.Use(middleware.AuthMiddleware(userRepository))
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	application.StartJobs(jobsCtx, cfg.Expenses.TrashRetention.Duration)
 
-	// Authenticated routes
-	route.RegisterExpenseRoutes(auth)
+	// Shutdown order: drain requests, stop the background jobs, and only then
+	// close the database pool both use.
+	code := exitOK
+	if err := app.Serve(ctx, router, cfg.Server); err != nil {
+		log.Println("❌ Server error:", err)
+		code = exitFailure
+	}
+
+	stopJobs()
+	application.WaitJobs()
 
-	server.Run(":" + port)
+	if err := database.Close(db); err != nil {
+		log.Println("❌ Error to close database:", err)
+		code = exitFailure
+	}
+	log.Println("👋 Server stopped")
+	return code
 }
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"financial-track/app"
+	"financial-track/config"
+	"financial-track/database"
+	"financial-track/model"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+var errMissingEmail = errors.New("-email is required")
+
+const (
+	exitOK      = 0
+	exitFailure = 1
+	exitUsage   = 2
+)
+
+type command struct {
+	name    string
+	summary string
+	run     func(cfg *config.Config, args []string) int
+}
+
+var commands []command
+
+func init() {
+	commands = []command{
+		{"serve", "Start the HTTP server (default)", runServe},
+		{"migrate", "Apply, roll back or inspect database migrations", runMigrateCommand},
+		{"create-user", "Create a user account", runCreateUser},
+		{"reset-password", "Set a new password for a user and revoke their sessions", runResetPassword},
+		{"import", "Import expenses for a user from CSV or JSON", runImport},
+		{"export", "Export a user's expenses as CSV or JSON", runExport},
+		{"seed", "Create fake users with months of realistic expenses for development", runSeed},
+		{"purge-trash", "Permanently delete expenses trashed longer than the retention", runPurgeTrash},
+	}
+}
+
+func runCommand(cfg *config.Config, name string, args []string) int {
+	if name == "help" {
+		printUsage()
+		return exitOK
+	}
+	for _, cmd := range commands {
+		if cmd.name == name {
+			return cmd.run(cfg, args)
+		}
+	}
+	fmt.Fprintf(os.Stderr, "unknown command %q\n\n", name)
+	printUsage()
+	return exitUsage
+}
+
+func printUsage() {
+	var b strings.Builder
+	b.WriteString("Usage: app <command> [flags]\n\nCommands:\n")
+	for _, cmd := range commands {
+		fmt.Fprintf(&b, "  %-16s%s\n", cmd.name, cmd.summary)
+	}
+	b.WriteString("\nRun \"app <command> -h\" for the flags of a command. Most commands accept -json.\n")
+	fmt.Fprint(os.Stderr, b.String())
+}
+
+func newFlagSet(name string) *flag.FlagSet {
+	return flag.NewFlagSet(name, flag.ContinueOnError)
+}
+
+// output writes the result as text or, with -json, as one JSON document on
+// stdout (errors go to stderr, also as JSON).
+type output struct {
+	json bool
+}
+
+func (o output) result(v interface{}, human string, humanArgs ...interface{}) int {
+	if o.json {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(v); err != nil {
+			return o.fail(err)
+		}
+		return exitOK
+	}
+	fmt.Printf(human+"\n", humanArgs...)
+	return exitOK
+}
+
+func (o output) fail(err error) int {
+	if o.json {
+		json.NewEncoder(os.Stderr).Encode(map[string]string{"error": err.Error()})
+	} else {
+		fmt.Fprintln(os.Stderr, "❌", err)
+	}
+	return exitFailure
+}
+
+// usageError reports missing or invalid required flags.
+func usageError(flags *flag.FlagSet, message string) int {
+	fmt.Fprintf(os.Stderr, "%s: %s\n", flags.Name(), message)
+	flags.Usage()
+	return exitUsage
+}
+
+// openApp connects to the database and builds the use cases shared with the
+// API. Commands issue no tokens, so JWT keys are not loaded.
+func openApp(cfg *config.Config) *app.App {
+	if err := cfg.ValidateApp(); err != nil {
+		log.Fatal("❌ ", err)
+	}
+	return app.New(app.NewDeps(cfg, connectDB(cfg)))
+}
+
+// connectDB opens the database checking only the config the CLI uses.
+func connectDB(cfg *config.Config) *gorm.DB {
+	if err := cfg.ValidateDatabase(); err != nil {
+		log.Fatal("❌ ", err)
+	}
+	return database.Connect(cfg.Database.URL, cfg.Location())
+}
+
+// cliActor marks CLI changes in the audit trail.
+func cliActor(command string) model.AuditActor {
+	return model.AuditActor{RequestID: "cli:" + command}
+}
@@ -0,0 +1,274 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"financial-track/config"
+	"financial-track/model"
+	"financial-track/usecase"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CSV columns for import and export. tags is optional on import and joins
+// labels with expenseCSVTagSeparator.
+var expenseCSVHeader = []string{"category", "amount", "description", "transactionAt", "tags"}
+
+const expenseCSVTagSeparator = "|"
+
+func runImport(cfg *config.Config, args []string) int {
+	flags := newFlagSet("import")
+	email := flags.String("email", "", "owner of the imported expenses (required)")
+	file := flags.String("file", "-", "input file, or - for stdin")
+	format := flags.String("format", "csv", "input format: csv or json")
+	jsonOut := flags.Bool("json", false, "print the result as JSON")
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	if *email == "" {
+		return usageError(flags, errMissingEmail.Error())
+	}
+	if *format != "csv" && *format != "json" {
+		return usageError(flags, "-format must be csv or json")
+	}
+
+	out := output{json: *jsonOut}
+	reader, closeInput, err := openInput(*file)
+	if err != nil {
+		return out.fail(err)
+	}
+	defer closeInput()
+
+	var inputs []model.CreateExpenseInput
+	if *format == "json" {
+		err = json.NewDecoder(reader).Decode(&inputs)
+	} else {
+		inputs, err = readExpenseCSV(reader)
+	}
+	if err != nil {
+		return out.fail(fmt.Errorf("read %s: %w", *file, err))
+	}
+
+	application := openApp(cfg)
+	user, err := application.Users.GetUserByEmail(context.Background(), *email)
+	if err != nil {
+		return out.fail(err)
+	}
+	for i := range inputs {
+		inputs[i].UserID = user.ID.String()
+	}
+
+	expenses, err := application.Expenses.ImportExpenses(context.Background(), inputs, cliActor("import"))
+	if err != nil {
+		return out.fail(err)
+	}
+	return out.result(map[string]interface{}{"email": user.Email, "imported": len(expenses)},
+		"✅ Imported %d expenses for %s", len(expenses), user.Email)
+}
+
+func runExport(cfg *config.Config, args []string) int {
+	flags := newFlagSet("export")
+	email := flags.String("email", "", "owner of the exported expenses (required)")
+	file := flags.String("out", "-", "output file, or - for stdout")
+	format := flags.String("format", "csv", "output format: csv or json")
+	jsonOut := flags.Bool("json", false, "print the result as JSON (requires -out)")
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	if *email == "" {
+		return usageError(flags, errMissingEmail.Error())
+	}
+	if *format != "csv" && *format != "json" {
+		return usageError(flags, "-format must be csv or json")
+	}
+	if *jsonOut && *file == "-" {
+		return usageError(flags, "-json needs -out, stdout already carries the export")
+	}
+
+	out := output{json: *jsonOut}
+	application := openApp(cfg)
+	user, err := application.Users.GetUserByEmail(context.Background(), *email)
+	if err != nil {
+		return out.fail(err)
+	}
+
+	expenses, err := collectExpenses(context.Background(), application.Expenses, user.ID.String(), cfg.Location())
+	if err != nil {
+		return out.fail(err)
+	}
+
+	writer, closeOutput, err := createOutput(*file)
+	if err != nil {
+		return out.fail(err)
+	}
+	if *format == "json" {
+		encoder := json.NewEncoder(writer)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(expenses)
+	} else {
+		err = writeExpenseCSV(writer, expenses)
+	}
+	if closeErr := closeOutput(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return out.fail(err)
+	}
+
+	if *file == "-" {
+		return exitOK
+	}
+	return out.result(map[string]interface{}{"email": user.Email, "exported": len(expenses), "file": *file},
+		"✅ Exported %d expenses for %s to %s", len(expenses), user.Email, *file)
+}
+
+func runPurgeTrash(cfg *config.Config, args []string) int {
+	flags := newFlagSet("purge-trash")
+	days := flags.Int("retention-days", 0, "purge items trashed more than this many days ago (default: expenses.trash_retention)")
+	jsonOut := flags.Bool("json", false, "print the result as JSON")
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	if *days < 0 {
+		return usageError(flags, "-retention-days must not be negative")
+	}
+
+	retention := cfg.Expenses.TrashRetention.Duration
+	if *days > 0 {
+		retention = time.Duration(*days) * 24 * time.Hour
+	}
+
+	out := output{json: *jsonOut}
+	purged, err := openApp(cfg).Expenses.PurgeExpiredTrash(context.Background(), retention)
+	if err != nil {
+		return out.fail(err)
+	}
+	return out.result(map[string]interface{}{"purged": purged, "retentionDays": int(retention.Hours() / 24)},
+		"✅ Purged %d trashed expenses", purged)
+}
+
+// collectExpenses walks every list page in chronological order with dates in
+// loc.
+func collectExpenses(ctx context.Context, uc *usecase.ExpenseUseCase, userID string, loc *time.Location) ([]model.CreateExpenseInput, error) {
+	var expenses []model.CreateExpenseInput
+	cursor := ""
+	for {
+		page, err := uc.ListExpenses(ctx, userID, model.ExpenseFilter{}, "transactionAt", cursor, 100, false)
+		if err != nil {
+			return nil, err
+		}
+		for _, expense := range page.Data {
+			expenses = append(expenses, model.CreateExpenseInput{
+				Category:      expense.Category,
+				Amount:        expense.Amount,
+				Description:   expense.Description,
+				TransactionAt: model.JSONTime{Time: expense.TransactionAt.In(loc)},
+				Tags:          expense.Tags,
+			})
+		}
+		if page.NextCursor == "" {
+			return expenses, nil
+		}
+		cursor = page.NextCursor
+	}
+}
+
+func readExpenseCSV(r io.Reader) ([]model.CreateExpenseInput, error) {
+	reader := csv.NewReader(r)
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, err
+	}
+	if len(header) < len(expenseCSVHeader)-1 || len(header) > len(expenseCSVHeader) {
+		return nil, fmt.Errorf("unexpected header %q, want %s", strings.Join(header, ","), strings.Join(expenseCSVHeader, ","))
+	}
+	for i, column := range header {
+		if !strings.EqualFold(strings.TrimSpace(column), expenseCSVHeader[i]) {
+			return nil, fmt.Errorf("unexpected header %q, want %s", strings.Join(header, ","), strings.Join(expenseCSVHeader, ","))
+		}
+	}
+	reader.FieldsPerRecord = len(header)
+
+	var inputs []model.CreateExpenseInput
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return inputs, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		amount, err := strconv.ParseFloat(strings.TrimSpace(record[1]), 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid amount %q", line, record[1])
+		}
+		var transactionAt model.JSONTime
+		if err := transactionAt.UnmarshalJSON([]byte(strings.TrimSpace(record[3]))); err != nil || transactionAt.IsZero() {
+			return nil, fmt.Errorf("line %d: invalid transactionAt %q, expected %s", line, record[3], model.LayoutYYYYMMDDHHMM)
+		}
+
+		var tags []string
+		if len(record) > 4 && strings.TrimSpace(record[4]) != "" {
+			tags = strings.Split(record[4], expenseCSVTagSeparator)
+		}
+
+		inputs = append(inputs, model.CreateExpenseInput{
+			Category:      model.Category(strings.ToUpper(strings.TrimSpace(record[0]))),
+			Amount:        amount,
+			Description:   strings.TrimSpace(record[2]),
+			TransactionAt: transactionAt,
+			Tags:          tags,
+		})
+	}
+}
+
+func writeExpenseCSV(w io.Writer, expenses []model.CreateExpenseInput) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(expenseCSVHeader); err != nil {
+		return err
+	}
+	for _, expense := range expenses {
+		err := writer.Write([]string{
+			string(expense.Category),
+			strconv.FormatFloat(expense.Amount, 'f', 2, 64),
+			expense.Description,
+			expense.TransactionAt.Format(model.LayoutYYYYMMDDHHMM),
+			strings.Join(expense.Tags, expenseCSVTagSeparator),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+func openInput(path string) (io.Reader, func() error, error) {
+	if path == "-" {
+		return os.Stdin, func() error { return nil }, nil
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	return file, file.Close, nil
+}
+
+func createOutput(path string) (io.Writer, func() error, error) {
+	if path == "-" {
+		return os.Stdout, func() error { return nil }, nil
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	return file, file.Close, nil
+}
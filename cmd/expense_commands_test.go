@@ -0,0 +1,44 @@
+package main
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"financial-track/model"
+)
+
+func TestExpenseCSVRoundTrip(t *testing.T) {
+	expenses := []model.CreateExpenseInput{
+		{Category: "FOOD", Amount: 12.5, Description: "lunch, with \"friends\"", TransactionAt: model.JSONTime{Time: time.Date(2025, 10, 5, 12, 30, 0, 0, time.UTC)}, Tags: []string{"trip", "work"}},
+		{Category: "TRANSPORT", Amount: 4, Description: "bus", TransactionAt: model.JSONTime{Time: time.Date(2025, 10, 6, 8, 0, 0, 0, time.UTC)}},
+	}
+
+	var buf bytes.Buffer
+	if err := writeExpenseCSV(&buf, expenses); err != nil {
+		t.Fatal(err)
+	}
+	got, err := readExpenseCSV(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, expenses) {
+		t.Fatalf("round trip = %+v, want %+v", got, expenses)
+	}
+}
+
+func TestReadExpenseCSVWithoutTags(t *testing.T) {
+	got, err := readExpenseCSV(strings.NewReader("category,amount,description,transactionAt\nfood,10,lunch,2025-10-05 12:30\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].Category != "FOOD" || got[0].Tags != nil {
+		t.Fatalf("got %+v", got)
+	}
+
+	if _, err := readExpenseCSV(strings.NewReader("category,amount\nfood,10\n")); err == nil {
+		t.Fatal("truncated header accepted")
+	}
+}
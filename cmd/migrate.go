@@ -0,0 +1,104 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"financial-track/config"
+	"financial-track/database"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"gorm.io/gorm"
+)
+
+const migrateUsage = `Usage: app migrate <command>
+
+Commands:
+  up                                Apply all pending migrations
+  down [n] [-confirm-initial]       Roll back the last n migrations (default 1)
+  to <version> [-confirm-initial]   Migrate up or down to the given version (0 rolls back everything)
+  status [-json]                    Show applied and pending migrations
+
+Rolling back the initial migration drops every table, so it is refused unless
+-confirm-initial is given.`
+
+// runMigrateCommand implements "app migrate ..." and returns the exit code.
+func runMigrateCommand(cfg *config.Config, args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		return exitUsage
+	}
+
+	command, confirmInitial, args := args[0], false, args[1:]
+	if len(args) > 0 && args[len(args)-1] == "-confirm-initial" {
+		confirmInitial, args = true, args[:len(args)-1]
+	}
+
+	var run func(db *gorm.DB) error
+	switch command {
+	case "up":
+		run = func(db *gorm.DB) error { return database.MigrateUp(db) }
+	case "down":
+		steps := 1
+		if len(args) > 0 {
+			n, err := strconv.Atoi(args[0])
+			if err != nil {
+				fmt.Fprintln(os.Stderr, "invalid number of steps:", args[0])
+				return exitUsage
+			}
+			steps = n
+		}
+		run = func(db *gorm.DB) error { return database.MigrateDown(db, steps, confirmInitial) }
+	case "to":
+		if len(args) < 1 {
+			fmt.Fprintln(os.Stderr, migrateUsage)
+			return exitUsage
+		}
+		version, err := strconv.ParseInt(args[0], 10, 64)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "invalid version:", args[0])
+			return exitUsage
+		}
+		run = func(db *gorm.DB) error { return database.MigrateTo(db, version, confirmInitial) }
+	case "status":
+		asJSON := len(args) > 0 && args[0] == "-json"
+		run = func(db *gorm.DB) error { return printMigrationStatus(db, asJSON) }
+	default:
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		return exitUsage
+	}
+
+	if err := run(connectDB(cfg)); err != nil {
+		if errors.Is(err, database.ErrInitialRollback) {
+			err = fmt.Errorf("%w (-confirm-initial)", err)
+		}
+		fmt.Fprintln(os.Stderr, "❌ Migration failed:", err)
+		return exitFailure
+	}
+	return exitOK
+}
+
+func printMigrationStatus(db *gorm.DB, asJSON bool) error {
+	status, err := database.GetMigrationStatus(db)
+	if err != nil {
+		return err
+	}
+	if asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(status)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+	for _, s := range status {
+		applied := "pending"
+		if s.AppliedAt != nil {
+			applied = s.AppliedAt.Format("2006-01-02 15:04:05")
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\n", s.Version, s.Name, applied)
+	}
+	return w.Flush()
+}
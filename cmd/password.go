@@ -0,0 +1,48 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/mattn/go-isatty"
+)
+
+// readPassword reads a password from stdin so it never shows up in ps or the
+// shell history. On a terminal it prompts without echo and asks for it twice;
+// otherwise it reads the first line (e.g. `echo "$PASSWORD" | app create-user`).
+func readPassword(prompt string) (string, error) {
+	if !isatty.IsTerminal(os.Stdin.Fd()) {
+		return readLine(bufio.NewReader(os.Stdin))
+	}
+
+	password, err := promptPassword(prompt)
+	if err != nil {
+		return "", err
+	}
+	again, err := promptPassword("Confirm password: ")
+	if err != nil {
+		return "", err
+	}
+	if password != again {
+		return "", errors.New("passwords do not match")
+	}
+	return password, nil
+}
+
+func promptPassword(prompt string) (string, error) {
+	fmt.Fprint(os.Stderr, prompt)
+	defer fmt.Fprintln(os.Stderr)
+	return readNoEcho(os.Stdin)
+}
+
+func readLine(r *bufio.Reader) (string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"financial-track/app"
+	"financial-track/config"
+	"financial-track/model"
+	"financial-track/seed"
+	"financial-track/usecase"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Password of the seeded users unless -password-stdin is given.
+const seedPassword = "seed123"
+
+type seedResult struct {
+	Email    string `json:"email"`
+	Expenses int    `json:"expenses"`
+	Skipped  bool   `json:"skipped,omitempty"`
+}
+
+func runSeed(cfg *config.Config, args []string) int {
+	flags := newFlagSet("seed")
+	seedValue := flags.Int64("seed", 1, "random seed; the same flags always generate the same data")
+	users := flags.Int("users", 3, "number of users to create")
+	months := flags.Int("months", 6, "months of expenses per user")
+	volume := flags.Float64("volume", 1, "multiplier for the frequency of variable expenses")
+	until := flags.String("until", seed.DefaultUntil, "last day (exclusive) of the generated period, YYYY-MM-DD")
+	passwordStdin := flags.Bool("password-stdin", false, "read the password of the generated users from stdin instead of using "+seedPassword)
+	domain := flags.String("email-domain", "seed.local", "domain of the generated emails")
+	dryRun := flags.Bool("dry-run", false, "print the generated data as JSON without touching the database")
+	jsonOut := flags.Bool("json", false, "print the result as JSON")
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	if *users <= 0 || *months <= 0 || *volume <= 0 {
+		return usageError(flags, "-users, -months and -volume must be positive")
+	}
+	password := seedPassword
+	if *passwordStdin && !*dryRun {
+		var err error
+		if password, err = readPassword("Password for the seeded users: "); err != nil {
+			return output{json: *jsonOut}.fail(err)
+		}
+		if len(password) < 6 {
+			return usageError(flags, "the password must have at least 6 characters")
+		}
+	}
+
+	end, err := time.ParseInLocation("2006-01-02", *until, cfg.Location())
+	if err != nil {
+		return usageError(flags, "-until must be a date in the format YYYY-MM-DD")
+	}
+
+	generated := seed.Generate(seed.Options{
+		Seed:        *seedValue,
+		Users:       *users,
+		Months:      *months,
+		Volume:      *volume,
+		Until:       end,
+		EmailDomain: *domain,
+	})
+	if *dryRun {
+		return output{json: true}.result(generated, "")
+	}
+
+	out := output{json: *jsonOut}
+	if err := cfg.ValidateApp(); err != nil {
+		return out.fail(err)
+	}
+	db := connectDB(cfg)
+
+	results := make([]seedResult, 0, len(generated))
+	total := 0
+	for _, g := range generated {
+		err := seedUser(cfg, db, g, password)
+		// Users from an earlier run with the same seed are left as they are.
+		if errors.Is(err, usecase.ErrEmailInUse) {
+			results = append(results, seedResult{Email: g.Email, Skipped: true})
+			continue
+		}
+		if err != nil {
+			return out.fail(fmt.Errorf("seed %s: %w", g.Email, err))
+		}
+		results = append(results, seedResult{Email: g.Email, Expenses: len(g.Expenses)})
+		total += len(g.Expenses)
+	}
+
+	if *passwordStdin {
+		return out.result(results, "✅ Seeded %d expenses for %d users", total, len(results))
+	}
+	return out.result(results, "✅ Seeded %d expenses for %d users (password %q)", total, len(results), password)
+}
+
+// seedUser creates the user and their expenses in one transaction, so a failed
+// run leaves no empty user behind for the next run to skip.
+func seedUser(cfg *config.Config, db *gorm.DB, g seed.User, password string) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		application := app.New(app.NewDeps(cfg, tx))
+		input := model.CreateUserInput{Name: g.Name, Email: g.Email, Password: password}
+		user, err := application.Users.AdminCreateUser(context.Background(), input, true)
+		if err != nil {
+			return err
+		}
+
+		for i := range g.Expenses {
+			g.Expenses[i].UserID = user.ID.String()
+		}
+		_, err = application.Expenses.ImportExpenses(context.Background(), g.Expenses, cliActor("seed"))
+		return err
+	})
+}
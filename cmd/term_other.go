@@ -0,0 +1,13 @@
+//go:build !linux
+
+package main
+
+import (
+	"bufio"
+	"os"
+)
+
+// readNoEcho falls back to a plain read where termios is not available.
+func readNoEcho(f *os.File) (string, error) {
+	return readLine(bufio.NewReader(f))
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"financial-track/config"
+	"financial-track/model"
+	"financial-track/utils"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+func runCreateUser(cfg *config.Config, args []string) int {
+	flags := newFlagSet("create-user")
+	name := flags.String("name", "", "user name (required)")
+	email := flags.String("email", "", "user email (required)")
+	verified := flags.Bool("verified", false, "mark the email as verified and skip the verification email")
+	jsonOut := flags.Bool("json", false, "print the result as JSON")
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+
+	out := output{json: *jsonOut}
+	password, err := readPassword("Password: ")
+	if err != nil {
+		return out.fail(err)
+	}
+
+	input := model.CreateUserInput{Name: *name, Email: *email, Password: password}
+	if errs := utils.ValidateStruct(&input); errs != nil {
+		return usageError(flags, formatFieldErrors(errs))
+	}
+
+	user, err := openApp(cfg).Users.AdminCreateUser(context.Background(), input, *verified)
+	if err != nil {
+		return out.fail(err)
+	}
+	return out.result(user, "✅ User %s created (%s)", user.Email, user.ID)
+}
+
+func runResetPassword(cfg *config.Config, args []string) int {
+	flags := newFlagSet("reset-password")
+	email := flags.String("email", "", "user email (required)")
+	jsonOut := flags.Bool("json", false, "print the result as JSON")
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	if *email == "" {
+		return usageError(flags, errMissingEmail.Error())
+	}
+
+	out := output{json: *jsonOut}
+	password, err := readPassword("New password: ")
+	if err != nil {
+		return out.fail(err)
+	}
+
+	if err := openApp(cfg).Users.AdminSetPassword(context.Background(), *email, password); err != nil {
+		return out.fail(err)
+	}
+	return out.result(map[string]string{"email": *email, "status": "password_reset"},
+		"✅ Password reset for %s; all sessions revoked", *email)
+}
+
+// formatFieldErrors turns a ValidateStruct map into one stable line.
+func formatFieldErrors(errs map[string]string) string {
+	fields := make([]string, 0, len(errs))
+	for field := range errs {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	parts := make([]string, 0, len(fields))
+	for _, field := range fields {
+		parts = append(parts, fmt.Sprintf("%s: %s", strings.ToLower(field), errs[field]))
+	}
+	return strings.Join(parts, "; ")
+}
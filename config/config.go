@@ -0,0 +1,265 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/goccy/go-yaml"
+	"github.com/joho/godotenv"
+	"github.com/pelletier/go-toml/v2"
+)
+
+// Config is the typed application configuration. Later sources win: defaults,
+// CONFIG_FILE (YAML or TOML), .env, then environment variables.
+type Config struct {
+	App      AppConfig      `yaml:"app" toml:"app"`
+	Server   ServerConfig   `yaml:"server" toml:"server"`
+	Database DatabaseConfig `yaml:"database" toml:"database"`
+	JWT      JWTConfig      `yaml:"jwt" toml:"jwt"`
+	Cursor   CursorConfig   `yaml:"cursor" toml:"cursor"`
+
+	Mail        MailConfig        `yaml:"mail" toml:"mail"`
+	OIDC        OIDCConfig        `yaml:"oidc" toml:"oidc"`
+	RateLimit   RateLimitConfig   `yaml:"rate_limit" toml:"rate_limit"`
+	LoginGuard  LoginGuardConfig  `yaml:"login_guard" toml:"login_guard"`
+	Idempotency IdempotencyConfig `yaml:"idempotency" toml:"idempotency"`
+	Expenses    ExpensesConfig    `yaml:"expenses" toml:"expenses"`
+}
+
+// AppConfig names the app (the 2FA issuer) and sets the base URL of emailed links.
+type AppConfig struct {
+	Timezone string `yaml:"timezone" toml:"timezone"`
+	Name     string `yaml:"name" toml:"name"`
+	URL      string `yaml:"url" toml:"url"`
+}
+
+// ServerConfig holds the HTTP server limits. WriteTimeout must exceed
+// RequestTimeout so the 504 reaches the client.
+type ServerConfig struct {
+	Port              string   `yaml:"port" toml:"port"`
+	ReadHeaderTimeout Duration `yaml:"read_header_timeout" toml:"read_header_timeout"`
+	ReadTimeout       Duration `yaml:"read_timeout" toml:"read_timeout"`
+	WriteTimeout      Duration `yaml:"write_timeout" toml:"write_timeout"`
+	IdleTimeout       Duration `yaml:"idle_timeout" toml:"idle_timeout"`
+	// ShutdownTimeout is how long in-flight requests get after SIGTERM/SIGINT.
+	ShutdownTimeout Duration `yaml:"shutdown_timeout" toml:"shutdown_timeout"`
+	RequestTimeout  Duration `yaml:"request_timeout" toml:"request_timeout"`
+	// TrustedProxies may set X-Forwarded-For. When empty the connection IP is
+	// used, so the header cannot dodge rate limits or login lockouts.
+	TrustedProxies []string `yaml:"trusted_proxies" toml:"trusted_proxies"`
+}
+
+type DatabaseConfig struct {
+	URL         string `yaml:"url" toml:"url"`
+	AutoMigrate bool   `yaml:"auto_migrate" toml:"auto_migrate"`
+}
+
+// JWTConfig signs tokens with PEM keys from KeysDir or with an HS256 Secret.
+type JWTConfig struct {
+	Secret    string `yaml:"secret" toml:"secret"`
+	KeysDir   string `yaml:"keys_dir" toml:"keys_dir"`
+	ActiveKID string `yaml:"active_kid" toml:"active_kid"`
+}
+
+// CursorConfig is the HMAC key for pagination cursors, kept apart from the JWT
+// secret.
+type CursorConfig struct {
+	Secret string `yaml:"secret" toml:"secret"`
+}
+
+// MailConfig selects "log" (development and tests) or "smtp" delivery.
+type MailConfig struct {
+	Driver string     `yaml:"driver" toml:"driver"`
+	From   string     `yaml:"from" toml:"from"`
+	LogDir string     `yaml:"log_dir" toml:"log_dir"`
+	SMTP   SMTPConfig `yaml:"smtp" toml:"smtp"`
+}
+
+type SMTPConfig struct {
+	Host     string `yaml:"host" toml:"host"`
+	Port     string `yaml:"port" toml:"port"`
+	Username string `yaml:"username" toml:"username"`
+	Password string `yaml:"password" toml:"password"`
+}
+
+// OIDCConfig lists the enabled social login providers.
+type OIDCConfig struct {
+	Providers []OIDCProviderConfig `yaml:"providers" toml:"providers"`
+}
+
+type OIDCProviderConfig struct {
+	Name         string   `yaml:"name" toml:"name"`
+	Issuer       string   `yaml:"issuer" toml:"issuer"`
+	ClientID     string   `yaml:"client_id" toml:"client_id"`
+	ClientSecret string   `yaml:"client_secret" toml:"client_secret"`
+	RedirectURL  string   `yaml:"redirect_url" toml:"redirect_url"`
+	Scopes       []string `yaml:"scopes" toml:"scopes"`
+}
+
+// RateLimitConfig selects the "memory" or "postgres" backend and the
+// "<n>/<s|min|h>" policies for /auth (per IP) and authenticated routes (per user).
+type RateLimitConfig struct {
+	Store string `yaml:"store" toml:"store"`
+	Auth  string `yaml:"auth" toml:"auth"`
+	API   string `yaml:"api" toml:"api"`
+}
+
+// LoginGuardConfig stores login attempts in "postgres" (shared by replicas) or
+// "memory".
+type LoginGuardConfig struct {
+	Store string `yaml:"store" toml:"store"`
+}
+
+type IdempotencyConfig struct {
+	TTL Duration `yaml:"ttl" toml:"ttl"`
+}
+
+type ExpensesConfig struct {
+	// TrashRetention is how long a deleted expense stays in the trash.
+	TrashRetention Duration `yaml:"trash_retention" toml:"trash_retention"`
+}
+
+// Duration accepts values such as "15s" or "2m".
+type Duration struct {
+	time.Duration
+}
+
+func (d *Duration) UnmarshalText(text []byte) error {
+	v, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	d.Duration = v
+	return nil
+}
+
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+func Default() *Config {
+	return &Config{
+		App: AppConfig{
+			Timezone: "America/Sao_Paulo",
+			Name:     "Financial Track",
+			URL:      "http://localhost:81",
+		},
+		Server: ServerConfig{
+			Port:              "81",
+			ReadHeaderTimeout: Duration{5 * time.Second},
+			ReadTimeout:       Duration{15 * time.Second},
+			WriteTimeout:      Duration{40 * time.Second},
+			IdleTimeout:       Duration{120 * time.Second},
+			ShutdownTimeout:   Duration{30 * time.Second},
+			RequestTimeout:    Duration{30 * time.Second},
+		},
+		Database: DatabaseConfig{AutoMigrate: true},
+		Mail: MailConfig{
+			Driver: "log",
+			From:   "no-reply@financial-track.local",
+			SMTP:   SMTPConfig{Port: "587"},
+		},
+		RateLimit:   RateLimitConfig{Store: "memory", Auth: "20/min", API: "120/min"},
+		LoginGuard:  LoginGuardConfig{Store: "postgres"},
+		Idempotency: IdempotencyConfig{TTL: Duration{24 * time.Hour}},
+		Expenses:    ExpensesConfig{TrashRetention: Duration{30 * 24 * time.Hour}},
+	}
+}
+
+// Load reads .env, CONFIG_FILE and the environment. It does not validate;
+// each command runs the checks it needs.
+func Load() (*Config, error) {
+	if err := godotenv.Load(); err != nil {
+		log.Println("⚠️ File .env not found, using environment variables")
+	}
+
+	cfg := Default()
+	if path := os.Getenv("CONFIG_FILE"); path != "" {
+		if err := cfg.loadFile(path); err != nil {
+			return nil, err
+		}
+	}
+	if err := cfg.loadEnv(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+func (c *Config) loadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("config: %w", err)
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.UnmarshalWithOptions(data, c, yaml.DisallowUnknownField())
+	case ".toml":
+		decoder := toml.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		err = decoder.Decode(c)
+	default:
+		return fmt.Errorf("config: unsupported file %q (use .yaml, .yml or .toml)", path)
+	}
+	if err != nil {
+		return fmt.Errorf("config: invalid %s: %w", path, err)
+	}
+	return nil
+}
+
+// Location returns App.Timezone, or time.Local when it is invalid (Validate
+// reports that).
+func (c *Config) Location() *time.Location {
+	loc, err := time.LoadLocation(c.App.Timezone)
+	if err != nil {
+		return time.Local
+	}
+	return loc
+}
+
+const redacted = "REDACTED"
+
+// Redacted returns a copy without secrets, safe to log.
+func (c *Config) Redacted() Config {
+	out := *c
+	if out.JWT.Secret != "" {
+		out.JWT.Secret = redacted
+	}
+	if out.Cursor.Secret != "" {
+		out.Cursor.Secret = redacted
+	}
+	if out.Mail.SMTP.Password != "" {
+		out.Mail.SMTP.Password = redacted
+	}
+	out.OIDC.Providers = append([]OIDCProviderConfig(nil), c.OIDC.Providers...)
+	for i := range out.OIDC.Providers {
+		if out.OIDC.Providers[i].ClientSecret != "" {
+			out.OIDC.Providers[i].ClientSecret = redacted
+		}
+	}
+	// DSNs like "host=... password=..." are not URLs, so hide them entirely.
+	if u, err := url.Parse(out.Database.URL); err == nil && u.Scheme != "" {
+		if _, ok := u.User.Password(); ok {
+			u.User = url.UserPassword(u.User.Username(), redacted)
+		}
+		out.Database.URL = u.String()
+	} else if out.Database.URL != "" {
+		out.Database.URL = redacted
+	}
+	return out
+}
+
+// String renders the effective configuration as YAML with secrets hidden.
+func (c *Config) String() string {
+	data, err := yaml.Marshal(c.Redacted())
+	if err != nil {
+		return fmt.Sprintf("config: %v", err)
+	}
+	return string(data)
+}
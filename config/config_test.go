@@ -0,0 +1,118 @@
+package config
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadEnvOIDCProviders(t *testing.T) {
+	cfg := Default()
+	cfg.OIDC.Providers = []OIDCProviderConfig{
+		{Name: "google", Issuer: "https://accounts.google.com", ClientID: "from-file"},
+		{Name: "github", Issuer: "https://github.example"},
+	}
+	t.Setenv("OIDC_PROVIDERS", "Google, local")
+	t.Setenv("OIDC_GOOGLE_CLIENT_SECRET", "google-secret")
+	t.Setenv("OIDC_LOCAL_ISSUER", "http://localhost:8080")
+	t.Setenv("OIDC_LOCAL_SCOPES", "openid,email")
+
+	if err := cfg.loadEnv(); err != nil {
+		t.Fatal(err)
+	}
+
+	providers := cfg.OIDC.Providers
+	if len(providers) != 2 {
+		t.Fatalf("providers = %+v, want google and local", providers)
+	}
+	if google := providers[0]; google.Name != "google" || google.ClientID != "from-file" || google.ClientSecret != "google-secret" {
+		t.Errorf("google = %+v, want file fields plus the env secret", google)
+	}
+	if local := providers[1]; local.Issuer != "http://localhost:8080" || strings.Join(local.Scopes, " ") != "openid email" {
+		t.Errorf("local = %+v", local)
+	}
+}
+
+func TestLoadEnvUnits(t *testing.T) {
+	cfg := Default()
+	t.Setenv("IDEMPOTENCY_KEY_TTL_HOURS", "2")
+	t.Setenv("EXPENSE_TRASH_RETENTION_DAYS", "7")
+
+	if err := cfg.loadEnv(); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Idempotency.TTL.Duration != 2*time.Hour || cfg.Expenses.TrashRetention.Duration != 7*24*time.Hour {
+		t.Fatalf("ttl = %s, retention = %s", cfg.Idempotency.TTL, cfg.Expenses.TrashRetention)
+	}
+
+	t.Setenv("EXPENSE_TRASH_RETENTION_DAYS", "0")
+	if err := cfg.loadEnv(); err == nil {
+		t.Fatal("EXPENSE_TRASH_RETENTION_DAYS=0 accepted")
+	}
+}
+
+func TestValidateServices(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(*Config)
+		want   string
+	}{
+		{name: "defaults"},
+		{name: "unknown mail driver", change: func(c *Config) { c.Mail.Driver = "sendgrid" }, want: "mail.driver (MAIL_DRIVER)"},
+		{name: "smtp without host", change: func(c *Config) { c.Mail.Driver = "smtp" }, want: "mail.smtp.host (SMTP_HOST)"},
+		{name: "smtp user without password", change: func(c *Config) {
+			c.Mail.Driver, c.Mail.SMTP.Host, c.Mail.SMTP.Username = "smtp", "smtp.example.com", "user"
+		}, want: "mail.smtp.password (SMTP_PASSWORD)"},
+		{name: "oidc provider without client id", change: func(c *Config) {
+			c.OIDC.Providers = []OIDCProviderConfig{{Name: "google", Issuer: "https://accounts.google.com", RedirectURL: "http://localhost:81/cb"}}
+		}, want: "client_id is required"},
+		{name: "duplicated oidc provider", change: func(c *Config) {
+			p := OIDCProviderConfig{Name: "google", Issuer: "https://accounts.google.com", ClientID: "id", RedirectURL: "http://localhost:81/cb"}
+			c.OIDC.Providers = []OIDCProviderConfig{p, p}
+		}, want: "configured more than once"},
+		{name: "unknown login guard store", change: func(c *Config) { c.LoginGuard.Store = "redis" }, want: "login_guard.store (LOGIN_GUARD_STORE)"},
+		{name: "app url with trailing slash", change: func(c *Config) { c.App.URL = "https://example.com/" }, want: "app.url (APP_URL)"},
+		{name: "invalid rate limit", change: func(c *Config) { c.RateLimit.API = "10/day" }, want: "rate_limit.api (RATE_LIMIT_API)"},
+		{name: "unknown rate limit store", change: func(c *Config) { c.RateLimit.Store = "redis" }, want: "rate_limit.store (RATE_LIMIT_STORE)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Default()
+			cfg.Database.URL = "postgres://localhost/financial_track"
+			cfg.JWT.Secret = "7f3a9c1e5b2d8f4a6c0e9b3d7a1f5c8e"
+			cfg.Cursor.Secret = "2b8e4f0a6d1c9e3b7f5a2d8c4e0b6f1a"
+			if tt.change != nil {
+				tt.change(cfg)
+			}
+
+			err := cfg.Validate()
+			if tt.want == "" {
+				if err != nil {
+					t.Fatalf("Validate = %v, want nil", err)
+				}
+				return
+			}
+			var invalid *ValidationError
+			if !errors.As(err, &invalid) || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("Validate = %v, want a problem with %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestRedactedHidesServiceSecrets(t *testing.T) {
+	cfg := Default()
+	cfg.Mail.SMTP.Password = "smtp-password"
+	cfg.OIDC.Providers = []OIDCProviderConfig{{Name: "google", ClientSecret: "oidc-secret"}}
+
+	out := cfg.String()
+	for _, secret := range []string{"smtp-password", "oidc-secret"} {
+		if strings.Contains(out, secret) {
+			t.Errorf("printed configuration contains %q:\n%s", secret, out)
+		}
+	}
+	if cfg.OIDC.Providers[0].ClientSecret != "oidc-secret" {
+		t.Error("Redacted changed the original configuration")
+	}
+}
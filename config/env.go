@@ -0,0 +1,149 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// loadEnv overrides the config with the environment. Timeouts stay in whole
+// seconds.
+func (c *Config) loadEnv() error {
+	var errs []error
+
+	setString(&c.App.Timezone, "APP_TIMEZONE")
+	setString(&c.App.Name, "APP_NAME")
+	setString(&c.App.URL, "APP_URL")
+
+	setString(&c.Server.Port, "SERVER_PORT")
+	errs = append(errs,
+		setSeconds(&c.Server.ReadHeaderTimeout, "SERVER_READ_HEADER_TIMEOUT_SECONDS"),
+		setSeconds(&c.Server.ReadTimeout, "SERVER_READ_TIMEOUT_SECONDS"),
+		setSeconds(&c.Server.WriteTimeout, "SERVER_WRITE_TIMEOUT_SECONDS"),
+		setSeconds(&c.Server.IdleTimeout, "SERVER_IDLE_TIMEOUT_SECONDS"),
+		setSeconds(&c.Server.ShutdownTimeout, "SERVER_SHUTDOWN_TIMEOUT_SECONDS"),
+		setSeconds(&c.Server.RequestTimeout, "REQUEST_TIMEOUT_SECONDS"),
+	)
+
+	setList(&c.Server.TrustedProxies, "TRUSTED_PROXIES")
+
+	setString(&c.Database.URL, "DB_URL")
+	errs = append(errs, setBool(&c.Database.AutoMigrate, "DB_AUTO_MIGRATE"))
+
+	setString(&c.JWT.Secret, "JWT_SECRET")
+	setString(&c.JWT.KeysDir, "JWT_KEYS_DIR")
+	setString(&c.JWT.ActiveKID, "JWT_ACTIVE_KID")
+
+	setString(&c.Cursor.Secret, "CURSOR_SECRET")
+
+	setString(&c.Mail.Driver, "MAIL_DRIVER")
+	setString(&c.Mail.From, "MAIL_FROM")
+	setString(&c.Mail.LogDir, "MAIL_LOG_DIR")
+	setString(&c.Mail.SMTP.Host, "SMTP_HOST")
+	setString(&c.Mail.SMTP.Port, "SMTP_PORT")
+	setString(&c.Mail.SMTP.Username, "SMTP_USERNAME")
+	setString(&c.Mail.SMTP.Password, "SMTP_PASSWORD")
+
+	c.loadOIDCEnv()
+
+	setString(&c.RateLimit.Store, "RATE_LIMIT_STORE")
+	setString(&c.RateLimit.Auth, "RATE_LIMIT_AUTH")
+	setString(&c.RateLimit.API, "RATE_LIMIT_API")
+
+	setString(&c.LoginGuard.Store, "LOGIN_GUARD_STORE")
+
+	errs = append(errs,
+		setUnits(&c.Idempotency.TTL, "IDEMPOTENCY_KEY_TTL_HOURS", time.Hour, "hours"),
+		setUnits(&c.Expenses.TrashRetention, "EXPENSE_TRASH_RETENTION_DAYS", 24*time.Hour, "days"),
+	)
+
+	return errors.Join(errs...)
+}
+
+// loadOIDCEnv applies OIDC_PROVIDERS=google,local, which replaces the file's
+// provider list (keeping fields of same-named providers), and each provider's
+// OIDC_<NAME>_ISSUER, _CLIENT_ID, _CLIENT_SECRET, _REDIRECT_URL and _SCOPES.
+func (c *Config) loadOIDCEnv() {
+	var names []string
+	setList(&names, "OIDC_PROVIDERS")
+	if names != nil {
+		providers := make([]OIDCProviderConfig, 0, len(names))
+		for _, name := range names {
+			provider := OIDCProviderConfig{Name: strings.ToLower(name)}
+			for _, existing := range c.OIDC.Providers {
+				if strings.EqualFold(existing.Name, name) {
+					provider = existing
+				}
+			}
+			providers = append(providers, provider)
+		}
+		c.OIDC.Providers = providers
+	}
+
+	for i := range c.OIDC.Providers {
+		p := &c.OIDC.Providers[i]
+		prefix := "OIDC_" + strings.ToUpper(p.Name) + "_"
+		setString(&p.Issuer, prefix+"ISSUER")
+		setString(&p.ClientID, prefix+"CLIENT_ID")
+		setString(&p.ClientSecret, prefix+"CLIENT_SECRET")
+		setString(&p.RedirectURL, prefix+"REDIRECT_URL")
+		if scopes := os.Getenv(prefix + "SCOPES"); scopes != "" {
+			p.Scopes = strings.Fields(strings.ReplaceAll(scopes, ",", " "))
+		}
+	}
+}
+
+func setString(dst *string, name string) {
+	if value, ok := os.LookupEnv(name); ok && value != "" {
+		*dst = value
+	}
+}
+
+// setList reads a comma-separated list.
+func setList(dst *[]string, name string) {
+	value := os.Getenv(name)
+	if value == "" {
+		return
+	}
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	*dst = items
+}
+
+func setSeconds(dst *Duration, name string) error {
+	return setUnits(dst, name, time.Second, "seconds")
+}
+
+// setUnits reads a positive whole number of units (hours, days).
+func setUnits(dst *Duration, name string, unit time.Duration, unitName string) error {
+	value := os.Getenv(name)
+	if value == "" {
+		return nil
+	}
+	v, err := strconv.Atoi(value)
+	if err != nil || v <= 0 {
+		return fmt.Errorf("config: %s must be a positive number of %s, got %q", name, unitName, value)
+	}
+	dst.Duration = time.Duration(v) * unit
+	return nil
+}
+
+func setBool(dst *bool, name string) error {
+	value := os.Getenv(name)
+	if value == "" {
+		return nil
+	}
+	v, err := strconv.ParseBool(value)
+	if err != nil {
+		return fmt.Errorf("config: %s must be true or false, got %q", name, value)
+	}
+	*dst = v
+	return nil
+}
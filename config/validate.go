@@ -0,0 +1,268 @@
+package config
+
+import (
+	"financial-track/ratelimit"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// minSecretLength is the minimum for HMAC-SHA256 (256 bits).
+const minSecretLength = 32
+
+// Sample values that must never reach production, whatever their length.
+var placeholderSecrets = []string{"your-secret-key-here", "changeme", "change-me", "secret", "jwt-secret"}
+
+// ValidationError lists every problem at once so the config can be fixed in one
+// go.
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return "invalid configuration:\n  - " + strings.Join(e.Problems, "\n  - ")
+}
+
+// Validate checks everything the HTTP server needs.
+func (c *Config) Validate() error {
+	var problems []string
+	problems = append(problems, c.App.validate()...)
+	problems = append(problems, c.Database.validate()...)
+	problems = append(problems, c.Server.validate()...)
+	problems = append(problems, c.JWT.validate()...)
+	problems = append(problems, c.validateCursor()...)
+	problems = append(problems, c.validateServices()...)
+	problems = append(problems, c.RateLimit.validate()...)
+	if c.Idempotency.TTL.Duration <= 0 {
+		problems = append(problems, "idempotency.ttl (IDEMPOTENCY_KEY_TTL_HOURS) must be greater than zero")
+	}
+	return toError(problems)
+}
+
+// ValidateDatabase checks only what opening the database needs.
+func (c *Config) ValidateDatabase() error {
+	var problems []string
+	problems = append(problems, c.App.validate()...)
+	problems = append(problems, c.Database.validate()...)
+	return toError(problems)
+}
+
+// ValidateApp checks what CLI commands need to build the use cases.
+func (c *Config) ValidateApp() error {
+	var problems []string
+	problems = append(problems, c.App.validate()...)
+	problems = append(problems, c.Database.validate()...)
+	problems = append(problems, c.validateCursor()...)
+	problems = append(problems, c.validateServices()...)
+	return toError(problems)
+}
+
+// validateServices checks the use case dependencies shared by server and CLI.
+func (c *Config) validateServices() []string {
+	var problems []string
+	problems = append(problems, c.Mail.validate()...)
+	problems = append(problems, c.OIDC.validate()...)
+	if !oneOf(c.LoginGuard.Store, "postgres", "memory") {
+		problems = append(problems, fmt.Sprintf("login_guard.store (LOGIN_GUARD_STORE) %q must be postgres or memory", c.LoginGuard.Store))
+	}
+	if c.Expenses.TrashRetention.Duration <= 0 {
+		problems = append(problems, "expenses.trash_retention (EXPENSE_TRASH_RETENTION_DAYS) must be greater than zero")
+	}
+	return problems
+}
+
+func toError(problems []string) error {
+	if len(problems) == 0 {
+		return nil
+	}
+	return &ValidationError{Problems: problems}
+}
+
+func (a AppConfig) validate() []string {
+	var problems []string
+	if _, err := time.LoadLocation(a.Timezone); err != nil || a.Timezone == "" {
+		problems = append(problems, fmt.Sprintf("app.timezone (APP_TIMEZONE) %q is not a valid IANA time zone", a.Timezone))
+	}
+	if strings.TrimSpace(a.Name) == "" {
+		problems = append(problems, "app.name (APP_NAME) is required")
+	}
+	if !isHTTPURL(a.URL) || strings.HasSuffix(a.URL, "/") {
+		problems = append(problems, fmt.Sprintf("app.url (APP_URL) %q must be an http(s) URL without a trailing slash", a.URL))
+	}
+	return problems
+}
+
+func (d DatabaseConfig) validate() []string {
+	if d.URL == "" {
+		return []string{"database.url (DB_URL) is required"}
+	}
+	return nil
+}
+
+func (s ServerConfig) validate() []string {
+	var problems []string
+	if port, err := strconv.Atoi(s.Port); err != nil || port < 1 || port > 65535 {
+		problems = append(problems, fmt.Sprintf("server.port (SERVER_PORT) %q is not a valid port", s.Port))
+	}
+
+	timeouts := []struct {
+		key   string
+		value Duration
+	}{
+		{"server.read_header_timeout", s.ReadHeaderTimeout},
+		{"server.read_timeout", s.ReadTimeout},
+		{"server.write_timeout", s.WriteTimeout},
+		{"server.idle_timeout", s.IdleTimeout},
+		{"server.shutdown_timeout", s.ShutdownTimeout},
+		{"server.request_timeout", s.RequestTimeout},
+	}
+	for _, t := range timeouts {
+		if t.value.Duration <= 0 {
+			problems = append(problems, t.key+" must be greater than zero")
+		}
+	}
+	for _, proxy := range s.TrustedProxies {
+		if _, _, err := net.ParseCIDR(proxy); err != nil && net.ParseIP(proxy) == nil {
+			problems = append(problems, fmt.Sprintf("server.trusted_proxies (TRUSTED_PROXIES) %q is not an IP or CIDR", proxy))
+		}
+	}
+	if s.WriteTimeout.Duration <= s.RequestTimeout.Duration {
+		problems = append(problems, fmt.Sprintf("server.write_timeout (%s) must be greater than server.request_timeout (%s)", s.WriteTimeout, s.RequestTimeout))
+	}
+	return problems
+}
+
+func (j JWTConfig) validate() []string {
+	if j.KeysDir != "" {
+		if info, err := os.Stat(j.KeysDir); err != nil || !info.IsDir() {
+			return []string{fmt.Sprintf("jwt.keys_dir (JWT_KEYS_DIR) %q is not a directory", j.KeysDir)}
+		}
+		return nil
+	}
+	if j.Secret == "" {
+		return []string{"jwt.secret (JWT_SECRET) is required when jwt.keys_dir (JWT_KEYS_DIR) is not set"}
+	}
+	return validateSecret("jwt.secret (JWT_SECRET)", j.Secret)
+}
+
+func (m MailConfig) validate() []string {
+	var problems []string
+	if !strings.Contains(m.From, "@") {
+		problems = append(problems, fmt.Sprintf("mail.from (MAIL_FROM) %q is not an email address", m.From))
+	}
+	switch m.Driver {
+	case "log":
+	case "smtp":
+		if m.SMTP.Host == "" {
+			problems = append(problems, "mail.smtp.host (SMTP_HOST) is required when mail.driver is smtp")
+		}
+		if port, err := strconv.Atoi(m.SMTP.Port); err != nil || port < 1 || port > 65535 {
+			problems = append(problems, fmt.Sprintf("mail.smtp.port (SMTP_PORT) %q is not a valid port", m.SMTP.Port))
+		}
+		if m.SMTP.Username != "" && m.SMTP.Password == "" {
+			problems = append(problems, "mail.smtp.password (SMTP_PASSWORD) is required when mail.smtp.username is set")
+		}
+	default:
+		problems = append(problems, fmt.Sprintf("mail.driver (MAIL_DRIVER) %q must be log or smtp", m.Driver))
+	}
+	return problems
+}
+
+func (o OIDCConfig) validate() []string {
+	var problems []string
+	seen := map[string]bool{}
+	for _, p := range o.Providers {
+		name := strings.ToLower(p.Name)
+		if name == "" {
+			problems = append(problems, "oidc.providers: every provider needs a name")
+			continue
+		}
+		if seen[name] {
+			problems = append(problems, fmt.Sprintf("oidc.providers: %q is configured more than once", name))
+		}
+		seen[name] = true
+
+		key := fmt.Sprintf("oidc provider %q (OIDC_%s_*)", name, strings.ToUpper(name))
+		if !isHTTPURL(p.Issuer) {
+			problems = append(problems, fmt.Sprintf("%s: issuer %q must be an http(s) URL", key, p.Issuer))
+		}
+		if p.ClientID == "" {
+			problems = append(problems, key+": client_id is required")
+		}
+		if !isHTTPURL(p.RedirectURL) {
+			problems = append(problems, fmt.Sprintf("%s: redirect_url %q must be an http(s) URL", key, p.RedirectURL))
+		}
+	}
+	return problems
+}
+
+func (r RateLimitConfig) validate() []string {
+	var problems []string
+	if !oneOf(r.Store, "memory", "postgres") {
+		problems = append(problems, fmt.Sprintf("rate_limit.store (RATE_LIMIT_STORE) %q must be memory or postgres", r.Store))
+	}
+	if _, err := ratelimit.ParsePolicy("auth", r.Auth); err != nil {
+		problems = append(problems, fmt.Sprintf("rate_limit.auth (RATE_LIMIT_AUTH): %v", err))
+	}
+	if _, err := ratelimit.ParsePolicy("api", r.API); err != nil {
+		problems = append(problems, fmt.Sprintf("rate_limit.api (RATE_LIMIT_API): %v", err))
+	}
+	return problems
+}
+
+func (c *Config) validateCursor() []string {
+	switch {
+	case c.Cursor.Secret == "":
+		return []string{"cursor.secret (CURSOR_SECRET) is required"}
+	case c.Cursor.Secret == c.JWT.Secret:
+		return []string{"cursor.secret (CURSOR_SECRET) must be different from jwt.secret (JWT_SECRET)"}
+	}
+	return validateSecret("cursor.secret (CURSOR_SECRET)", c.Cursor.Secret)
+}
+
+// validateSecret rejects short or guessable HMAC keys.
+func validateSecret(name, secret string) []string {
+	switch {
+	case len(secret) < minSecretLength:
+		return []string{fmt.Sprintf("%s is too weak: use at least %d characters (e.g. openssl rand -hex 32)", name, minSecretLength)}
+	case isPlaceholder(secret) || distinctChars(secret) < 8:
+		return []string{name + " is too weak: use a random value (e.g. openssl rand -hex 32)"}
+	}
+	return nil
+}
+
+func isPlaceholder(secret string) bool {
+	lower := strings.ToLower(secret)
+	for _, p := range placeholderSecrets {
+		if strings.Contains(lower, p) {
+			return true
+		}
+	}
+	return false
+}
+
+func distinctChars(s string) int {
+	seen := map[rune]bool{}
+	for _, r := range s {
+		seen[r] = true
+	}
+	return len(seen)
+}
+
+func isHTTPURL(raw string) bool {
+	u, err := url.Parse(raw)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+func oneOf(value string, allowed ...string) bool {
+	for _, a := range allowed {
+		if value == a {
+			return true
+		}
+	}
+	return false
+}
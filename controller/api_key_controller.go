@@ -0,0 +1,81 @@
+package controller
+
+import (
+	"errors"
+	"financial-track/model"
+	"financial-track/usecase"
+	"financial-track/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type APIKeyController struct {
+	apiKeys *usecase.APIKeyUseCase
+}
+
+func NewAPIKeyController(apiKeys *usecase.APIKeyUseCase) *APIKeyController {
+	return &APIKeyController{apiKeys: apiKeys}
+}
+
+func (ac *APIKeyController) CreateAPIKey(c *gin.Context) {
+	var input model.CreateAPIKeyInput
+	errs := utils.ValidateJSON(c, &input)
+	if errs != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"message": "Fields are invalid or missing",
+			"errors":  errs,
+		})
+		return
+	}
+
+	key, err := ac.apiKeys.CreateAPIKey(c.Request.Context(), c.GetString("userId"), input)
+	if err != nil {
+		respondAPIKeyError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "API key created successfully. Store it now, it will not be shown again",
+		"apiKey":  key,
+	})
+}
+
+func (ac *APIKeyController) ListAPIKeys(c *gin.Context) {
+	keys, err := ac.apiKeys.ListAPIKeys(c.Request.Context(), c.GetString("userId"))
+	if err != nil {
+		respondAPIKeyError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"apiKeys": keys,
+	})
+}
+
+func (ac *APIKeyController) DeleteAPIKey(c *gin.Context) {
+	if err := ac.apiKeys.DeleteAPIKey(c.Request.Context(), c.GetString("userId"), c.Param("id")); err != nil {
+		respondAPIKeyError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "API key revoked successfully",
+	})
+}
+
+func respondAPIKeyError(c *gin.Context, err error) {
+	if respondContextError(c, err) {
+		return
+	}
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, usecase.ErrAPIKeyNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, usecase.ErrInvalidAPIKeyScope):
+		status = http.StatusUnprocessableEntity
+	}
+	c.JSON(status, gin.H{
+		"message": err.Error(),
+	})
+}
@@ -0,0 +1,61 @@
+package controller
+
+import (
+	"financial-track/model"
+	"financial-track/usecase"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type AuditController struct {
+	audit *usecase.AuditUseCase
+}
+
+func NewAuditController(audit *usecase.AuditUseCase) *AuditController {
+	return &AuditController{audit: audit}
+}
+
+// ListAuditLog returns the user's own audit trail.
+// Optional query: entityType=expense|user, page, perPage.
+func (ac *AuditController) ListAuditLog(c *gin.Context) {
+	page := 1
+	pageSize := 15
+	if p := c.Query("page"); p != "" {
+		if v, err := strconv.Atoi(p); err == nil && v > 0 {
+			page = v
+		}
+	}
+	if ps := c.Query("perPage"); ps != "" {
+		if v, err := strconv.Atoi(ps); err == nil && v > 0 && v <= 100 {
+			pageSize = v
+		}
+	}
+
+	logs, err := ac.audit.ListAuditLog(c.Request.Context(), c.GetString("userId"), c.Query("entityType"), page, pageSize)
+	if err != nil {
+		if respondContextError(c, err) {
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, logs)
+}
+
+// auditActor builds the change author from the request. The IP comes from
+// c.ClientIP(), which only trusts X-Forwarded-For from
+// RouterConfig.TrustedProxies; otherwise clients could pick the logged IP.
+func auditActor(c *gin.Context) model.AuditActor {
+	actor := model.AuditActor{
+		IP:        c.ClientIP(),
+		RequestID: c.GetString("requestId"),
+	}
+	if id, err := uuid.Parse(c.GetString("userId")); err == nil {
+		actor.UserID = &id
+	}
+	return actor
+}
@@ -0,0 +1,49 @@
+package controller
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"financial-track/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+func TestAuditActorIP(t *testing.T) {
+	tests := []struct {
+		name           string
+		trustedProxies []string
+		want           string
+	}{
+		{name: "ignores X-Forwarded-For from untrusted peers", want: "192.0.2.10"},
+		{name: "uses X-Forwarded-For from a trusted proxy", trustedProxies: []string{"192.0.2.0/24"}, want: "198.51.100.7"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine := gin.New()
+			if err := engine.SetTrustedProxies(tt.trustedProxies); err != nil {
+				t.Fatal(err)
+			}
+			var actor model.AuditActor
+			engine.GET("/", func(c *gin.Context) {
+				actor = auditActor(c)
+				c.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = "192.0.2.10:4321"
+			req.Header.Set("X-Forwarded-For", "198.51.100.7")
+			engine.ServeHTTP(httptest.NewRecorder(), req)
+
+			if actor.IP != tt.want {
+				t.Fatalf("actor IP = %q, want %q", actor.IP, tt.want)
+			}
+		})
+	}
+}
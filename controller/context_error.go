@@ -0,0 +1,27 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// statusClientClosedRequest is nginx's 499: the client gave up. Nobody sees it,
+// but it tells cancellations from failures in the logs.
+const statusClientClosedRequest = 499
+
+// respondContextError answers 504 when the request deadline passed and 499 when
+// the client canceled. It returns false for other errors, left to the caller.
+func respondContextError(c *gin.Context, err error) bool {
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		c.JSON(http.StatusGatewayTimeout, gin.H{"error": "request timed out"})
+	case errors.Is(err, context.Canceled):
+		c.JSON(statusClientClosedRequest, gin.H{"error": "request canceled"})
+	default:
+		return false
+	}
+	return true
+}
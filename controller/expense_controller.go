@@ -1,24 +1,43 @@
 package controller
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
 	"financial-track/model"
-	"financial-track/repository"
 	"financial-track/usecase"
 	"financial-track/utils"
+	"io"
 	"strconv"
-	"time"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 )
 
-var expenseRepository *repository.ExpenseRepository = repository.NewExpenseRepository()
-var expenseUseCase *usecase.ExpenseUseCase = usecase.NewExpenseUseCase(expenseRepository)
+type ExpenseController struct {
+	expenses *usecase.ExpenseUseCase
+}
 
-func CreateExpense(c *gin.Context) {
-	var createExpenseInput model.CreateExpenseInput
-	userId, err := c.Get("userId")
+func NewExpenseController(expenses *usecase.ExpenseUseCase) *ExpenseController {
+	return &ExpenseController{expenses: expenses}
+}
+
+// CreateExpense accepts one expense or an array (see createExpenseBatch).
+func (ec *ExpenseController) CreateExpense(c *gin.Context) {
+	body, readErr := c.GetRawData()
+	if readErr != nil {
+		c.JSON(400, gin.H{"errors": "Could not read request body"})
+		return
+	}
+	if trimmed := bytes.TrimSpace(body); len(trimmed) > 0 && trimmed[0] == '[' {
+		ec.createExpenseBatch(c, trimmed)
+		return
+	}
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
 
-	if !err {
+	var createExpenseInput model.CreateExpenseInput
+	userId := c.GetString("userId")
+	if userId == "" {
 		c.JSON(400, gin.H{"errors": "User ID not found in context"})
 		return
 	}
@@ -34,34 +53,131 @@ func CreateExpense(c *gin.Context) {
 		return
 	}
 
-	createExpenseInput.UserID = userId.(string)
+	createExpenseInput.UserID = userId
 
-	expense, expenseErr := expenseUseCase.CreateExpense(createExpenseInput)
+	expense, err := ec.expenses.CreateExpense(c.Request.Context(), createExpenseInput, auditActor(c))
+	if err != nil {
+		respondExpenseError(c, err)
+		return
+	}
+
+	resp := expense.Response()
 
-	if expenseErr != nil {
-		c.JSON(400, gin.H{"errors": err})
+	c.Header("ETag", expenseETag(expense.Version))
+	c.JSON(201, gin.H{"message": "Expense created successfully", "expense": resp})
+}
+
+// createExpenseBatch creates up to usecase.MaxBatchExpenses expenses. By default
+// it is all or nothing: any invalid item fails the request with errors keyed by
+// position. With ?partial=true valid items are created and invalid ones come
+// back in "errors".
+func (ec *ExpenseController) createExpenseBatch(c *gin.Context, body []byte) {
+	var items []json.RawMessage
+	if err := json.Unmarshal(body, &items); err != nil {
+		c.JSON(400, gin.H{"errors": gin.H{"body": "Invalid type"}})
+		return
+	}
+	if len(items) == 0 {
+		c.JSON(400, gin.H{"errors": gin.H{"body": "At least one expense is required"}})
 		return
 	}
+	if len(items) > usecase.MaxBatchExpenses {
+		c.JSON(400, gin.H{"errors": gin.H{"body": "At most " + strconv.Itoa(usecase.MaxBatchExpenses) + " expenses per request"}})
+		return
+	}
+
+	partial := c.Query("partial") == "true"
+	userId := c.GetString("userId")
 
-	resp := model.ExpenseResponse{
-		ID:            expense.ID,
-		UserID:        expense.UserID,
-		Category:      expense.Category,
-		Amount:        expense.Amount,
-		Description:   expense.Description,
-		TransactionAt: expense.TransactionAt,
-		CreatedAt:     expense.CreatedAt,
-		UpdatedAt:     expense.UpdatedAt,
+	inputs := make([]model.CreateExpenseInput, 0, len(items))
+	indexes := make([]int, 0, len(items))
+	itemErrors := map[int]map[string]string{}
+	for i, item := range items {
+		var input model.CreateExpenseInput
+		if errs := utils.ValidateJSONBytes(item, &input); errs != nil {
+			itemErrors[i] = errs
+			continue
+		}
+		if !model.IsValidCategory(input.Category) {
+			itemErrors[i] = map[string]string{"Category": "Invalid category"}
+			continue
+		}
+		input.UserID = userId
+		inputs = append(inputs, input)
+		indexes = append(indexes, i)
 	}
 
-	c.JSON(201, gin.H{"message": "Expense created successfully", "expense": resp})
+	if len(itemErrors) > 0 && (!partial || len(inputs) == 0) {
+		c.JSON(400, gin.H{"errors": itemErrors})
+		return
+	}
+
+	expenses, err := ec.expenses.CreateExpenses(c.Request.Context(), inputs, auditActor(c))
+	if err != nil {
+		respondExpenseError(c, err)
+		return
+	}
+
+	created := make([]gin.H, 0, len(expenses))
+	for i, expense := range expenses {
+		created = append(created, gin.H{"index": indexes[i], "expense": expense.Response()})
+	}
+
+	resp := gin.H{"message": "Expenses created successfully", "expenses": created}
+	if len(itemErrors) > 0 {
+		resp["errors"] = itemErrors
+	}
+	c.JSON(201, resp)
+}
+
+// ListExpenses pages the user's expenses by cursor.
+// Query: limit (1-100, default 20), cursor, sort, includeTotal, category, from, to.
+func (ec *ExpenseController) ListExpenses(c *gin.Context) {
+	limit := 20
+	if l := c.Query("limit"); l != "" {
+		v, err := strconv.Atoi(l)
+		if err != nil || v < 1 || v > 100 {
+			c.JSON(400, gin.H{"errors": gin.H{"limit": "Must be between 1 and 100"}})
+			return
+		}
+		limit = v
+	}
+
+	filter := model.ExpenseFilter{Category: model.Category(c.Query("category")), Tag: c.Query("tag")}
+	for field, target := range map[string]*model.JSONTime{"from": &filter.From, "to": &filter.To} {
+		if value := c.Query(field); value != "" {
+			if err := target.UnmarshalJSON([]byte(value)); err != nil {
+				c.JSON(400, gin.H{"errors": gin.H{field: "Invalid datetime format. Expected: " + model.LayoutYYYYMMDDHHMM}})
+				return
+			}
+		}
+	}
+
+	page, err := ec.expenses.ListExpenses(c.Request.Context(), c.GetString("userId"), filter, c.Query("sort"), c.Query("cursor"), limit, c.Query("includeTotal") == "true")
+	if err != nil {
+		respondExpenseError(c, err)
+		return
+	}
+
+	page.Links = model.CursorLinks{
+		Next: cursorLink(c, page.NextCursor),
+		Prev: cursorLink(c, page.PrevCursor),
+	}
+	c.JSON(200, page)
 }
 
-func GetMensalSummary(c *gin.Context) {
-	now := time.Now()
-	startDate := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
-	endDate := time.Now()
+func cursorLink(c *gin.Context, cursor string) string {
+	if cursor == "" {
+		return ""
+	}
+	link := *c.Request.URL
+	query := link.Query()
+	query.Set("cursor", cursor)
+	link.RawQuery = query.Encode()
+	return link.RequestURI()
+}
 
+func (ec *ExpenseController) GetMensalSummary(c *gin.Context) {
 	page := 1
 	pageSize := 15
 	if p := c.Query("page"); p != "" {
@@ -85,11 +201,196 @@ func GetMensalSummary(c *gin.Context) {
 		}
 	}
 
-	paged, err := expenseUseCase.GetMensalSummary(startDate, endDate, page, pageSize)
+	paged, err := ec.expenses.GetMensalSummary(c.Request.Context(), c.GetString("userId"), page, pageSize, c.Query("sort"))
 	if err != nil {
-		c.JSON(400, gin.H{"errors": err})
+		respondExpenseError(c, err)
 		return
 	}
 
 	c.JSON(200, paged)
 }
+
+// GetExpense returns the expense with its ETag; If-None-Match with the current
+// version answers 304.
+func (ec *ExpenseController) GetExpense(c *gin.Context) {
+	expense, err := ec.expenses.GetExpense(c.Request.Context(), c.GetString("userId"), c.Param("id"))
+	if err != nil {
+		respondExpenseError(c, err)
+		return
+	}
+
+	etag := expenseETag(expense.Version)
+	c.Header("ETag", etag)
+	if match, ok := parseETagList(c.GetHeader("If-None-Match"), true); ok && match.Matches(expense.Version) {
+		c.Status(304)
+		return
+	}
+
+	c.JSON(200, gin.H{"expense": expense.Response()})
+}
+
+// UpdateExpense requires If-Match with the current ETag (or "*").
+func (ec *ExpenseController) UpdateExpense(c *gin.Context) {
+	match, ok := requireIfMatch(c)
+	if !ok {
+		return
+	}
+
+	var input model.UpdateExpenseInput
+	errs := utils.ValidateJSON(c, &input)
+	if errs != nil {
+		c.JSON(400, gin.H{"errors": errs})
+		return
+	}
+
+	expense, err := ec.expenses.UpdateExpense(c.Request.Context(), c.GetString("userId"), c.Param("id"), match, input, auditActor(c))
+	if err != nil {
+		respondExpenseError(c, err)
+		return
+	}
+
+	c.Header("ETag", expenseETag(expense.Version))
+	c.JSON(200, gin.H{"message": "Expense updated successfully", "expense": expense.Response()})
+}
+
+// DeleteExpense moves the expense to the trash. Requires If-Match.
+func (ec *ExpenseController) DeleteExpense(c *gin.Context) {
+	match, ok := requireIfMatch(c)
+	if !ok {
+		return
+	}
+
+	err := ec.expenses.DeleteExpense(c.Request.Context(), c.GetString("userId"), c.Param("id"), match, auditActor(c))
+	if err != nil {
+		respondExpenseError(c, err)
+		return
+	}
+
+	c.JSON(200, gin.H{"message": "Expense moved to trash"})
+}
+
+func (ec *ExpenseController) ListExpenseTrash(c *gin.Context) {
+	page, pageSize := pageParams(c)
+
+	paged, err := ec.expenses.ListTrash(c.Request.Context(), c.GetString("userId"), page, pageSize)
+	if err != nil {
+		respondExpenseError(c, err)
+		return
+	}
+
+	c.JSON(200, paged)
+}
+
+func (ec *ExpenseController) RestoreExpense(c *gin.Context) {
+	expense, err := ec.expenses.RestoreExpense(c.Request.Context(), c.GetString("userId"), c.Param("id"), auditActor(c))
+	if err != nil {
+		respondExpenseError(c, err)
+		return
+	}
+
+	c.JSON(200, gin.H{"message": "Expense restored successfully", "expense": expense.Response()})
+}
+
+// PurgeExpense permanently deletes a trashed expense.
+func (ec *ExpenseController) PurgeExpense(c *gin.Context) {
+	err := ec.expenses.PurgeExpense(c.Request.Context(), c.GetString("userId"), c.Param("id"), auditActor(c))
+	if err != nil {
+		respondExpenseError(c, err)
+		return
+	}
+
+	c.JSON(200, gin.H{"message": "Expense permanently deleted"})
+}
+
+func pageParams(c *gin.Context) (int, int) {
+	page := 1
+	pageSize := 15
+	if p := c.Query("page"); p != "" {
+		if v, err := strconv.Atoi(p); err == nil && v > 0 {
+			page = v
+		}
+	}
+	if ps := c.Query("perPage"); ps != "" {
+		if v, err := strconv.Atoi(ps); err == nil && v > 0 {
+			pageSize = v
+		}
+	}
+	return page, pageSize
+}
+
+func respondExpenseError(c *gin.Context, err error) {
+	if respondContextError(c, err) {
+		return
+	}
+	if errors.Is(err, usecase.ErrExpenseNotFound) {
+		c.JSON(404, gin.H{"errors": err.Error()})
+		return
+	}
+	if errors.Is(err, usecase.ErrExpenseVersionMismatch) {
+		c.JSON(412, gin.H{"errors": err.Error()})
+		return
+	}
+	c.JSON(400, gin.H{"errors": err.Error()})
+}
+
+// BulkExpenses applies recategorize, shift_dates, add_tags, remove_tags or
+// delete to many expenses at once; "dryRun": true reports without writing.
+func (ec *ExpenseController) BulkExpenses(c *gin.Context) {
+	var input model.BulkExpenseInput
+	errs := utils.ValidateJSON(c, &input)
+	if errs != nil {
+		c.JSON(400, gin.H{"errors": errs})
+		return
+	}
+
+	result, err := ec.expenses.BulkExpenses(c.Request.Context(), c.GetString("userId"), input, auditActor(c))
+	if err != nil {
+		respondExpenseError(c, err)
+		return
+	}
+
+	c.JSON(200, result)
+}
+
+func expenseETag(version int) string {
+	return `"v` + strconv.Itoa(version) + `"`
+}
+
+// parseETagList reads If-Match/If-None-Match: "*" or a list of ETags. With weak,
+// weak tags match too (If-None-Match); If-Match compares strongly and skips
+// them. Tags that are not expense versions are ignored.
+func parseETagList(header string, weak bool) (model.VersionMatch, bool) {
+	header = strings.TrimSpace(header)
+	if header == "" {
+		return model.VersionMatch{}, false
+	}
+	if header == "*" {
+		return model.VersionMatch{Any: true}, true
+	}
+
+	var match model.VersionMatch
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if strings.HasPrefix(tag, "W/") {
+			if !weak {
+				continue
+			}
+			tag = strings.TrimPrefix(tag, "W/")
+		}
+		tag = strings.TrimSuffix(strings.TrimPrefix(tag, `"v`), `"`)
+		if v, err := strconv.Atoi(tag); err == nil {
+			match.Versions = append(match.Versions, v)
+		}
+	}
+	return match, true
+}
+
+// requireIfMatch answers 428 when the request has no If-Match.
+func requireIfMatch(c *gin.Context) (model.VersionMatch, bool) {
+	match, ok := parseETagList(c.GetHeader("If-Match"), false)
+	if !ok {
+		c.JSON(428, gin.H{"errors": "If-Match header with the expense ETag is required"})
+		return model.VersionMatch{}, false
+	}
+	return match, true
+}
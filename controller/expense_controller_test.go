@@ -0,0 +1,271 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"financial-track/model"
+	"financial-track/usecase"
+	"financial-track/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// versionedExpenseStore holds one expense and checks the version precondition
+// like ExpenseRepository.
+type versionedExpenseStore struct {
+	usecase.ExpenseStore
+
+	expense model.Expense
+}
+
+func (s *versionedExpenseStore) FindByID(ctx context.Context, userID, id uuid.UUID) (*model.Expense, error) {
+	if s.expense.ID != id || s.expense.UserID != userID {
+		return nil, gorm.ErrRecordNotFound
+	}
+	copied := s.expense
+	return &copied, nil
+}
+
+func (s *versionedExpenseStore) Update(ctx context.Context, userID, id uuid.UUID, match model.VersionMatch, change func(*model.Expense) map[string]interface{}, actor model.AuditActor) (*model.Expense, error) {
+	if _, err := s.FindByID(ctx, userID, id); err != nil {
+		return nil, err
+	}
+	if !match.Matches(s.expense.Version) {
+		return nil, usecase.ErrVersionMismatch
+	}
+	change(&s.expense)
+	s.expense.Version++
+	copied := s.expense
+	return &copied, nil
+}
+
+func (s *versionedExpenseStore) SoftDelete(ctx context.Context, userID, id uuid.UUID, match model.VersionMatch, actor model.AuditActor) error {
+	if _, err := s.FindByID(ctx, userID, id); err != nil {
+		return err
+	}
+	if !match.Matches(s.expense.Version) {
+		return usecase.ErrVersionMismatch
+	}
+	s.expense.Version++
+	return nil
+}
+
+// newExpenseEngine serves the expense routes over one expense at version 3.
+func newExpenseEngine() (*gin.Engine, *versionedExpenseStore) {
+	userID := uuid.New()
+	store := &versionedExpenseStore{expense: model.Expense{
+		ID:            uuid.New(),
+		UserID:        userID,
+		Category:      model.Category("FOOD"),
+		Amount:        10,
+		Description:   "lunch",
+		TransactionAt: time.Date(2025, 10, 5, 12, 30, 0, 0, time.UTC),
+		Version:       3,
+	}}
+	cursors := utils.NewCursorCodec([]byte("0123456789abcdef0123456789abcdef"))
+	ec := NewExpenseController(usecase.NewExpenseUseCase(store, usecase.SystemClock{}, cursors, time.UTC))
+
+	engine := gin.New()
+	engine.Use(func(c *gin.Context) { c.Set("userId", userID.String()) })
+	engine.GET("/expenses/:id", ec.GetExpense)
+	engine.PATCH("/expenses/:id", ec.UpdateExpense)
+	engine.DELETE("/expenses/:id", ec.DeleteExpense)
+	return engine, store
+}
+
+func TestParseETagList(t *testing.T) {
+	tests := []struct {
+		header string
+		weak   bool
+		want   model.VersionMatch
+		wantOK bool
+	}{
+		{header: ""},
+		{header: "*", want: model.VersionMatch{Any: true}, wantOK: true},
+		{header: `"v3"`, want: model.VersionMatch{Versions: []int{3}}, wantOK: true},
+		{header: `W/"v3", "v4"`, weak: true, want: model.VersionMatch{Versions: []int{3, 4}}, wantOK: true},
+		{header: `W/"v3", "v4"`, want: model.VersionMatch{Versions: []int{4}}, wantOK: true},
+		{header: `"abc", "v7"`, want: model.VersionMatch{Versions: []int{7}}, wantOK: true},
+		{header: `"abc"`, wantOK: true},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s weak=%v", tt.header, tt.weak), func(t *testing.T) {
+			got, ok := parseETagList(tt.header, tt.weak)
+			if ok != tt.wantOK || !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("parseETagList(%q, %v) = %+v, %v, want %+v, %v", tt.header, tt.weak, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestGetExpenseConditional(t *testing.T) {
+	tests := []struct {
+		name        string
+		ifNoneMatch string
+		wantCode    int
+	}{
+		{name: "no precondition", wantCode: http.StatusOK},
+		{name: "current version", ifNoneMatch: `"v3"`, wantCode: http.StatusNotModified},
+		{name: "weak current version", ifNoneMatch: `W/"v3"`, wantCode: http.StatusNotModified},
+		{name: "stale version", ifNoneMatch: `"v2"`, wantCode: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine, store := newExpenseEngine()
+			req := httptest.NewRequest(http.MethodGet, "/expenses/"+store.expense.ID.String(), nil)
+			if tt.ifNoneMatch != "" {
+				req.Header.Set("If-None-Match", tt.ifNoneMatch)
+			}
+			w := httptest.NewRecorder()
+			engine.ServeHTTP(w, req)
+
+			if w.Code != tt.wantCode {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantCode)
+			}
+			if etag := w.Header().Get("ETag"); etag != `"v3"` {
+				t.Errorf("ETag = %q, want %q", etag, `"v3"`)
+			}
+		})
+	}
+}
+
+func TestExpenseWritesRequireIfMatch(t *testing.T) {
+	tests := []struct {
+		name        string
+		ifMatch     string
+		wantCode    int
+		wantVersion int
+	}{
+		{name: "missing If-Match", wantCode: http.StatusPreconditionRequired, wantVersion: 3},
+		{name: "stale ETag", ifMatch: `"v2"`, wantCode: http.StatusPreconditionFailed, wantVersion: 3},
+		{name: "weak current ETag", ifMatch: `W/"v3"`, wantCode: http.StatusPreconditionFailed, wantVersion: 3},
+		{name: "current ETag", ifMatch: `"v3"`, wantCode: http.StatusOK, wantVersion: 4},
+		{name: "one of several ETags", ifMatch: `"v1", "v3"`, wantCode: http.StatusOK, wantVersion: 4},
+		{name: "any version", ifMatch: "*", wantCode: http.StatusOK, wantVersion: 4},
+	}
+	for _, method := range []string{http.MethodPatch, http.MethodDelete} {
+		for _, tt := range tests {
+			t.Run(method+" "+tt.name, func(t *testing.T) {
+				engine, store := newExpenseEngine()
+				req := httptest.NewRequest(method, "/expenses/"+store.expense.ID.String(), strings.NewReader(`{"description":"dinner"}`))
+				req.Header.Set("Content-Type", "application/json")
+				if tt.ifMatch != "" {
+					req.Header.Set("If-Match", tt.ifMatch)
+				}
+				w := httptest.NewRecorder()
+				engine.ServeHTTP(w, req)
+
+				if w.Code != tt.wantCode {
+					t.Fatalf("status = %d (%s), want %d", w.Code, w.Body, tt.wantCode)
+				}
+				if store.expense.Version != tt.wantVersion {
+					t.Errorf("version = %d, want %d", store.expense.Version, tt.wantVersion)
+				}
+				if method == http.MethodPatch && tt.wantCode == http.StatusOK {
+					if etag := w.Header().Get("ETag"); etag != `"v4"` {
+						t.Errorf("ETag = %q, want the new version", etag)
+					}
+				}
+			})
+		}
+	}
+}
+
+// batchStore records the expenses inserted by CreateBatch.
+type batchStore struct {
+	usecase.ExpenseStore
+
+	batches [][]model.Expense
+}
+
+func (s *batchStore) CreateBatch(ctx context.Context, expenses []model.Expense, actor model.AuditActor) error {
+	s.batches = append(s.batches, expenses)
+	return nil
+}
+
+func TestCreateExpenseBatch(t *testing.T) {
+	const (
+		valid   = `{"category":"FOOD","amount":10,"description":"lunch","transactionAt":"2025-10-05 12:30"}`
+		invalid = `{"category":"FOOD","amount":0,"description":"lunch","transactionAt":"2025-10-05 12:30"}`
+		badCat  = `{"category":"NOPE","amount":5,"description":"gift","transactionAt":"2025-10-05 12:30"}`
+	)
+
+	tests := []struct {
+		name        string
+		query       string
+		body        string
+		wantCode    int
+		wantCreated []int // indexes of the created items
+		wantErrors  []string
+	}{
+		{name: "all valid", body: "[" + valid + "," + valid + "]", wantCode: http.StatusCreated, wantCreated: []int{0, 1}},
+		{name: "all or nothing by default", body: "[" + valid + "," + invalid + "]", wantCode: http.StatusBadRequest, wantErrors: []string{"1"}},
+		{name: "partial keeps the valid items", query: "?partial=true", body: "[" + invalid + "," + valid + "," + badCat + "]", wantCode: http.StatusCreated, wantCreated: []int{1}, wantErrors: []string{"0", "2"}},
+		{name: "partial with nothing valid", query: "?partial=true", body: "[" + invalid + "]", wantCode: http.StatusBadRequest, wantErrors: []string{"0"}},
+		{name: "empty array", body: "[]", wantCode: http.StatusBadRequest},
+		{name: "over the limit", body: "[" + strings.TrimSuffix(strings.Repeat(valid+",", usecase.MaxBatchExpenses+1), ",") + "]", wantCode: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &batchStore{}
+			ec := NewExpenseController(usecase.NewExpenseUseCase(store, usecase.SystemClock{}, nil, time.UTC))
+			engine := gin.New()
+			engine.Use(func(c *gin.Context) { c.Set("userId", uuid.NewString()) })
+			engine.POST("/expenses/", ec.CreateExpense)
+
+			req := httptest.NewRequest(http.MethodPost, "/expenses/"+tt.query, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			engine.ServeHTTP(w, req)
+
+			if w.Code != tt.wantCode {
+				t.Fatalf("status = %d (%s), want %d", w.Code, w.Body, tt.wantCode)
+			}
+			var resp struct {
+				Expenses []struct {
+					Index int `json:"index"`
+				} `json:"expenses"`
+				Errors json.RawMessage `json:"errors"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatal(err)
+			}
+
+			var created []int
+			for _, item := range resp.Expenses {
+				created = append(created, item.Index)
+			}
+			if !reflect.DeepEqual(created, tt.wantCreated) {
+				t.Errorf("created indexes = %v, want %v", created, tt.wantCreated)
+			}
+			if wantBatches := len(tt.wantCreated) > 0; (len(store.batches) == 1) != wantBatches {
+				t.Errorf("batches = %d, want one only when something is created", len(store.batches))
+			}
+			if tt.wantErrors != nil {
+				var itemErrors map[string]json.RawMessage
+				if err := json.Unmarshal(resp.Errors, &itemErrors); err != nil {
+					t.Fatalf("errors = %s: %v", resp.Errors, err)
+				}
+				var indexes []string
+				for index := range itemErrors {
+					indexes = append(indexes, index)
+				}
+				sort.Strings(indexes)
+				if !reflect.DeepEqual(indexes, tt.wantErrors) {
+					t.Errorf("errors = %s, want indexes %v", resp.Errors, tt.wantErrors)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,164 @@
+package controller
+
+import (
+	"errors"
+	"financial-track/authtoken"
+	"financial-track/usecase"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// oidcStateCookie binds the OIDC state to the browser that started the flow.
+// The __Host- prefix requires Secure, Path=/ and no Domain.
+const oidcStateCookie = "__Host-oidc_state"
+
+type OIDCController struct {
+	socialLogin *usecase.SocialLoginUseCase
+}
+
+func NewOIDCController(socialLogin *usecase.SocialLoginUseCase) *OIDCController {
+	return &OIDCController{socialLogin: socialLogin}
+}
+
+// Login redirects the browser to the provider's authorization page.
+func (oc *OIDCController) Login(c *gin.Context) {
+	authURL, state, err := oc.socialLogin.StartLogin(c.Request.Context(), c.Param("provider"), nil)
+	if err != nil {
+		respondOIDCError(c, err)
+		return
+	}
+
+	setOIDCStateCookie(c, state)
+	c.Redirect(http.StatusFound, authURL)
+}
+
+func (oc *OIDCController) Callback(c *gin.Context) {
+	if errCode := c.Query("error"); errCode != "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"message": "Identity provider returned an error",
+			"error":   errCode,
+		})
+		return
+	}
+
+	code, state := c.Query("code"), c.Query("state")
+	if code == "" || state == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": "code and state are required",
+		})
+		return
+	}
+
+	boundState, _ := c.Cookie(oidcStateCookie)
+	clearOIDCStateCookie(c)
+
+	result, identity, err := oc.socialLogin.HandleCallback(c.Request.Context(), c.Param("provider"), code, state, boundState, sessionMetadata(c))
+	if err != nil {
+		respondOIDCError(c, err)
+		return
+	}
+
+	if identity != nil {
+		c.JSON(http.StatusOK, gin.H{
+			"message":  "Identity linked successfully",
+			"identity": identity,
+		})
+		return
+	}
+
+	if result.TwoFactorRequired {
+		c.JSON(http.StatusOK, gin.H{
+			"message":           "Two-factor authentication required",
+			"twoFactorRequired": true,
+			"preAuthToken":      result.PreAuthToken,
+			"expiresIn":         int64(authtoken.PreAuthTokenTTL.Seconds()),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":      "Login successful",
+		"token":        result.Tokens.AccessToken,
+		"refreshToken": result.Tokens.RefreshToken,
+		"tokenType":    result.Tokens.TokenType,
+		"expiresIn":    result.Tokens.ExpiresIn,
+	})
+}
+
+// LinkIdentity returns the authorization URL to link the provider to the
+// signed-in user. The login callback completes the link.
+func (oc *OIDCController) LinkIdentity(c *gin.Context) {
+	userID, err := uuid.Parse(c.GetString("userId"))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"message": "User ID not found in context",
+		})
+		return
+	}
+
+	authURL, state, err := oc.socialLogin.StartLogin(c.Request.Context(), c.Param("provider"), &userID)
+	if err != nil {
+		respondOIDCError(c, err)
+		return
+	}
+
+	setOIDCStateCookie(c, state)
+	c.JSON(http.StatusOK, gin.H{
+		"authorizationUrl": authURL,
+	})
+}
+
+func (oc *OIDCController) ListIdentities(c *gin.Context) {
+	identities, err := oc.socialLogin.ListIdentities(c.Request.Context(), c.GetString("userId"))
+	if err != nil {
+		respondOIDCError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"identities": identities,
+	})
+}
+
+func (oc *OIDCController) UnlinkIdentity(c *gin.Context) {
+	if err := oc.socialLogin.UnlinkIdentity(c.Request.Context(), c.GetString("userId"), c.Param("id")); err != nil {
+		respondOIDCError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Identity unlinked successfully",
+	})
+}
+
+func setOIDCStateCookie(c *gin.Context, state string) {
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oidcStateCookie, state, int(usecase.OIDCStateTTL.Seconds()), "/", "", true, true)
+}
+
+func clearOIDCStateCookie(c *gin.Context) {
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oidcStateCookie, "", -1, "/", "", true, true)
+}
+
+func respondOIDCError(c *gin.Context, err error) {
+	if respondContextError(c, err) {
+		return
+	}
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, usecase.ErrUnknownProvider), errors.Is(err, usecase.ErrIdentityNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, usecase.ErrInvalidOIDCState), errors.Is(err, usecase.ErrOIDCEmailRequired):
+		status = http.StatusBadRequest
+	case errors.Is(err, usecase.ErrOIDCLoginFailed):
+		status = http.StatusUnauthorized
+	case errors.Is(err, usecase.ErrOIDCEmailUnverified), errors.Is(err, usecase.ErrIdentityInUse):
+		status = http.StatusConflict
+	}
+	c.JSON(status, gin.H{
+		"message": err.Error(),
+	})
+}
@@ -0,0 +1,195 @@
+package controller
+
+import (
+	"errors"
+	"financial-track/model"
+	"financial-track/usecase"
+	"financial-track/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ProfileController struct {
+	users *usecase.UserUseCase
+}
+
+func NewProfileController(users *usecase.UserUseCase) *ProfileController {
+	return &ProfileController{users: users}
+}
+
+func (pc *ProfileController) GetProfile(c *gin.Context) {
+	user, err := pc.users.GetProfile(c.Request.Context(), c.GetString("userId"))
+	if err != nil {
+		respondProfileError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"user": user,
+	})
+}
+
+func (pc *ProfileController) UpdateProfile(c *gin.Context) {
+	var input model.UpdateProfileInput
+	errs := utils.ValidateJSON(c, &input)
+	if errs != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"message": "Fields are invalid or missing",
+			"errors":  errs,
+		})
+		return
+	}
+
+	user, err := pc.users.UpdateProfile(c.Request.Context(), c.GetString("userId"), input, auditActor(c))
+	if err != nil {
+		respondProfileError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Profile updated successfully",
+		"user":    user,
+	})
+}
+
+func (pc *ProfileController) ChangePassword(c *gin.Context) {
+	var input model.ChangePasswordInput
+	errs := utils.ValidateJSON(c, &input)
+	if errs != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"message": "Fields are invalid or missing",
+			"errors":  errs,
+		})
+		return
+	}
+
+	if err := pc.users.ChangePassword(c.Request.Context(), c.GetString("userId"), c.GetString("sessionId"), input); err != nil {
+		respondProfileError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Password changed successfully",
+	})
+}
+
+func (pc *ProfileController) RequestAccountDeletion(c *gin.Context) {
+	var input model.RequestAccountDeletionInput
+	errs := utils.ValidateJSON(c, &input)
+	if errs != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"message": "Fields are invalid or missing",
+			"errors":  errs,
+		})
+		return
+	}
+
+	if err := pc.users.RequestAccountDeletion(c.Request.Context(), c.GetString("userId"), input); err != nil {
+		respondProfileError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"message": "A confirmation token has been sent to your email",
+	})
+}
+
+func (pc *ProfileController) DeleteAccount(c *gin.Context) {
+	var input model.DeleteAccountInput
+	errs := utils.ValidateJSON(c, &input)
+	if errs != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"message": "Fields are invalid or missing",
+			"errors":  errs,
+		})
+		return
+	}
+
+	if err := pc.users.DeleteAccount(c.Request.Context(), c.GetString("userId"), input, auditActor(c)); err != nil {
+		respondProfileError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Account deleted successfully",
+	})
+}
+
+func (pc *ProfileController) SetupTwoFactor(c *gin.Context) {
+	setup, err := pc.users.SetupTwoFactor(c.Request.Context(), c.GetString("userId"))
+	if err != nil {
+		respondProfileError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, setup)
+}
+
+func (pc *ProfileController) EnableTwoFactor(c *gin.Context) {
+	var input model.EnableTwoFactorInput
+	errs := utils.ValidateJSON(c, &input)
+	if errs != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"message": "Fields are invalid or missing",
+			"errors":  errs,
+		})
+		return
+	}
+
+	codes, err := pc.users.EnableTwoFactor(c.Request.Context(), c.GetString("userId"), input)
+	if err != nil {
+		respondProfileError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":       "Two-factor authentication enabled",
+		"recoveryCodes": codes,
+	})
+}
+
+func (pc *ProfileController) DisableTwoFactor(c *gin.Context) {
+	var input model.DisableTwoFactorInput
+	errs := utils.ValidateJSON(c, &input)
+	if errs != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"message": "Fields are invalid or missing",
+			"errors":  errs,
+		})
+		return
+	}
+
+	if err := pc.users.DisableTwoFactor(c.Request.Context(), c.GetString("userId"), input); err != nil {
+		respondProfileError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Two-factor authentication disabled",
+	})
+}
+
+func respondProfileError(c *gin.Context, err error) {
+	if respondContextError(c, err) {
+		return
+	}
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, usecase.ErrUserNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, usecase.ErrInvalidPassword), errors.Is(err, usecase.ErrInvalidTwoFactorCode):
+		status = http.StatusUnauthorized
+	case errors.Is(err, usecase.ErrEmailInUse):
+		status = http.StatusConflict
+	case errors.Is(err, usecase.ErrInvalidUserToken):
+		status = http.StatusBadRequest
+	case errors.Is(err, usecase.ErrTwoFactorAlreadyEnabled),
+		errors.Is(err, usecase.ErrTwoFactorNotEnabled),
+		errors.Is(err, usecase.ErrTwoFactorNotSetUp):
+		status = http.StatusConflict
+	}
+	c.JSON(status, gin.H{
+		"message": err.Error(),
+	})
+}
@@ -0,0 +1,72 @@
+package controller
+
+import (
+	"errors"
+	"financial-track/usecase"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SessionController struct {
+	sessions *usecase.SessionUseCase
+}
+
+func NewSessionController(sessions *usecase.SessionUseCase) *SessionController {
+	return &SessionController{sessions: sessions}
+}
+
+func (sc *SessionController) ListSessions(c *gin.Context) {
+	sessions, err := sc.sessions.ListSessions(c.Request.Context(), c.GetString("userId"), c.GetString("sessionId"))
+	if err != nil {
+		if respondContextError(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"sessions": sessions,
+	})
+}
+
+func (sc *SessionController) RevokeSession(c *gin.Context) {
+	err := sc.sessions.RevokeSession(c.Request.Context(), c.GetString("userId"), c.Param("id"))
+	if err != nil {
+		if respondContextError(c, err) {
+			return
+		}
+		status := http.StatusInternalServerError
+		if errors.Is(err, usecase.ErrSessionNotFound) {
+			status = http.StatusNotFound
+		}
+		c.JSON(status, gin.H{
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Session revoked successfully",
+	})
+}
+
+// RevokeOtherSessions ends every session of the user but the current one.
+func (sc *SessionController) RevokeOtherSessions(c *gin.Context) {
+	if err := sc.sessions.RevokeOtherSessions(c.Request.Context(), c.GetString("userId"), c.GetString("sessionId")); err != nil {
+		if respondContextError(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Other sessions revoked successfully",
+	})
+}
@@ -0,0 +1,53 @@
+package controller
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"financial-track/model"
+	"financial-track/usecase"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// sessionByID holds one session of another user.
+type sessionByID struct {
+	usecase.SessionStore
+
+	session model.Session
+	revoked bool
+}
+
+func (s *sessionByID) FindByID(ctx context.Context, id string) (*model.Session, error) {
+	if s.session.ID.String() != id {
+		return nil, nil
+	}
+	copied := s.session
+	return &copied, nil
+}
+
+func (s *sessionByID) Revoke(ctx context.Context, id uuid.UUID) error {
+	s.revoked = true
+	return nil
+}
+
+func TestRevokeSessionOfAnotherUser(t *testing.T) {
+	store := &sessionByID{session: model.Session{ID: uuid.New(), UserID: uuid.New()}}
+	sc := NewSessionController(usecase.NewSessionUseCase(store))
+
+	engine := gin.New()
+	engine.Use(func(c *gin.Context) { c.Set("userId", uuid.NewString()) })
+	engine.DELETE("/auth/sessions/:id", sc.RevokeSession)
+
+	rec := httptest.NewRecorder()
+	engine.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/auth/sessions/"+store.session.ID.String(), nil))
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("status = %d, want 404", rec.Code)
+	}
+	if store.revoked {
+		t.Error("another user's session was revoked")
+	}
+}
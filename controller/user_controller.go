@@ -1,23 +1,27 @@
 package controller
 
 import (
+	"errors"
+	"financial-track/authtoken"
+	"financial-track/loginguard"
 	"financial-track/model"
-	"financial-track/repository"
 	"financial-track/usecase"
 	"financial-track/utils"
+	"math"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
-type UserController struct{}
-
-func NewUserController() *UserController {
-	return &UserController{}
+type UserController struct {
+	users *usecase.UserUseCase
 }
 
-var userRepository *repository.UserRepository = repository.NewUserRepository()
-var userUseCase *usecase.UserUseCase = usecase.NewUserUseCase(userRepository)
+func NewUserController(users *usecase.UserUseCase) *UserController {
+	return &UserController{users: users}
+}
 
 func (uc *UserController) RegisterUser(c *gin.Context) {
 	var input model.CreateUserInput
@@ -30,8 +34,11 @@ func (uc *UserController) RegisterUser(c *gin.Context) {
 		return
 	}
 
-	createdUser, err := userUseCase.RegisterUser(input)
+	createdUser, err := uc.users.RegisterUser(c.Request.Context(), input)
 	if err != nil {
+		if respondContextError(c, err) {
+			return
+		}
 		c.JSON(http.StatusBadRequest, gin.H{
 			"message": err.Error(),
 		})
@@ -55,16 +62,257 @@ func (uc *UserController) LoginUser(c *gin.Context) {
 		return
 	}
 
-	token, err := userUseCase.LoginUser(input)
+	result, err := uc.users.LoginUser(c.Request.Context(), input, sessionMetadata(c))
 	if err != nil {
+		if respondContextError(c, err) {
+			return
+		}
+		if respondLocked(c, err) {
+			return
+		}
 		c.JSON(http.StatusUnauthorized, gin.H{
 			"message": err.Error(),
 		})
 		return
 	}
 
+	if result.TwoFactorRequired {
+		c.JSON(http.StatusOK, gin.H{
+			"message":           "Two-factor authentication required",
+			"twoFactorRequired": true,
+			"preAuthToken":      result.PreAuthToken,
+			"expiresIn":         int64(authtoken.PreAuthTokenTTL.Seconds()),
+		})
+		return
+	}
+
+	tokens := result.Tokens
+	c.JSON(http.StatusOK, gin.H{
+		"message":      "Login successful",
+		"token":        tokens.AccessToken,
+		"refreshToken": tokens.RefreshToken,
+		"tokenType":    tokens.TokenType,
+		"expiresIn":    tokens.ExpiresIn,
+	})
+}
+
+func (uc *UserController) VerifyTwoFactor(c *gin.Context) {
+	var input model.VerifyTwoFactorInput
+	errs := utils.ValidateJSON(c, &input)
+	if errs != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"message": "Fields are invalid or missing",
+			"errors":  errs,
+		})
+		return
+	}
+
+	tokens, err := uc.users.VerifyTwoFactor(c.Request.Context(), input, sessionMetadata(c))
+	if err != nil {
+		if respondContextError(c, err) {
+			return
+		}
+		if respondLocked(c, err) {
+			return
+		}
+		status := http.StatusInternalServerError
+		if errors.Is(err, usecase.ErrInvalidPreAuthToken) || errors.Is(err, usecase.ErrInvalidTwoFactorCode) {
+			status = http.StatusUnauthorized
+		}
+		c.JSON(status, gin.H{
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":      "Login successful",
+		"token":        tokens.AccessToken,
+		"refreshToken": tokens.RefreshToken,
+		"tokenType":    tokens.TokenType,
+		"expiresIn":    tokens.ExpiresIn,
+	})
+}
+
+func (uc *UserController) RefreshToken(c *gin.Context) {
+	var input model.RefreshTokenInput
+	errs := utils.ValidateJSON(c, &input)
+	if errs != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"message": "Fields are invalid or missing",
+			"errors":  errs,
+		})
+		return
+	}
+
+	tokens, err := uc.users.RefreshToken(c.Request.Context(), input)
+	if err != nil {
+		if respondContextError(c, err) {
+			return
+		}
+		status := http.StatusInternalServerError
+		if errors.Is(err, usecase.ErrInvalidRefreshToken) || errors.Is(err, usecase.ErrRefreshTokenReused) {
+			status = http.StatusUnauthorized
+		}
+		c.JSON(status, gin.H{
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":      "Token refreshed successfully",
+		"token":        tokens.AccessToken,
+		"refreshToken": tokens.RefreshToken,
+		"tokenType":    tokens.TokenType,
+		"expiresIn":    tokens.ExpiresIn,
+	})
+}
+
+func (uc *UserController) Logout(c *gin.Context) {
+	expiresAt, _ := c.Get("tokenExpiresAt")
+	exp, _ := expiresAt.(time.Time)
+
+	if err := uc.users.Logout(c.Request.Context(), c.GetString("sessionId"), c.GetString("jti"), exp); err != nil {
+		if respondContextError(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"message": err.Error(),
+		})
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{
-		"message": "Login successful",
-		"token":   token,
+		"message": "Logout successful",
 	})
 }
+
+func (uc *UserController) ForgotPassword(c *gin.Context) {
+	var input model.ForgotPasswordInput
+	errs := utils.ValidateJSON(c, &input)
+	if errs != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"message": "Fields are invalid or missing",
+			"errors":  errs,
+		})
+		return
+	}
+
+	if err := uc.users.ForgotPassword(c.Request.Context(), input); err != nil {
+		if respondContextError(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "If the email is registered, a reset link has been sent",
+	})
+}
+
+func (uc *UserController) ResetPassword(c *gin.Context) {
+	var input model.ResetPasswordInput
+	errs := utils.ValidateJSON(c, &input)
+	if errs != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"message": "Fields are invalid or missing",
+			"errors":  errs,
+		})
+		return
+	}
+
+	if err := uc.users.ResetPassword(c.Request.Context(), input); err != nil {
+		respondUserTokenError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Password reset successfully",
+	})
+}
+
+func (uc *UserController) VerifyEmail(c *gin.Context) {
+	var input model.VerifyEmailInput
+	errs := utils.ValidateJSON(c, &input)
+	if errs != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"message": "Fields are invalid or missing",
+			"errors":  errs,
+		})
+		return
+	}
+
+	if err := uc.users.VerifyEmail(c.Request.Context(), input); err != nil {
+		respondUserTokenError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Email verified successfully",
+	})
+}
+
+func (uc *UserController) ResendVerification(c *gin.Context) {
+	var input model.ResendVerificationInput
+	errs := utils.ValidateJSON(c, &input)
+	if errs != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"message": "Fields are invalid or missing",
+			"errors":  errs,
+		})
+		return
+	}
+
+	if err := uc.users.ResendVerification(c.Request.Context(), input); err != nil {
+		if respondContextError(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "If the email is registered and not verified, a new link has been sent",
+	})
+}
+
+func respondUserTokenError(c *gin.Context, err error) {
+	if respondContextError(c, err) {
+		return
+	}
+	status := http.StatusInternalServerError
+	if errors.Is(err, usecase.ErrInvalidUserToken) {
+		status = http.StatusBadRequest
+	}
+	c.JSON(status, gin.H{
+		"message": err.Error(),
+	})
+}
+
+// respondLocked answers 429 with Retry-After when err is a login lockout.
+func respondLocked(c *gin.Context, err error) bool {
+	locked, ok := loginguard.IsLocked(err)
+	if !ok {
+		return false
+	}
+	seconds := int(math.Ceil(locked.RetryAfter.Seconds()))
+	c.Header("Retry-After", strconv.Itoa(seconds))
+	c.JSON(http.StatusTooManyRequests, gin.H{
+		"message":    locked.Error(),
+		"retryAfter": seconds,
+	})
+	return true
+}
+
+func sessionMetadata(c *gin.Context) model.SessionMetadata {
+	return model.SessionMetadata{
+		UserAgent: c.Request.UserAgent(),
+		IP:        c.ClientIP(),
+	}
+}
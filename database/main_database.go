@@ -3,50 +3,42 @@ package database
 import (
 	"fmt"
 	"log"
-	"os"
 	"time"
 
-	"financial-track/model"
-
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
 
-var DB *gorm.DB
-
-func Connect() {
-	dsn := os.Getenv("DB_URL")
+// Connect opens the pool for dsn with loc as the session time zone. The handle
+// is passed to whoever needs it; there is no global instance.
+func Connect(dsn string, loc *time.Location) *gorm.DB {
 	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
 		NowFunc: func() time.Time {
-			loc, _ := time.LoadLocation(getTimezone())
 			return time.Now().In(loc)
 		},
 	})
 	if err != nil {
 		log.Fatal("❌ Error to connect to database: ", err)
 	}
-	DB = db
 
-	tz := getTimezone()
-	DB.Exec("SET TIME ZONE ?", tz)
+	db.Exec("SET TIME ZONE ?", loc.String())
 	fmt.Println("✅ Database connected")
+	return db
 }
 
-func getTimezone() string {
-	tz := os.Getenv("APP_TIMEZONE")
-	if tz == "" {
-		tz = "America/Sao_Paulo"
+// Close closes the pool. It must be the last shutdown step, once nothing uses
+// the database.
+func Close(db *gorm.DB) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
 	}
-	return tz
+	return sqlDB.Close()
 }
 
-func Migrate() {
-	err := DB.AutoMigrate(
-		&model.User{},
-		&model.Expense{},
-	)
-
-	if err != nil {
+// Migrate applies pending versioned migrations (see migrate.go).
+func Migrate(db *gorm.DB) {
+	if err := MigrateUp(db); err != nil {
 		log.Fatal("❌ Error to run migrations: ", err)
 	}
 
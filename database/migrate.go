@@ -0,0 +1,289 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// migrationLockKey names the migrations advisory lock; replicas starting
+// together wait for each other.
+const migrationLockKey int64 = 7_311_552_044
+
+var migrationFileName = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+type MigrationStatus struct {
+	Version   int64      `json:"version"`
+	Name      string     `json:"name"`
+	AppliedAt *time.Time `json:"appliedAt"`
+}
+
+// LoadMigrations reads database/migrations/<version>_<name>.(up|down).sql sorted
+// by version. Every migration needs both files.
+func LoadMigrations() ([]Migration, error) {
+	entries, err := fs.ReadDir(migrationFiles, "migrations")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[int64]*Migration{}
+	for _, entry := range entries {
+		match := migrationFileName.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("invalid migration file name: %s", entry.Name())
+		}
+		version, _ := strconv.ParseInt(match[1], 10, 64)
+		content, err := migrationFiles.ReadFile(path.Join("migrations", entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		} else if m.Name != match[2] {
+			return nil, fmt.Errorf("migration %d has conflicting names: %s and %s", version, m.Name, match[2])
+		}
+		if match[3] == "up" {
+			m.Up = string(content)
+		} else {
+			m.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" || m.Down == "" {
+			return nil, fmt.Errorf("migration %d_%s must have both up and down files", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// MigrateUp applies every pending migration.
+func MigrateUp(db *gorm.DB) error {
+	migrations, err := LoadMigrations()
+	if err != nil {
+		return err
+	}
+	if len(migrations) == 0 {
+		return nil
+	}
+	return MigrateTo(db, migrations[len(migrations)-1].Version, false)
+}
+
+// ErrInitialRollback stops a rollback of the first migration, which drops every
+// table, without explicit consent.
+var ErrInitialRollback = errors.New("rolling back the initial migration drops every table and all data; confirm explicitly to proceed")
+
+// MigrateDown rolls back the last steps migrations. Rolling back the first one
+// requires allowInitial.
+func MigrateDown(db *gorm.DB, steps int, allowInitial bool) error {
+	if steps < 1 {
+		return errors.New("steps must be at least 1")
+	}
+	return withMigrationLock(db, func(ctx context.Context, conn *sql.Conn) error {
+		migrations, applied, err := migrationState(ctx, conn)
+		if err != nil {
+			return err
+		}
+		var rollback []Migration
+		for i := len(migrations) - 1; i >= 0 && len(rollback) < steps; i-- {
+			if _, ok := applied[migrations[i].Version]; ok {
+				rollback = append(rollback, migrations[i])
+			}
+		}
+		if !allowInitial && rollsBackInitial(migrations, rollback) {
+			return ErrInitialRollback
+		}
+		for _, m := range rollback {
+			if err := runMigration(ctx, conn, m, false); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// MigrateTo applies or rolls back until exactly the migrations <= version are
+// applied. version 0 rolls back everything and, as in MigrateDown, requires
+// allowInitial.
+func MigrateTo(db *gorm.DB, version int64, allowInitial bool) error {
+	return withMigrationLock(db, func(ctx context.Context, conn *sql.Conn) error {
+		migrations, applied, err := migrationState(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		known := version == 0
+		for _, m := range migrations {
+			known = known || m.Version == version
+		}
+		if !known {
+			return fmt.Errorf("unknown migration version %d", version)
+		}
+
+		var rollback []Migration
+		for i := len(migrations) - 1; i >= 0; i-- {
+			if _, ok := applied[migrations[i].Version]; ok && migrations[i].Version > version {
+				rollback = append(rollback, migrations[i])
+			}
+		}
+		if !allowInitial && rollsBackInitial(migrations, rollback) {
+			return ErrInitialRollback
+		}
+		for _, m := range rollback {
+			if err := runMigration(ctx, conn, m, false); err != nil {
+				return err
+			}
+		}
+		for _, m := range migrations {
+			if _, ok := applied[m.Version]; !ok && m.Version <= version {
+				if err := runMigration(ctx, conn, m, true); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+func rollsBackInitial(migrations, rollback []Migration) bool {
+	for _, m := range rollback {
+		if m.Version == migrations[0].Version {
+			return true
+		}
+	}
+	return false
+}
+
+// GetMigrationStatus lists known migrations and when they were applied.
+func GetMigrationStatus(db *gorm.DB) ([]MigrationStatus, error) {
+	var status []MigrationStatus
+	err := withMigrationLock(db, func(ctx context.Context, conn *sql.Conn) error {
+		migrations, applied, err := migrationState(ctx, conn)
+		if err != nil {
+			return err
+		}
+		for _, m := range migrations {
+			s := MigrationStatus{Version: m.Version, Name: m.Name}
+			if at, ok := applied[m.Version]; ok {
+				s.AppliedAt = &at
+			}
+			status = append(status, s)
+		}
+		return nil
+	})
+	return status, err
+}
+
+// withMigrationLock runs fn on a dedicated connection holding the advisory lock,
+// which is per session, so pooled connections will not do.
+func withMigrationLock(db *gorm.DB, fn func(ctx context.Context, conn *sql.Conn) error) error {
+	ctx := context.Background()
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", migrationLockKey); err != nil {
+		return fmt.Errorf("acquire migration lock: %w", err)
+	}
+	defer conn.ExecContext(ctx, "SELECT pg_advisory_unlock($1)", migrationLockKey)
+
+	_, err = conn.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version bigint PRIMARY KEY,
+			name text NOT NULL,
+			applied_at timestamptz NOT NULL DEFAULT now()
+		)`)
+	if err != nil {
+		return err
+	}
+
+	return fn(ctx, conn)
+}
+
+func migrationState(ctx context.Context, conn *sql.Conn) ([]Migration, map[int64]time.Time, error) {
+	migrations, err := LoadMigrations()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rows, err := conn.QueryContext(ctx, "SELECT version, applied_at FROM schema_migrations")
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	applied := map[int64]time.Time{}
+	for rows.Next() {
+		var version int64
+		var at time.Time
+		if err := rows.Scan(&version, &at); err != nil {
+			return nil, nil, err
+		}
+		applied[version] = at
+	}
+	return migrations, applied, rows.Err()
+}
+
+// runMigration applies or rolls back one migration in its own transaction.
+func runMigration(ctx context.Context, conn *sql.Conn, m Migration, up bool) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	script, direction := m.Down, "down"
+	if up {
+		script, direction = m.Up, "up"
+	}
+	if _, err := tx.ExecContext(ctx, script); err != nil {
+		return fmt.Errorf("migration %d_%s (%s): %w", m.Version, m.Name, direction, err)
+	}
+
+	if up {
+		_, err = tx.ExecContext(ctx, "INSERT INTO schema_migrations (version, name) VALUES ($1, $2)", m.Version, m.Name)
+	} else {
+		_, err = tx.ExecContext(ctx, "DELETE FROM schema_migrations WHERE version = $1", m.Version)
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	fmt.Printf("📦 Migration %d_%s %s\n", m.Version, m.Name, direction)
+	return nil
+}
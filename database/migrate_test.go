@@ -0,0 +1,147 @@
+package database
+
+import (
+	"errors"
+	"os"
+	"sync"
+	"testing"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestLoadMigrations(t *testing.T) {
+	migrations, err := LoadMigrations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(migrations) == 0 || migrations[0].Version != 1 {
+		t.Fatalf("first migration = %+v, want version 1", migrations)
+	}
+	for i, m := range migrations {
+		if m.Up == "" || m.Down == "" {
+			t.Errorf("migration %d_%s without up or down script", m.Version, m.Name)
+		}
+		if i > 0 && m.Version <= migrations[i-1].Version {
+			t.Errorf("migrations out of order: %d after %d", m.Version, migrations[i-1].Version)
+		}
+	}
+}
+
+// testDB opens TEST_DATABASE_URL, which the tests drop and recreate: use a
+// throwaway database.
+func testDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL not set")
+	}
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := MigrateTo(db, 0, true); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = MigrateTo(db, 0, true)
+		_ = Close(db)
+	})
+	return db
+}
+
+func TestMigrateUpConcurrentReplicas(t *testing.T) {
+	db := testDB(t)
+
+	const replicas = 4
+	var wg sync.WaitGroup
+	errs := make(chan error, replicas)
+	for i := 0; i < replicas; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- MigrateUp(db)
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("MigrateUp: %v", err)
+		}
+	}
+
+	migrations, err := LoadMigrations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var applied int64
+	if err := db.Table("schema_migrations").Count(&applied).Error; err != nil {
+		t.Fatal(err)
+	}
+	if applied != int64(len(migrations)) {
+		t.Fatalf("schema_migrations has %d rows, want %d", applied, len(migrations))
+	}
+}
+
+func TestMigrateDownRefusesInitialWithoutConfirmation(t *testing.T) {
+	db := testDB(t)
+	if err := MigrateUp(db); err != nil {
+		t.Fatal(err)
+	}
+	migrations, err := LoadMigrations()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := MigrateDown(db, len(migrations), false); !errors.Is(err, ErrInitialRollback) {
+		t.Fatalf("MigrateDown = %v, want ErrInitialRollback", err)
+	}
+	if err := MigrateTo(db, 0, false); !errors.Is(err, ErrInitialRollback) {
+		t.Fatalf("MigrateTo(0) = %v, want ErrInitialRollback", err)
+	}
+	status, err := GetMigrationStatus(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range status {
+		if s.AppliedAt == nil {
+			t.Fatalf("migration %d_%s rolled back despite the refusal", s.Version, s.Name)
+		}
+	}
+
+	if err := MigrateDown(db, len(migrations), true); err != nil {
+		t.Fatalf("MigrateDown with confirmation: %v", err)
+	}
+}
+
+func TestInitialMigrationAdoptsAutoMigrateBaseline(t *testing.T) {
+	db := testDB(t)
+
+	// Schema AutoMigrate created before versioned migrations.
+	baseline := []string{
+		`DROP TABLE IF EXISTS expenses, users CASCADE`,
+		`CREATE TABLE users (id uuid PRIMARY KEY, name text NOT NULL, email text NOT NULL CONSTRAINT uni_users_email UNIQUE, password text NOT NULL, created_at timestamptz, updated_at timestamptz)`,
+		`CREATE TABLE expenses (id uuid PRIMARY KEY, user_id uuid REFERENCES users (id), category varchar(20), amount decimal, description text, transaction_at timestamptz, created_at timestamptz, updated_at timestamptz)`,
+	}
+	for _, stmt := range baseline {
+		if err := db.Exec(stmt).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := MigrateUp(db); err != nil {
+		t.Fatalf("MigrateUp on baseline schema: %v", err)
+	}
+	for table, columns := range map[string][]string{
+		"users":    {"email_verified_at", "totp_secret", "totp_last_step", "two_factor_enabled"},
+		"expenses": {"deleted_at", "version", "tags"},
+	} {
+		for _, column := range columns {
+			if !db.Migrator().HasColumn(table, column) {
+				t.Errorf("%s.%s missing after adoption", table, column)
+			}
+		}
+	}
+}
@@ -0,0 +1,208 @@
+-- Esquema base, equivalente ao que o AutoMigrate criava. Usa IF NOT EXISTS para
+-- que bancos criados pelo AutoMigrate possam adotar as migrations versionadas;
+-- como o CREATE TABLE é ignorado quando a tabela já existe, as colunas que não
+-- existiam no esquema original são acrescentadas com ADD COLUMN IF NOT EXISTS
+-- antes dos índices que dependem delas.
+
+CREATE TABLE IF NOT EXISTS users (
+    id uuid PRIMARY KEY,
+    name text NOT NULL,
+    email text NOT NULL CONSTRAINT uni_users_email UNIQUE,
+    password text NOT NULL,
+    email_verified_at timestamptz,
+    totp_secret text,
+    totp_last_step bigint NOT NULL DEFAULT 0,
+    two_factor_enabled boolean NOT NULL DEFAULT false,
+    created_at timestamptz,
+    updated_at timestamptz
+);
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS email_verified_at timestamptz,
+    ADD COLUMN IF NOT EXISTS totp_secret text,
+    ADD COLUMN IF NOT EXISTS totp_last_step bigint NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS two_factor_enabled boolean NOT NULL DEFAULT false;
+
+CREATE TABLE IF NOT EXISTS expenses (
+    id uuid PRIMARY KEY,
+    user_id uuid CONSTRAINT fk_expenses_user REFERENCES users (id) ON DELETE CASCADE ON UPDATE CASCADE,
+    category varchar(20),
+    amount decimal,
+    description text,
+    transaction_at timestamptz,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    version bigint NOT NULL DEFAULT 1
+);
+ALTER TABLE expenses
+    ADD COLUMN IF NOT EXISTS deleted_at timestamptz,
+    ADD COLUMN IF NOT EXISTS version bigint NOT NULL DEFAULT 1;
+CREATE INDEX IF NOT EXISTS idx_expenses_user_id ON expenses (user_id);
+CREATE INDEX IF NOT EXISTS idx_expenses_transaction_at ON expenses (transaction_at);
+CREATE INDEX IF NOT EXISTS idx_expenses_deleted_at ON expenses (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_user_transaction_at ON expenses (user_id, transaction_at DESC);
+CREATE INDEX IF NOT EXISTS idx_user_amount ON expenses (user_id, amount);
+CREATE INDEX IF NOT EXISTS idx_user_category ON expenses (user_id, category);
+CREATE INDEX IF NOT EXISTS idx_user_description ON expenses (user_id, description);
+CREATE INDEX IF NOT EXISTS idx_user_created_at ON expenses (user_id, created_at);
+
+CREATE TABLE IF NOT EXISTS sessions (
+    id uuid PRIMARY KEY,
+    user_id uuid NOT NULL CONSTRAINT fk_sessions_user REFERENCES users (id) ON DELETE CASCADE ON UPDATE CASCADE,
+    user_agent text,
+    ip varchar(45),
+    created_at timestamptz,
+    last_seen_at timestamptz,
+    revoked_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_sessions_user_id ON sessions (user_id);
+
+CREATE TABLE IF NOT EXISTS refresh_tokens (
+    id uuid PRIMARY KEY,
+    user_id uuid NOT NULL CONSTRAINT fk_refresh_tokens_user REFERENCES users (id) ON DELETE CASCADE ON UPDATE CASCADE,
+    session_id uuid NOT NULL CONSTRAINT fk_refresh_tokens_session REFERENCES sessions (id) ON DELETE CASCADE ON UPDATE CASCADE,
+    token_hash text NOT NULL,
+    expires_at timestamptz NOT NULL,
+    revoked_at timestamptz,
+    replaced_by_id uuid,
+    created_at timestamptz
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_refresh_tokens_token_hash ON refresh_tokens (token_hash);
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_session_id ON refresh_tokens (session_id);
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_user_id ON refresh_tokens (user_id);
+
+CREATE TABLE IF NOT EXISTS revoked_tokens (
+    jti text PRIMARY KEY,
+    expires_at timestamptz NOT NULL,
+    created_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_revoked_tokens_expires_at ON revoked_tokens (expires_at);
+
+CREATE TABLE IF NOT EXISTS user_tokens (
+    id uuid PRIMARY KEY,
+    user_id uuid NOT NULL CONSTRAINT fk_user_tokens_user REFERENCES users (id) ON DELETE CASCADE ON UPDATE CASCADE,
+    purpose varchar(30) NOT NULL,
+    token_hash text NOT NULL,
+    expires_at timestamptz NOT NULL,
+    used_at timestamptz,
+    created_at timestamptz
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_user_tokens_token_hash ON user_tokens (token_hash);
+CREATE INDEX IF NOT EXISTS idx_user_tokens_user_id ON user_tokens (user_id);
+
+CREATE TABLE IF NOT EXISTS recovery_codes (
+    id uuid PRIMARY KEY,
+    user_id uuid NOT NULL CONSTRAINT fk_recovery_codes_user REFERENCES users (id) ON DELETE CASCADE ON UPDATE CASCADE,
+    code_hash text NOT NULL,
+    used_at timestamptz,
+    created_at timestamptz
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_recovery_codes_code_hash ON recovery_codes (code_hash);
+CREATE INDEX IF NOT EXISTS idx_recovery_codes_user_id ON recovery_codes (user_id);
+
+CREATE TABLE IF NOT EXISTS user_identities (
+    id uuid PRIMARY KEY,
+    user_id uuid NOT NULL CONSTRAINT fk_user_identities_user REFERENCES users (id) ON DELETE CASCADE ON UPDATE CASCADE,
+    provider varchar(50) NOT NULL,
+    subject text NOT NULL,
+    email text,
+    created_at timestamptz,
+    updated_at timestamptz
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_identity_provider_subject ON user_identities (provider, subject);
+CREATE INDEX IF NOT EXISTS idx_user_identities_user_id ON user_identities (user_id);
+
+CREATE TABLE IF NOT EXISTS oidc_states (
+    state_hash text PRIMARY KEY,
+    provider varchar(50) NOT NULL,
+    code_verifier text NOT NULL,
+    nonce text NOT NULL,
+    user_id uuid,
+    expires_at timestamptz NOT NULL,
+    created_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_oidc_states_expires_at ON oidc_states (expires_at);
+
+CREATE TABLE IF NOT EXISTS api_keys (
+    id uuid PRIMARY KEY,
+    user_id uuid NOT NULL CONSTRAINT fk_api_keys_user REFERENCES users (id) ON DELETE CASCADE ON UPDATE CASCADE,
+    name text NOT NULL,
+    prefix varchar(16) NOT NULL,
+    key_hash text NOT NULL,
+    scopes text NOT NULL,
+    expires_at timestamptz,
+    last_used_at timestamptz,
+    created_at timestamptz
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_api_keys_key_hash ON api_keys (key_hash);
+CREATE INDEX IF NOT EXISTS idx_api_keys_user_id ON api_keys (user_id);
+
+CREATE TABLE IF NOT EXISTS login_attempts (
+    key text PRIMARY KEY,
+    failures bigint NOT NULL DEFAULT 0,
+    last_failure_at timestamptz NOT NULL,
+    locked_until timestamptz
+);
+
+CREATE TABLE IF NOT EXISTS security_events (
+    id uuid PRIMARY KEY,
+    type varchar(50) NOT NULL,
+    user_id uuid,
+    subject text NOT NULL,
+    ip varchar(45),
+    details text,
+    created_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_security_events_type ON security_events (type);
+CREATE INDEX IF NOT EXISTS idx_security_events_user_id ON security_events (user_id);
+CREATE INDEX IF NOT EXISTS idx_security_events_created_at ON security_events (created_at);
+
+CREATE TABLE IF NOT EXISTS rate_limit_buckets (
+    key text PRIMARY KEY,
+    tokens decimal NOT NULL,
+    allowed boolean NOT NULL,
+    updated_at timestamptz NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_rate_limit_buckets_updated_at ON rate_limit_buckets (updated_at);
+
+CREATE TABLE IF NOT EXISTS audit_logs (
+    id uuid PRIMARY KEY,
+    user_id uuid NOT NULL,
+    actor_id uuid,
+    entity_type varchar(50) NOT NULL,
+    entity_id varchar(64) NOT NULL,
+    action varchar(10) NOT NULL,
+    before jsonb,
+    after jsonb,
+    ip varchar(45),
+    request_id varchar(128),
+    created_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_entity_id ON audit_logs (entity_id);
+CREATE INDEX IF NOT EXISTS idx_audit_user_created ON audit_logs (user_id, created_at DESC);
+
+-- A trilha de auditoria é append-only também no banco.
+CREATE OR REPLACE FUNCTION audit_logs_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_logs is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS audit_logs_append_only ON audit_logs;
+CREATE TRIGGER audit_logs_append_only
+    BEFORE UPDATE OR DELETE ON audit_logs
+    FOR EACH ROW EXECUTE FUNCTION audit_logs_append_only();
+
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    user_id uuid,
+    key varchar(255),
+    request_hash varchar(64) NOT NULL,
+    status_code bigint NOT NULL DEFAULT 0,
+    content_type varchar(255),
+    response_body bytea,
+    completed_at timestamptz,
+    expires_at timestamptz NOT NULL,
+    created_at timestamptz,
+    PRIMARY KEY (user_id, key)
+);
+CREATE INDEX IF NOT EXISTS idx_idempotency_keys_expires_at ON idempotency_keys (expires_at);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_expenses_tags;
+ALTER TABLE expenses DROP COLUMN IF EXISTS tags;
@@ -0,0 +1,3 @@
+-- Rótulos livres das despesas (operações em massa add_tags/remove_tags e filtro por tag).
+ALTER TABLE expenses ADD COLUMN IF NOT EXISTS tags jsonb NOT NULL DEFAULT '[]';
+CREATE INDEX IF NOT EXISTS idx_expenses_tags ON expenses USING GIN (tags);
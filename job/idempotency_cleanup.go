@@ -0,0 +1,31 @@
+package job
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// IdempotencyKeyStore is what the cleanup needs from the Idempotency-Key
+// repository.
+type IdempotencyKeyStore interface {
+	DeleteExpired(ctx context.Context) (int64, error)
+}
+
+// RunIdempotencyCleanup periodically deletes expired Idempotency-Keys.
+func RunIdempotencyCleanup(ctx context.Context, repo IdempotencyKeyStore, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := repo.DeleteExpired(ctx); err != nil {
+			log.Println("⚠️ Failed to delete expired idempotency keys:", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package job
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// LoginAttemptPruner is what the cleanup needs from loginguard.Guard.
+type LoginAttemptPruner interface {
+	Prune(ctx context.Context) (int64, error)
+}
+
+// RunLoginAttemptCleanup periodically deletes expired login counters so
+// login_attempts does not grow with every email or IP that ever failed.
+func RunLoginAttemptCleanup(ctx context.Context, guard LoginAttemptPruner, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if deleted, err := guard.Prune(ctx); err != nil {
+			log.Println("⚠️ Failed to prune login attempts:", err)
+		} else if deleted > 0 {
+			log.Printf("🧹 Pruned %d login attempt counters", deleted)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package job
+
+import (
+	"context"
+	"financial-track/usecase"
+	"log"
+	"time"
+)
+
+// RunTrashPurge periodically deletes expenses trashed longer than retention,
+// until ctx is done.
+func RunTrashPurge(ctx context.Context, expenses *usecase.ExpenseUseCase, retention, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		purged, err := expenses.PurgeExpiredTrash(ctx, retention)
+		if err != nil {
+			log.Println("⚠️ Failed to purge expense trash:", err)
+		} else if purged > 0 {
+			log.Printf("🗑️ Purged %d expenses from trash", purged)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package loginguard
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Entry is the failed attempts state of one key (account or IP).
+type Entry struct {
+	Failures      int
+	LastFailureAt time.Time
+	LockedUntil   time.Time
+}
+
+// Store persists the counters. Fail must be atomic across API replicas.
+type Store interface {
+	Get(ctx context.Context, key string) (Entry, error)
+	// Fail increments the counter, restarting it if the last failure is older
+	// than resetAfter, and returns the new state.
+	Fail(ctx context.Context, key string, now time.Time, resetAfter time.Duration) (Entry, error)
+	Lock(ctx context.Context, key string, until time.Time) error
+	Reset(ctx context.Context, key string) error
+	// DeleteStale deletes keys with no failure since now-resetAfter and no
+	// active lockout; they no longer affect any decision.
+	DeleteStale(ctx context.Context, now time.Time, resetAfter time.Duration) (int64, error)
+}
+
+// Policy sets how many failures lock a key and the exponential backoff per
+// further failure (BaseLockout, 2x, 4x... up to MaxLockout).
+type Policy struct {
+	MaxAttempts int
+	BaseLockout time.Duration
+	MaxLockout  time.Duration
+	ResetAfter  time.Duration
+}
+
+var (
+	AccountPolicy = Policy{MaxAttempts: 5, BaseLockout: 30 * time.Second, MaxLockout: time.Hour, ResetAfter: 15 * time.Minute}
+	IPPolicy      = Policy{MaxAttempts: 20, BaseLockout: 30 * time.Second, MaxLockout: time.Hour, ResetAfter: 15 * time.Minute}
+)
+
+func (p Policy) lockoutFor(failures int) time.Duration {
+	if failures < p.MaxAttempts {
+		return 0
+	}
+	d := p.BaseLockout
+	for i := p.MaxAttempts; i < failures && d < p.MaxLockout; i++ {
+		d *= 2
+	}
+	if d > p.MaxLockout {
+		d = p.MaxLockout
+	}
+	return d
+}
+
+// Subject is what is protected: the store key and its policy.
+type Subject struct {
+	Key    string
+	Policy Policy
+}
+
+func Account(id string) Subject {
+	return Subject{Key: "account:" + id, Policy: AccountPolicy}
+}
+
+func IP(ip string) Subject {
+	return Subject{Key: "ip:" + ip, Policy: IPPolicy}
+}
+
+// Lockout describes a lockout applied to a Subject.
+type Lockout struct {
+	Key      string
+	Failures int
+	Until    time.Time
+}
+
+type LockedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LockedError) Error() string {
+	return "too many failed attempts, try again later"
+}
+
+func IsLocked(err error) (*LockedError, bool) {
+	var locked *LockedError
+	ok := errors.As(err, &locked)
+	return locked, ok
+}
+
+// Clock is implemented by usecase.SystemClock.
+type Clock interface {
+	Now() time.Time
+}
+
+type Guard struct {
+	store Store
+	clock Clock
+}
+
+func NewGuard(store Store, clock Clock) *Guard {
+	return &Guard{store: store, clock: clock}
+}
+
+// Check returns a *LockedError if any subject is locked.
+func (g *Guard) Check(ctx context.Context, subjects ...Subject) error {
+	now := g.clock.Now()
+	var retryAfter time.Duration
+	for _, s := range subjects {
+		entry, err := g.store.Get(ctx, s.Key)
+		if err != nil {
+			return err
+		}
+		if wait := entry.LockedUntil.Sub(now); wait > retryAfter {
+			retryAfter = wait
+		}
+	}
+	if retryAfter > 0 {
+		return &LockedError{RetryAfter: retryAfter}
+	}
+	return nil
+}
+
+// Fail records a failure for each subject and returns the lockouts applied.
+func (g *Guard) Fail(ctx context.Context, subjects ...Subject) ([]Lockout, error) {
+	now := g.clock.Now()
+	var lockouts []Lockout
+	for _, s := range subjects {
+		entry, err := g.store.Fail(ctx, s.Key, now, s.Policy.ResetAfter)
+		if err != nil {
+			return nil, err
+		}
+		if d := s.Policy.lockoutFor(entry.Failures); d > 0 {
+			until := now.Add(d)
+			if err := g.store.Lock(ctx, s.Key, until); err != nil {
+				return nil, err
+			}
+			lockouts = append(lockouts, Lockout{Key: s.Key, Failures: entry.Failures, Until: until})
+		}
+	}
+	return lockouts, nil
+}
+
+// Prune deletes counters expired under every policy.
+func (g *Guard) Prune(ctx context.Context) (int64, error) {
+	resetAfter := AccountPolicy.ResetAfter
+	if IPPolicy.ResetAfter > resetAfter {
+		resetAfter = IPPolicy.ResetAfter
+	}
+	return g.store.DeleteStale(ctx, g.clock.Now(), resetAfter)
+}
+
+func (g *Guard) Reset(ctx context.Context, subjects ...Subject) error {
+	for _, s := range subjects {
+		if err := g.store.Reset(ctx, s.Key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
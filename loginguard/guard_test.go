@@ -0,0 +1,114 @@
+package loginguard
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+type testClock struct {
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	return c.now
+}
+
+func TestLockoutFor(t *testing.T) {
+	policy := Policy{MaxAttempts: 3, BaseLockout: 30 * time.Second, MaxLockout: 5 * time.Minute}
+
+	tests := []struct {
+		failures int
+		want     time.Duration
+	}{
+		{failures: 0, want: 0},
+		{failures: 2, want: 0},
+		{failures: 3, want: 30 * time.Second},
+		{failures: 4, want: time.Minute},
+		{failures: 5, want: 2 * time.Minute},
+		{failures: 6, want: 4 * time.Minute},
+		{failures: 7, want: 5 * time.Minute},
+		{failures: 1000, want: 5 * time.Minute},
+	}
+	for _, tt := range tests {
+		if got := policy.lockoutFor(tt.failures); got != tt.want {
+			t.Errorf("lockoutFor(%d) = %s, want %s", tt.failures, got, tt.want)
+		}
+	}
+}
+
+func TestGuard(t *testing.T) {
+	policy := Policy{MaxAttempts: 2, BaseLockout: time.Minute, MaxLockout: time.Hour, ResetAfter: 15 * time.Minute}
+	subject := Subject{Key: "account:alice@example.com", Policy: policy}
+
+	tests := []struct {
+		name string
+		// gaps is the wait before each failure.
+		gaps      []time.Duration
+		checkIn   time.Duration
+		wantRetry time.Duration
+	}{
+		{name: "below the limit", gaps: []time.Duration{0}},
+		{name: "locked at the limit", gaps: []time.Duration{0, time.Second}, wantRetry: time.Minute},
+		{name: "backoff doubles", gaps: []time.Duration{0, time.Second, 2 * time.Minute}, wantRetry: 2 * time.Minute},
+		{name: "lock expires", gaps: []time.Duration{0, time.Second}, checkIn: time.Minute},
+		{name: "old failures reset", gaps: []time.Duration{0, 16 * time.Minute}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := &testClock{now: time.Date(2025, 10, 15, 12, 0, 0, 0, time.UTC)}
+			guard := NewGuard(NewMemoryStore(), clock)
+			ctx := context.Background()
+
+			for _, gap := range tt.gaps {
+				clock.now = clock.now.Add(gap)
+				if _, err := guard.Fail(ctx, subject); err != nil {
+					t.Fatal(err)
+				}
+			}
+			clock.now = clock.now.Add(tt.checkIn)
+
+			err := guard.Check(ctx, subject)
+			locked, ok := IsLocked(err)
+			if tt.wantRetry == 0 {
+				if err != nil {
+					t.Fatalf("Check = %v, want nil", err)
+				}
+				return
+			}
+			if !ok || locked.RetryAfter != tt.wantRetry {
+				t.Fatalf("Check = %v, want locked for %s", err, tt.wantRetry)
+			}
+		})
+	}
+}
+
+func TestGuardResetAndPrune(t *testing.T) {
+	clock := &testClock{now: time.Date(2025, 10, 15, 12, 0, 0, 0, time.UTC)}
+	store := NewMemoryStore()
+	guard := NewGuard(store, clock)
+	ctx := context.Background()
+	alice, bob := Account("alice@example.com"), Account("bob@example.com")
+
+	for i := 0; i < AccountPolicy.MaxAttempts; i++ {
+		guard.Fail(ctx, alice, bob)
+	}
+	if err := guard.Reset(ctx, alice); err != nil {
+		t.Fatal(err)
+	}
+	if err := guard.Check(ctx, alice); err != nil {
+		t.Fatalf("Check after Reset = %v", err)
+	}
+	if err := guard.Check(ctx, bob); err == nil {
+		t.Fatal("Reset unlocked another subject")
+	}
+
+	clock.now = clock.now.Add(AccountPolicy.ResetAfter)
+	if n, _ := guard.Prune(ctx); n != 0 {
+		t.Fatalf("Prune deleted %d entries inside the reset window", n)
+	}
+	clock.now = clock.now.Add(time.Second)
+	if n, _ := guard.Prune(ctx); n != 1 || len(store.entries) != 0 {
+		t.Fatalf("Prune deleted %d entries, %d left, want the expired one", n, len(store.entries))
+	}
+}
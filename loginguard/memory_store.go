@@ -0,0 +1,75 @@
+package loginguard
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryStore keeps counters in process, for development or a single instance;
+// replicas need PostgresStore.
+type MemoryStore struct {
+	mu      sync.Mutex
+	entries map[string]Entry
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{entries: map[string]Entry{}}
+}
+
+func (s *MemoryStore) Get(_ context.Context, key string) (Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.entries[key], nil
+}
+
+func (s *MemoryStore) Fail(_ context.Context, key string, now time.Time, resetAfter time.Duration) (Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.evictStale(now, resetAfter)
+
+	entry := s.entries[key]
+	if now.Sub(entry.LastFailureAt) > resetAfter {
+		entry.Failures = 0
+	}
+	entry.Failures++
+	entry.LastFailureAt = now
+	s.entries[key] = entry
+	return entry, nil
+}
+
+func (s *MemoryStore) Lock(_ context.Context, key string, until time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry := s.entries[key]
+	entry.LockedUntil = until
+	s.entries[key] = entry
+	return nil
+}
+
+func (s *MemoryStore) Reset(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.entries, key)
+	return nil
+}
+
+func (s *MemoryStore) DeleteStale(_ context.Context, now time.Time, resetAfter time.Duration) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.evictStale(now, resetAfter), nil
+}
+
+// evictStale drops entries with no recent failure and no active lockout.
+func (s *MemoryStore) evictStale(now time.Time, resetAfter time.Duration) int64 {
+	var deleted int64
+	for key, entry := range s.entries {
+		if now.Sub(entry.LastFailureAt) > resetAfter && now.After(entry.LockedUntil) {
+			delete(s.entries, key)
+			deleted++
+		}
+	}
+	return deleted
+}
@@ -0,0 +1,75 @@
+package loginguard
+
+import (
+	"context"
+	"financial-track/model"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// PostgresStore shares counters between replicas in the login_attempts table.
+type PostgresStore struct {
+	db *gorm.DB
+}
+
+func NewPostgresStore(db *gorm.DB) *PostgresStore {
+	return &PostgresStore{db: db}
+}
+
+func (s *PostgresStore) Get(ctx context.Context, key string) (Entry, error) {
+	var attempt model.LoginAttempt
+	err := s.db.WithContext(ctx).Where("key = ?", key).First(&attempt).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return Entry{}, nil
+		}
+		return Entry{}, err
+	}
+	return toEntry(attempt), nil
+}
+
+func (s *PostgresStore) Fail(ctx context.Context, key string, now time.Time, resetAfter time.Duration) (Entry, error) {
+	var attempt model.LoginAttempt
+	err := s.db.WithContext(ctx).Raw(`
+		INSERT INTO login_attempts (key, failures, last_failure_at)
+		VALUES (?, 1, ?)
+		ON CONFLICT (key) DO UPDATE SET
+			failures = CASE
+				WHEN login_attempts.last_failure_at < ? THEN 1
+				ELSE login_attempts.failures + 1
+			END,
+			last_failure_at = EXCLUDED.last_failure_at
+		RETURNING key, failures, last_failure_at, locked_until`,
+		key, now, now.Add(-resetAfter),
+	).Scan(&attempt).Error
+	if err != nil {
+		return Entry{}, err
+	}
+	return toEntry(attempt), nil
+}
+
+func (s *PostgresStore) Lock(ctx context.Context, key string, until time.Time) error {
+	return s.db.WithContext(ctx).Model(&model.LoginAttempt{}).
+		Where("key = ?", key).
+		Update("locked_until", until).Error
+}
+
+func (s *PostgresStore) Reset(ctx context.Context, key string) error {
+	return s.db.WithContext(ctx).Where("key = ?", key).Delete(&model.LoginAttempt{}).Error
+}
+
+func (s *PostgresStore) DeleteStale(ctx context.Context, now time.Time, resetAfter time.Duration) (int64, error) {
+	result := s.db.WithContext(ctx).
+		Where("last_failure_at < ? AND (locked_until IS NULL OR locked_until < ?)", now.Add(-resetAfter), now).
+		Delete(&model.LoginAttempt{})
+	return result.RowsAffected, result.Error
+}
+
+func toEntry(attempt model.LoginAttempt) Entry {
+	entry := Entry{Failures: attempt.Failures, LastFailureAt: attempt.LastFailureAt}
+	if attempt.LockedUntil != nil {
+		entry.LockedUntil = *attempt.LockedUntil
+	}
+	return entry
+}
@@ -1,72 +1,98 @@
 package middleware
 
 import (
-	"errors"
+	"context"
 	"net/http"
-	"os"
 	"strings"
+	"time"
 
-	"financial-track/repository"
+	"financial-track/authtoken"
+	"financial-track/model"
+	"financial-track/usecase"
 
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt/v5"
-	"github.com/google/uuid"
 )
 
-var jwtSecret = []byte(os.Getenv("JWT_SECRET"))
+// sessionTouchInterval throttles last_seen_at updates.
+const sessionTouchInterval = time.Minute
 
-func AuthMiddleware(userRepo *repository.UserRepository) gin.HandlerFunc {
-	return func(c *gin.Context) {
-		authHeader := c.GetHeader("Authorization")
-		if authHeader == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "authorization header required"})
-			c.Abort()
-			return
-		}
+// Values of "authMethod" in the context.
+const (
+	AuthMethodSession = "session"
+	AuthMethodAPIKey  = "api_key"
+)
 
-		parts := strings.Split(authHeader, " ")
-		if len(parts) != 2 || parts[0] != "Bearer" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid authorization header"})
-			c.Abort()
-			return
-		}
+// AccessTokenVerifier checks access tokens (*authtoken.Service).
+type AccessTokenVerifier interface {
+	ParseAccessToken(tokenStr string) (*authtoken.AccessClaims, error)
+}
 
-		tokenStr := parts[1]
+// APIKeyAuthenticator checks personal API keys (*usecase.APIKeyUseCase).
+type APIKeyAuthenticator interface {
+	Authenticate(ctx context.Context, rawKey string) (*model.APIKey, error)
+}
 
-		token, err := jwt.Parse(tokenStr, func(token *jwt.Token) (interface{}, error) {
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, errors.New("unexpected signing method")
+// AuthMiddleware accepts a session JWT or a personal API key as
+// "Authorization: Bearer <token>" (API keys also in X-API-Key).
+func AuthMiddleware(
+	tokens AccessTokenVerifier,
+	userRepo usecase.UserStore,
+	tokenRepo usecase.TokenStore,
+	sessionRepo usecase.SessionStore,
+	apiKeys APIKeyAuthenticator,
+	clock usecase.Clock,
+) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tokenStr := c.GetHeader("X-API-Key")
+		if tokenStr == "" {
+			authHeader := c.GetHeader("Authorization")
+			if authHeader == "" {
+				c.JSON(http.StatusUnauthorized, gin.H{"error": "authorization header required"})
+				c.Abort()
+				return
 			}
-			return jwtSecret, nil
-		})
 
-		if err != nil || !token.Valid {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid or expired token"})
-			c.Abort()
+			parts := strings.Split(authHeader, " ")
+			if len(parts) != 2 || parts[0] != "Bearer" {
+				c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid authorization header"})
+				c.Abort()
+				return
+			}
+			tokenStr = parts[1]
+		}
+
+		if usecase.IsAPIKey(tokenStr) {
+			authenticateAPIKey(c, tokenStr, userRepo, apiKeys)
 			return
 		}
 
-		claims, ok := token.Claims.(jwt.MapClaims)
-		if !ok {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid token claims"})
+		claims, err := tokens.ParseAccessToken(tokenStr)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
 			c.Abort()
 			return
 		}
 
-		userIDStr, ok := claims["userId"].(string)
-		if !ok {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid user id in token"})
+		userIDStr, jti, sessionID := claims.UserID, claims.JTI, claims.SessionID
+
+		if revoked, err := tokenRepo.IsJTIRevoked(c.Request.Context(), jti); err != nil || revoked {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "token has been revoked"})
 			c.Abort()
 			return
 		}
 
-		if _, err := uuid.Parse(userIDStr); err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid user id format"})
+		session, err := sessionRepo.FindByID(c.Request.Context(), sessionID)
+		if err != nil || session == nil || session.RevokedAt != nil || session.UserID.String() != userIDStr {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "session has been revoked"})
 			c.Abort()
 			return
 		}
 
-		if user, err := userRepo.FindByID(userIDStr); err != nil || user == nil {
+		if clock.Now().Sub(session.LastSeenAt) > sessionTouchInterval {
+			_ = sessionRepo.Touch(c.Request.Context(), session.ID)
+		}
+
+		if user, err := userRepo.FindByID(c.Request.Context(), userIDStr); err != nil || user == nil {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "user does not exist"})
 			c.Abort()
 			return
@@ -75,7 +101,34 @@ func AuthMiddleware(userRepo *repository.UserRepository) gin.HandlerFunc {
 		}
 
 		c.Set("userId", userIDStr)
+		c.Set("jti", jti)
+		c.Set("sessionId", sessionID)
+		c.Set("tokenExpiresAt", claims.ExpiresAt)
+		c.Set("authMethod", AuthMethodSession)
 
 		c.Next()
 	}
 }
+
+func authenticateAPIKey(c *gin.Context, rawKey string, userRepo usecase.UserStore, apiKeys APIKeyAuthenticator) {
+	key, err := apiKeys.Authenticate(c.Request.Context(), rawKey)
+	if err != nil || key == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid or expired api key"})
+		c.Abort()
+		return
+	}
+
+	user, err := userRepo.FindByID(c.Request.Context(), key.UserID.String())
+	if err != nil || user == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user does not exist"})
+		c.Abort()
+		return
+	}
+
+	c.Set("user", user)
+	c.Set("userId", key.UserID.String())
+	c.Set("apiKey", key)
+	c.Set("authMethod", AuthMethodAPIKey)
+
+	c.Next()
+}
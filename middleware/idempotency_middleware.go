@@ -0,0 +1,162 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"financial-track/model"
+	"financial-track/usecase"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// IdempotencyStore keeps keys and responses (*repository.IdempotencyRepository).
+type IdempotencyStore interface {
+	Reserve(ctx context.Context, entry *model.IdempotencyKey) (bool, error)
+	Find(ctx context.Context, userID uuid.UUID, key string) (*model.IdempotencyKey, error)
+	Complete(ctx context.Context, userID uuid.UUID, key string, status int, contentType string, body []byte) error
+	Release(ctx context.Context, userID uuid.UUID, key string) error
+}
+
+// Idempotency replays the original response to authenticated writes that repeat
+// an Idempotency-Key instead of running them again. It must run after
+// AuthMiddleware; keys are per user.
+func Idempotency(repo IdempotencyStore, ttl time.Duration, clock usecase.Clock) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+		if key == "" || !isMutating(c.Request.Method) {
+			c.Next()
+			return
+		}
+		if len(key) > 255 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Idempotency-Key must be at most 255 characters"})
+			c.Abort()
+			return
+		}
+
+		userID, err := uuid.Parse(c.GetString("userId"))
+		if err != nil {
+			c.Next()
+			return
+		}
+
+		body, err := c.GetRawData()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "could not read request body"})
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		hash := sha256.New()
+		hash.Write([]byte(c.Request.Method + " " + c.Request.URL.RequestURI() + "\n"))
+		hash.Write(body)
+		requestHash := hex.EncodeToString(hash.Sum(nil))
+
+		reserved, err := repo.Reserve(c.Request.Context(), &model.IdempotencyKey{
+			UserID:      userID,
+			Key:         key,
+			RequestHash: requestHash,
+			ExpiresAt:   clock.Now().Add(ttl),
+		})
+		if err != nil {
+			log.Println("⚠️ Idempotency store unavailable:", err)
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "could not process idempotency key"})
+			c.Abort()
+			return
+		}
+
+		if !reserved {
+			replayIdempotent(c, repo, userID, key, requestHash)
+			return
+		}
+
+		// The request may have timed out in the handler; the outcome must still
+		// be stored to release or complete the key.
+		ctx := context.WithoutCancel(c.Request.Context())
+		release := func() {
+			if err := repo.Release(ctx, userID, key); err != nil {
+				log.Println("⚠️ Failed to release idempotency key:", err)
+			}
+		}
+		defer func() {
+			if r := recover(); r != nil {
+				release()
+				panic(r)
+			}
+		}()
+
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		c.Next()
+
+		// After the deadline Timeout drops the handler's response, so the status
+		// here is not what the client got: release the key so a retry runs again.
+		status := recorder.Status()
+		if c.Request.Context().Err() != nil || status >= http.StatusInternalServerError {
+			release()
+			return
+		}
+		if err := repo.Complete(ctx, userID, key, status, recorder.Header().Get("Content-Type"), recorder.body.Bytes()); err != nil {
+			log.Println("⚠️ Failed to store idempotent response:", err)
+		}
+	}
+}
+
+func replayIdempotent(c *gin.Context, repo IdempotencyStore, userID uuid.UUID, key, requestHash string) {
+	entry, err := repo.Find(c.Request.Context(), userID, key)
+	if err != nil || entry == nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "request with this Idempotency-Key is being processed, retry later"})
+		c.Abort()
+		return
+	}
+
+	if entry.RequestHash != requestHash {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "Idempotency-Key was already used with a different request"})
+		c.Abort()
+		return
+	}
+
+	if entry.CompletedAt == nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "request with this Idempotency-Key is being processed, retry later"})
+		c.Abort()
+		return
+	}
+
+	c.Header("Idempotent-Replayed", "true")
+	c.Data(entry.StatusCode, entry.ContentType, entry.ResponseBody)
+	c.Abort()
+}
+
+func isMutating(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// responseRecorder copies the body while it is sent to the client.
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseRecorder) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *responseRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
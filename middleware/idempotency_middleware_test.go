@@ -0,0 +1,185 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"financial-track/model"
+	"financial-track/usecase"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// memoryIdempotencyStore is an in-memory IdempotencyStore.
+type memoryIdempotencyStore struct {
+	mu      sync.Mutex
+	entries map[string]*model.IdempotencyKey
+}
+
+func newMemoryIdempotencyStore() *memoryIdempotencyStore {
+	return &memoryIdempotencyStore{entries: map[string]*model.IdempotencyKey{}}
+}
+
+func (s *memoryIdempotencyStore) Reserve(ctx context.Context, entry *model.IdempotencyKey) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id := entry.UserID.String() + "/" + entry.Key
+	if _, ok := s.entries[id]; ok {
+		return false, nil
+	}
+	copied := *entry
+	s.entries[id] = &copied
+	return true, nil
+}
+
+func (s *memoryIdempotencyStore) Find(ctx context.Context, userID uuid.UUID, key string) (*model.IdempotencyKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry, ok := s.entries[userID.String()+"/"+key]
+	if !ok {
+		return nil, nil
+	}
+	copied := *entry
+	return &copied, nil
+}
+
+func (s *memoryIdempotencyStore) Complete(ctx context.Context, userID uuid.UUID, key string, status int, contentType string, body []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry := s.entries[userID.String()+"/"+key]
+	now := time.Now()
+	entry.StatusCode = status
+	entry.ContentType = contentType
+	entry.ResponseBody = append([]byte(nil), body...)
+	entry.CompletedAt = &now
+	return nil
+}
+
+func (s *memoryIdempotencyStore) Release(ctx context.Context, userID uuid.UUID, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.entries, userID.String()+"/"+key)
+	return nil
+}
+
+const idempotencyTestUser = "0b6f2a4e-8f0e-4a47-9b59-2f3c1c7d9e10"
+
+// newIdempotentEngine chains Timeout, a signed-in user, Idempotency and handler,
+// counting handler runs.
+func newIdempotentEngine(store IdempotencyStore, timeout time.Duration, handler gin.HandlerFunc) (*gin.Engine, *int) {
+	calls := 0
+	engine := gin.New()
+	engine.Use(gin.CustomRecovery(func(c *gin.Context, _ any) {
+		c.AbortWithStatus(http.StatusInternalServerError)
+	}))
+	engine.Use(Timeout(timeout))
+	engine.Use(func(c *gin.Context) { c.Set("userId", idempotencyTestUser) })
+	engine.Use(Idempotency(store, time.Hour, usecase.SystemClock{}))
+	engine.POST("/expenses", func(c *gin.Context) {
+		calls++
+		handler(c)
+	})
+	return engine, &calls
+}
+
+func postIdempotent(engine *gin.Engine, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/expenses", strings.NewReader(body))
+	req.Header.Set(IdempotencyKeyHeader, key)
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+	return w
+}
+
+func TestIdempotencyReplaysCompletedResponse(t *testing.T) {
+	engine, calls := newIdempotentEngine(newMemoryIdempotencyStore(), 0, func(c *gin.Context) {
+		c.JSON(http.StatusCreated, gin.H{"id": "1"})
+	})
+
+	first := postIdempotent(engine, "k1", `{"amount":10}`)
+	second := postIdempotent(engine, "k1", `{"amount":10}`)
+
+	if *calls != 1 {
+		t.Fatalf("handler ran %d times, want 1", *calls)
+	}
+	if second.Code != http.StatusCreated || second.Body.String() != first.Body.String() {
+		t.Fatalf("replay = %d %q, want %d %q", second.Code, second.Body, first.Code, first.Body)
+	}
+	if second.Header().Get("Idempotent-Replayed") != "true" {
+		t.Fatal("replay without Idempotent-Replayed header")
+	}
+}
+
+func TestIdempotencyRejectsDifferentRequestWithSameKey(t *testing.T) {
+	engine, calls := newIdempotentEngine(newMemoryIdempotencyStore(), 0, func(c *gin.Context) {
+		c.JSON(http.StatusCreated, gin.H{"id": "1"})
+	})
+
+	postIdempotent(engine, "k1", `{"amount":10}`)
+	w := postIdempotent(engine, "k1", `{"amount":20}`)
+
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusUnprocessableEntity)
+	}
+	if *calls != 1 {
+		t.Fatalf("handler ran %d times, want 1", *calls)
+	}
+}
+
+func TestIdempotencyReleasesKeyWhenRequestFails(t *testing.T) {
+	tests := []struct {
+		name     string
+		timeout  time.Duration
+		handler  gin.HandlerFunc
+		wantCode int
+	}{
+		{
+			name: "server error",
+			handler: func(c *gin.Context) {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "boom"})
+			},
+			wantCode: http.StatusInternalServerError,
+		},
+		{
+			name:    "deadline exceeded",
+			timeout: 10 * time.Millisecond,
+			handler: func(c *gin.Context) {
+				<-c.Request.Context().Done()
+				c.JSON(http.StatusBadRequest, gin.H{"error": c.Request.Context().Err().Error()})
+			},
+			wantCode: http.StatusGatewayTimeout,
+		},
+		{
+			name: "panic",
+			handler: func(c *gin.Context) {
+				panic("boom")
+			},
+			wantCode: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newMemoryIdempotencyStore()
+			engine, calls := newIdempotentEngine(store, tt.timeout, tt.handler)
+
+			if w := postIdempotent(engine, "k1", `{}`); w.Code != tt.wantCode {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantCode)
+			}
+			entry, _ := store.Find(context.Background(), uuid.MustParse(idempotencyTestUser), "k1")
+			if entry != nil {
+				t.Fatalf("key kept after failure: %+v", entry)
+			}
+
+			postIdempotent(engine, "k1", `{}`)
+			if *calls != 2 {
+				t.Fatalf("handler ran %d times, want 2 (retry must execute again)", *calls)
+			}
+		})
+	}
+}
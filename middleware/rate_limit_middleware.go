@@ -0,0 +1,60 @@
+package middleware
+
+import (
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"financial-track/ratelimit"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RateLimitByIP limits by client IP; used on the public /auth routes.
+func RateLimitByIP(limiter *ratelimit.Limiter, policy ratelimit.Policy) gin.HandlerFunc {
+	return rateLimit(limiter, policy, func(c *gin.Context) string {
+		return "ip:" + c.ClientIP()
+	})
+}
+
+// RateLimitByUser limits by signed-in user. It must run after AuthMiddleware and
+// falls back to the IP without a user.
+func RateLimitByUser(limiter *ratelimit.Limiter, policy ratelimit.Policy) gin.HandlerFunc {
+	return rateLimit(limiter, policy, func(c *gin.Context) string {
+		if userID := c.GetString("userId"); userID != "" {
+			return "user:" + userID
+		}
+		return "ip:" + c.ClientIP()
+	})
+}
+
+func rateLimit(limiter *ratelimit.Limiter, policy ratelimit.Policy, key func(*gin.Context) string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		result, err := limiter.Allow(c.Request.Context(), key(c), policy)
+		if err != nil {
+			// Fail open: a backend outage must not take the API down.
+			log.Println("⚠️ Rate limiter unavailable:", err)
+			c.Next()
+			return
+		}
+
+		c.Header("X-RateLimit-Limit", strconv.Itoa(result.Limit))
+		c.Header("X-RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		c.Header("X-RateLimit-Reset", strconv.Itoa(ceilSeconds(result.ResetAfter)))
+
+		if !result.Allowed {
+			c.Header("Retry-After", strconv.Itoa(ceilSeconds(result.RetryAfter)))
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": "rate limit exceeded, try again later"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
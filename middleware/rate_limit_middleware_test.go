@@ -0,0 +1,141 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"financial-track/ratelimit"
+
+	"github.com/gin-gonic/gin"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+func newRateLimitedEngine(t *testing.T, trustedProxies []string) *gin.Engine {
+	t.Helper()
+	policy, err := ratelimit.ParsePolicy("test", "2/min")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	engine := gin.New()
+	if err := engine.SetTrustedProxies(trustedProxies); err != nil {
+		t.Fatal(err)
+	}
+	engine.Use(RateLimitByIP(ratelimit.NewLimiter(ratelimit.NewMemoryStore()), policy))
+	engine.GET("/", func(c *gin.Context) { c.Status(http.StatusOK) })
+	return engine
+}
+
+func TestRateLimitByIP(t *testing.T) {
+	tests := []struct {
+		name           string
+		trustedProxies []string
+		forwardedFor   []string
+		want           []int
+	}{
+		{
+			name:         "limits by connection ip",
+			forwardedFor: []string{"", "", ""},
+			want:         []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests},
+		},
+		{
+			name:         "ignores spoofed X-Forwarded-For without trusted proxies",
+			forwardedFor: []string{"198.51.100.1", "198.51.100.2", "198.51.100.3"},
+			want:         []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests},
+		},
+		{
+			name:           "uses X-Forwarded-For from a trusted proxy",
+			trustedProxies: []string{"192.0.2.0/24"},
+			forwardedFor:   []string{"198.51.100.1", "198.51.100.2", "198.51.100.3"},
+			want:           []int{http.StatusOK, http.StatusOK, http.StatusOK},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine := newRateLimitedEngine(t, tt.trustedProxies)
+			for i, forwardedFor := range tt.forwardedFor {
+				req := httptest.NewRequest(http.MethodGet, "/", nil)
+				req.RemoteAddr = "192.0.2.10:4321"
+				if forwardedFor != "" {
+					req.Header.Set("X-Forwarded-For", forwardedFor)
+				}
+				w := httptest.NewRecorder()
+				engine.ServeHTTP(w, req)
+				if w.Code != tt.want[i] {
+					t.Fatalf("request %d: status = %d, want %d", i+1, w.Code, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+// fixedStore always reports the same balance.
+type fixedStore struct {
+	tokens  float64
+	allowed bool
+	err     error
+}
+
+func (s fixedStore) Take(ctx context.Context, key string, policy ratelimit.Policy) (float64, bool, error) {
+	return s.tokens, s.allowed, s.err
+}
+
+func TestRateLimitHeaders(t *testing.T) {
+	policy := ratelimit.Policy{Name: "test", Capacity: 10, RefillPerSecond: 0.5}
+
+	tests := []struct {
+		name        string
+		store       fixedStore
+		wantCode    int
+		wantHeaders map[string]string
+	}{
+		{
+			name:     "allowed",
+			store:    fixedStore{tokens: 7.5, allowed: true},
+			wantCode: http.StatusOK,
+			wantHeaders: map[string]string{
+				"X-RateLimit-Limit": "10", "X-RateLimit-Remaining": "7", "X-RateLimit-Reset": "5", "Retry-After": "",
+			},
+		},
+		{
+			name:     "denied",
+			store:    fixedStore{tokens: 0.25},
+			wantCode: http.StatusTooManyRequests,
+			wantHeaders: map[string]string{
+				"X-RateLimit-Limit": "10", "X-RateLimit-Remaining": "0", "X-RateLimit-Reset": "20", "Retry-After": "2",
+			},
+		},
+		{
+			name:        "store unavailable fails open",
+			store:       fixedStore{err: errors.New("connection refused")},
+			wantCode:    http.StatusOK,
+			wantHeaders: map[string]string{"X-RateLimit-Limit": ""},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine := gin.New()
+			engine.Use(RateLimitByIP(ratelimit.NewLimiter(tt.store), policy))
+			engine.GET("/", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+			w := httptest.NewRecorder()
+			engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+			if w.Code != tt.wantCode {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantCode)
+			}
+			for header, want := range tt.wantHeaders {
+				if got := w.Header().Get(header); got != want {
+					t.Errorf("%s = %q, want %q", header, got, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"net/http"
+
+	"financial-track/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequireScope demands scope from API key requests. User sessions (JWT) have
+// every scope.
+func RequireScope(scope model.APIKeyScope) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetString("authMethod") != AuthMethodAPIKey {
+			c.Next()
+			return
+		}
+
+		value, _ := c.Get("apiKey")
+		key, ok := value.(*model.APIKey)
+		if !ok || !key.HasScope(scope) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "api key lacks required scope: " + string(scope)})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// RequireSession blocks API keys on account routes (profile, sessions, keys).
+func RequireSession() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetString("authMethod") != AuthMethodSession {
+			c.JSON(http.StatusForbidden, gin.H{"error": "this endpoint requires a user session"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,105 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"financial-track/model"
+	"financial-track/usecase"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// fakeAPIKeys accepts only the keys in the map.
+type fakeAPIKeys map[string]*model.APIKey
+
+func (f fakeAPIKeys) Authenticate(ctx context.Context, rawKey string) (*model.APIKey, error) {
+	if key, ok := f[rawKey]; ok {
+		return key, nil
+	}
+	return nil, errors.New("invalid or expired api key")
+}
+
+// fakeUsers returns the user with the same ID.
+type fakeUsers struct {
+	usecase.UserStore
+
+	user *model.User
+}
+
+func (f fakeUsers) FindByID(ctx context.Context, id string) (*model.User, error) {
+	if f.user.ID.String() == id {
+		return f.user, nil
+	}
+	return nil, nil
+}
+
+// newScopedEngine chains AuthMiddleware and RequireScope with read-only and
+// read-write keys.
+func newScopedEngine() *gin.Engine {
+	user := &model.User{ID: uuid.New()}
+	keys := fakeAPIKeys{
+		"ftk_read":  {ID: uuid.New(), UserID: user.ID, Scopes: string(model.ScopeExpensesRead)},
+		"ftk_write": {ID: uuid.New(), UserID: user.ID, Scopes: "expenses:read expenses:write"},
+		"ftk_ghost": {ID: uuid.New(), UserID: uuid.New(), Scopes: string(model.ScopeExpensesRead)},
+	}
+
+	engine := gin.New()
+	engine.Use(AuthMiddleware(nil, fakeUsers{user: user}, nil, nil, keys, usecase.SystemClock{}))
+	ok := func(c *gin.Context) { c.Status(http.StatusNoContent) }
+	engine.GET("/expenses", RequireScope(model.ScopeExpensesRead), ok)
+	engine.POST("/expenses", RequireScope(model.ScopeExpensesWrite), ok)
+	engine.GET("/profile", RequireSession(), ok)
+	return engine
+}
+
+func TestAPIKeyScopes(t *testing.T) {
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		header   string
+		value    string
+		wantCode int
+	}{
+		{name: "read key reads", method: http.MethodGet, path: "/expenses", header: "Authorization", value: "Bearer ftk_read", wantCode: http.StatusNoContent},
+		{name: "read key via X-API-Key", method: http.MethodGet, path: "/expenses", header: "X-API-Key", value: "ftk_read", wantCode: http.StatusNoContent},
+		{name: "read key cannot write", method: http.MethodPost, path: "/expenses", header: "X-API-Key", value: "ftk_read", wantCode: http.StatusForbidden},
+		{name: "write key writes", method: http.MethodPost, path: "/expenses", header: "X-API-Key", value: "ftk_write", wantCode: http.StatusNoContent},
+		{name: "api key cannot reach account routes", method: http.MethodGet, path: "/profile", header: "X-API-Key", value: "ftk_write", wantCode: http.StatusForbidden},
+		{name: "unknown key", method: http.MethodGet, path: "/expenses", header: "X-API-Key", value: "ftk_nope", wantCode: http.StatusUnauthorized},
+		{name: "key of a deleted user", method: http.MethodGet, path: "/expenses", header: "X-API-Key", value: "ftk_ghost", wantCode: http.StatusUnauthorized},
+		{name: "no credentials", method: http.MethodGet, path: "/expenses", wantCode: http.StatusUnauthorized},
+	}
+	engine := newScopedEngine()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			if tt.header != "" {
+				req.Header.Set(tt.header, tt.value)
+			}
+			w := httptest.NewRecorder()
+			engine.ServeHTTP(w, req)
+
+			if w.Code != tt.wantCode {
+				t.Fatalf("status = %d (%s), want %d", w.Code, w.Body, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestRequireScopeAllowsSessions(t *testing.T) {
+	engine := gin.New()
+	engine.Use(func(c *gin.Context) { c.Set("authMethod", AuthMethodSession) })
+	engine.POST("/expenses", RequireScope(model.ScopeExpensesWrite), func(c *gin.Context) { c.Status(http.StatusNoContent) })
+
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/expenses", nil))
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusNoContent)
+	}
+}
@@ -0,0 +1,85 @@
+package model
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type APIKeyScope string
+
+const (
+	ScopeExpensesRead  APIKeyScope = "expenses:read"
+	ScopeExpensesWrite APIKeyScope = "expenses:write"
+)
+
+var validAPIKeyScopes = []APIKeyScope{ScopeExpensesRead, ScopeExpensesWrite}
+
+func IsValidAPIKeyScope(s APIKeyScope) bool {
+	for _, scope := range validAPIKeyScopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// APIKey is a personal key for scripts and integrations. The full key is shown
+// only at creation; only its hash and a lookup prefix are stored.
+type APIKey struct {
+	ID         uuid.UUID  `gorm:"type:uuid;primaryKey" json:"id"`
+	UserID     uuid.UUID  `gorm:"type:uuid;index;not null" json:"userId"`
+	User       User       `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"-"`
+	Name       string     `gorm:"not null" json:"name"`
+	Prefix     string     `gorm:"type:varchar(16);not null" json:"prefix"`
+	KeyHash    string     `gorm:"uniqueIndex;not null" json:"-"`
+	Scopes     string     `gorm:"not null" json:"-"`
+	ExpiresAt  *time.Time `json:"expiresAt"`
+	LastUsedAt *time.Time `json:"lastUsedAt"`
+	CreatedAt  time.Time  `json:"createdAt"`
+}
+
+func (k *APIKey) BeforeCreate(tx *gorm.DB) (err error) {
+	k.ID = uuid.New()
+	return
+}
+
+func (k *APIKey) ScopeList() []APIKeyScope {
+	scopes := []APIKeyScope{}
+	for _, s := range strings.Fields(k.Scopes) {
+		scopes = append(scopes, APIKeyScope(s))
+	}
+	return scopes
+}
+
+func (k *APIKey) HasScope(scope APIKeyScope) bool {
+	for _, s := range k.ScopeList() {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+type CreateAPIKeyInput struct {
+	Name          string        `json:"name" binding:"required,max=100"`
+	Scopes        []APIKeyScope `json:"scopes" binding:"required,min=1"`
+	ExpiresInDays *int          `json:"expiresInDays" binding:"omitempty,min=1,max=365"`
+}
+
+type APIKeyResponse struct {
+	ID         uuid.UUID     `json:"id"`
+	Name       string        `json:"name"`
+	Prefix     string        `json:"prefix"`
+	Scopes     []APIKeyScope `json:"scopes"`
+	ExpiresAt  *time.Time    `json:"expiresAt"`
+	LastUsedAt *time.Time    `json:"lastUsedAt"`
+	CreatedAt  time.Time     `json:"createdAt"`
+}
+
+type CreatedAPIKeyResponse struct {
+	APIKeyResponse
+	Key string `json:"key"`
+}
@@ -0,0 +1,94 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type AuditAction string
+
+const (
+	AuditCreate  AuditAction = "CREATE"
+	AuditUpdate  AuditAction = "UPDATE"
+	AuditDelete  AuditAction = "DELETE"
+	AuditRestore AuditAction = "RESTORE"
+	AuditPurge   AuditAction = "PURGE"
+)
+
+const (
+	AuditEntityExpense = "expense"
+	AuditEntityUser    = "user"
+)
+
+// AuditActor is who made the change and from where.
+type AuditActor struct {
+	UserID    *uuid.UUID
+	IP        string
+	RequestID string
+}
+
+// AuditLog is append-only: the app never updates or deletes it and the database
+// rejects UPDATE/DELETE (see database.Migrate). There is no FK to users so the
+// history outlives the entity.
+type AuditLog struct {
+	ID         uuid.UUID   `gorm:"type:uuid;primaryKey" json:"id"`
+	UserID     uuid.UUID   `gorm:"type:uuid;index:idx_audit_user_created,priority:1;not null" json:"userId"`
+	ActorID    *uuid.UUID  `gorm:"type:uuid" json:"actorId"`
+	EntityType string      `gorm:"type:varchar(50);not null" json:"entityType"`
+	EntityID   string      `gorm:"type:varchar(64);index;not null" json:"entityId"`
+	Action     AuditAction `gorm:"type:varchar(10);not null" json:"action"`
+	Before     RawJSON     `gorm:"type:jsonb" json:"before"`
+	After      RawJSON     `gorm:"type:jsonb" json:"after"`
+	IP         string      `gorm:"type:varchar(45)" json:"ip"`
+	RequestID  string      `gorm:"type:varchar(128)" json:"requestId"`
+	CreatedAt  time.Time   `gorm:"index:idx_audit_user_created,priority:2,sort:desc" json:"createdAt"`
+}
+
+func (a *AuditLog) BeforeCreate(tx *gorm.DB) (err error) {
+	a.ID = uuid.New()
+	return
+}
+
+type PagedAuditLog struct {
+	Data        []AuditLog `json:"data"`
+	CurrentPage int        `json:"currentPage"`
+	LastPage    int        `json:"lastPage"`
+	TotalItems  int64      `json:"totalItems"`
+	PerPage     int        `json:"perPage"`
+}
+
+// RawJSON holds an already serialized JSON document in a jsonb column.
+type RawJSON json.RawMessage
+
+func (j RawJSON) Value() (driver.Value, error) {
+	if len(j) == 0 {
+		return nil, nil
+	}
+	return string(j), nil
+}
+
+func (j *RawJSON) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*j = nil
+	case []byte:
+		*j = append((*j)[:0], v...)
+	case string:
+		*j = RawJSON(v)
+	default:
+		return errors.New("unsupported type for RawJSON")
+	}
+	return nil
+}
+
+func (j RawJSON) MarshalJSON() ([]byte, error) {
+	if len(j) == 0 {
+		return []byte("null"), nil
+	}
+	return j, nil
+}
@@ -36,20 +36,28 @@ func IsValidCategory(c Category) bool {
 	return false
 }
 
+// Expense.Version grows on every change and is exposed as the ETag (optimistic
+// concurrency).
 type Expense struct {
-	ID            uuid.UUID `gorm:"type:uuid;primaryKey" json:"id"`
-	UserID        uuid.UUID `gorm:"index;index:idx_user_transaction_at,priority:1" json:"userId"`
-	User          User      `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"user"`
-	Category      Category  `gorm:"type:varchar(20)" json:"category"`
-	Amount        float64   `json:"amount"`
-	Description   string    `json:"description"`
-	TransactionAt time.Time `gorm:"index;index:idx_user_transaction_at,priority:2,sort:desc" json:"transactionAt"`
-	CreatedAt     time.Time `json:"createdAt"`
-	UpdatedAt     time.Time `json:"updatedAt"`
+	ID            uuid.UUID      `gorm:"type:uuid;primaryKey" json:"id"`
+	UserID        uuid.UUID      `gorm:"index;index:idx_user_transaction_at,priority:1;index:idx_user_amount,priority:1;index:idx_user_category,priority:1;index:idx_user_description,priority:1;index:idx_user_created_at,priority:1" json:"userId"`
+	User          User           `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"user"`
+	Category      Category       `gorm:"type:varchar(20);index:idx_user_category,priority:2" json:"category"`
+	Amount        float64        `gorm:"index:idx_user_amount,priority:2" json:"amount"`
+	Description   string         `gorm:"index:idx_user_description,priority:2" json:"description"`
+	TransactionAt time.Time      `gorm:"index;index:idx_user_transaction_at,priority:2,sort:desc" json:"transactionAt"`
+	CreatedAt     time.Time      `gorm:"index:idx_user_created_at,priority:2" json:"createdAt"`
+	UpdatedAt     time.Time      `json:"updatedAt"`
+	DeletedAt     gorm.DeletedAt `gorm:"index" json:"deletedAt"`
+	Version       int            `gorm:"not null;default:1" json:"version"`
+	Tags          Tags           `gorm:"type:jsonb;not null;default:'[]'" json:"tags"`
 }
 
 func (u *Expense) BeforeCreate(tx *gorm.DB) (err error) {
 	u.ID = uuid.New()
+	if u.Version == 0 {
+		u.Version = 1
+	}
 	return
 }
 
@@ -59,17 +67,52 @@ type CreateExpenseInput struct {
 	Amount        float64  `json:"amount" binding:"required,gt=0"`
 	Description   string   `json:"description" binding:"required"`
 	TransactionAt JSONTime `json:"transactionAt" binding:"required"`
+	Tags          []string `json:"tags"`
+}
+
+// UpdateExpenseInput changes only the fields sent.
+type UpdateExpenseInput struct {
+	Category      *Category `json:"category"`
+	Amount        *float64  `json:"amount" binding:"omitempty,gt=0"`
+	Description   *string   `json:"description"`
+	TransactionAt *JSONTime `json:"transactionAt"`
+	Tags          *[]string `json:"tags"`
 }
 
 type ExpenseResponse struct {
-	ID            uuid.UUID `json:"id"`
-	UserID        uuid.UUID `json:"userId"`
-	Category      Category  `gorm:"type:varchar(20)" json:"category" binding:"required"`
-	Amount        float64   `json:"amount"`
-	Description   string    `json:"description"`
-	TransactionAt time.Time `json:"transactionAt"`
-	CreatedAt     time.Time `json:"createdAt"`
-	UpdatedAt     time.Time `json:"updatedAt"`
+	ID            uuid.UUID  `json:"id"`
+	UserID        uuid.UUID  `json:"userId"`
+	Category      Category   `gorm:"type:varchar(20)" json:"category" binding:"required"`
+	Amount        float64    `json:"amount"`
+	Description   string     `json:"description"`
+	TransactionAt time.Time  `json:"transactionAt"`
+	CreatedAt     time.Time  `json:"createdAt"`
+	UpdatedAt     time.Time  `json:"updatedAt"`
+	DeletedAt     *time.Time `json:"deletedAt,omitempty"`
+	Version       int        `json:"version"`
+	Tags          Tags       `json:"tags"`
+}
+
+func (e Expense) Response() ExpenseResponse {
+	resp := ExpenseResponse{
+		ID:            e.ID,
+		UserID:        e.UserID,
+		Category:      e.Category,
+		Amount:        e.Amount,
+		Description:   e.Description,
+		TransactionAt: e.TransactionAt,
+		CreatedAt:     e.CreatedAt,
+		UpdatedAt:     e.UpdatedAt,
+		Version:       e.Version,
+		Tags:          e.Tags,
+	}
+	if resp.Tags == nil {
+		resp.Tags = Tags{}
+	}
+	if e.DeletedAt.Valid {
+		resp.DeletedAt = &e.DeletedAt.Time
+	}
+	return resp
 }
 
 type Summary struct {
@@ -85,3 +128,22 @@ type PagedSummary struct {
 	TotalItems  int64             `json:"totalItems"`
 	PerPage     int               `json:"perPage"`
 }
+
+// VersionMatch is an If-Match precondition: any version ("*") or one of those
+// listed.
+type VersionMatch struct {
+	Any      bool
+	Versions []int
+}
+
+func (m VersionMatch) Matches(version int) bool {
+	if m.Any {
+		return true
+	}
+	for _, v := range m.Versions {
+		if v == version {
+			return true
+		}
+	}
+	return false
+}
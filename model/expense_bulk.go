@@ -0,0 +1,57 @@
+package model
+
+type BulkOperation string
+
+const (
+	BulkRecategorize BulkOperation = "recategorize"
+	BulkShiftDates   BulkOperation = "shift_dates"
+	BulkDelete       BulkOperation = "delete"
+	BulkAddTags      BulkOperation = "add_tags"
+	BulkRemoveTags   BulkOperation = "remove_tags"
+)
+
+// ExpenseFilter selects the user's expenses; empty criteria are ignored.
+type ExpenseFilter struct {
+	Category            Category `json:"category"`
+	From                JSONTime `json:"from"`
+	To                  JSONTime `json:"to"`
+	DescriptionContains string   `json:"descriptionContains"`
+	Tag                 string   `json:"tag"`
+}
+
+func (f ExpenseFilter) IsEmpty() bool {
+	return f.Category == "" && f.From.IsZero() && f.To.IsZero() && f.DescriptionContains == "" && f.Tag == ""
+}
+
+// BulkExpenseInput applies Operation to the expenses in IDs or those matching
+// Filter (exactly one of them). DryRun runs the operation and rolls it back.
+type BulkExpenseInput struct {
+	IDs       []string       `json:"ids"`
+	Filter    *ExpenseFilter `json:"filter"`
+	Operation BulkOperation  `json:"operation" binding:"required"`
+	Category  Category       `json:"category"`
+	ShiftDays int            `json:"shiftDays"`
+	Tags      []string       `json:"tags"`
+	DryRun    bool           `json:"dryRun"`
+}
+
+type BulkItemStatus string
+
+const (
+	BulkItemApplied  BulkItemStatus = "applied"
+	BulkItemNotFound BulkItemStatus = "not_found"
+)
+
+type BulkItemResult struct {
+	ID     string           `json:"id"`
+	Status BulkItemStatus   `json:"status"`
+	Before *ExpenseResponse `json:"before,omitempty"`
+	After  *ExpenseResponse `json:"after,omitempty"`
+}
+
+type BulkExpenseResult struct {
+	Operation BulkOperation    `json:"operation"`
+	DryRun    bool             `json:"dryRun"`
+	Affected  int              `json:"affected"`
+	Results   []BulkItemResult `json:"results"`
+}
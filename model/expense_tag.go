@@ -0,0 +1,104 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	MaxTagsPerExpense = 20
+	MaxTagLength      = 32
+)
+
+// Tags are an expense's free-form labels, stored as a jsonb array. They are
+// always normalized: lowercase, trimmed, unique and sorted.
+type Tags []string
+
+func (t Tags) Value() (driver.Value, error) {
+	if t == nil {
+		t = Tags{}
+	}
+	data, err := json.Marshal([]string(t))
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+func (t *Tags) Scan(value interface{}) error {
+	var data []byte
+	switch v := value.(type) {
+	case nil:
+		*t = Tags{}
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("tags: unsupported type %T", value)
+	}
+	var tags []string
+	if err := json.Unmarshal(data, &tags); err != nil {
+		return err
+	}
+	*t = tags
+	return nil
+}
+
+// NormalizeTags validates and normalizes user supplied tags.
+func NormalizeTags(raw []string) (Tags, error) {
+	seen := make(map[string]bool, len(raw))
+	tags := Tags{}
+	for _, tag := range raw {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" {
+			return nil, errors.New("tags cannot be empty")
+		}
+		if utf8.RuneCountInString(tag) > MaxTagLength {
+			return nil, fmt.Errorf("tags must have at most %d characters", MaxTagLength)
+		}
+		if !seen[tag] {
+			seen[tag] = true
+			tags = append(tags, tag)
+		}
+	}
+	if len(tags) > MaxTagsPerExpense {
+		return nil, fmt.Errorf("at most %d tags per expense", MaxTagsPerExpense)
+	}
+	sort.Strings(tags)
+	return tags, nil
+}
+
+// Add returns t plus the tags of other it lacks.
+func (t Tags) Add(other Tags) Tags {
+	merged := append(append(Tags{}, t...), other...)
+	sort.Strings(merged)
+	out := Tags{}
+	for i, tag := range merged {
+		if i == 0 || tag != merged[i-1] {
+			out = append(out, tag)
+		}
+	}
+	return out
+}
+
+// Remove returns t without the tags of other.
+func (t Tags) Remove(other Tags) Tags {
+	drop := make(map[string]bool, len(other))
+	for _, tag := range other {
+		drop[tag] = true
+	}
+	out := Tags{}
+	for _, tag := range t {
+		if !drop[tag] {
+			out = append(out, tag)
+		}
+	}
+	return out
+}
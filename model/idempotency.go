@@ -0,0 +1,21 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// IdempotencyKey stores the response to a write sent with an Idempotency-Key so
+// retries get the original response. A nil CompletedAt means still in progress.
+type IdempotencyKey struct {
+	UserID       uuid.UUID `gorm:"type:uuid;primaryKey"`
+	Key          string    `gorm:"type:varchar(255);primaryKey"`
+	RequestHash  string    `gorm:"type:varchar(64);not null"`
+	StatusCode   int       `gorm:"not null;default:0"`
+	ContentType  string    `gorm:"type:varchar(255)"`
+	ResponseBody []byte    `gorm:"type:bytea"`
+	CompletedAt  *time.Time
+	ExpiresAt    time.Time `gorm:"index;not null"`
+	CreatedAt    time.Time
+}
@@ -0,0 +1,42 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// UserIdentity links a user to an external OIDC account, one per
+// provider and subject.
+type UserIdentity struct {
+	ID        uuid.UUID `gorm:"type:uuid;primaryKey" json:"id"`
+	UserID    uuid.UUID `gorm:"type:uuid;index;not null" json:"userId"`
+	User      User      `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"-"`
+	Provider  string    `gorm:"type:varchar(50);not null;uniqueIndex:idx_identity_provider_subject,priority:1" json:"provider"`
+	Subject   string    `gorm:"not null;uniqueIndex:idx_identity_provider_subject,priority:2" json:"subject"`
+	Email     string    `json:"email"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+func (i *UserIdentity) BeforeCreate(tx *gorm.DB) (err error) {
+	i.ID = uuid.New()
+	return
+}
+
+// OIDCState keeps the authorization code + PKCE flow data between redirect and
+// callback. UserID is set when the flow links an identity to a signed-in user.
+type OIDCState struct {
+	StateHash    string     `gorm:"primaryKey" json:"-"`
+	Provider     string     `gorm:"type:varchar(50);not null" json:"provider"`
+	CodeVerifier string     `gorm:"not null" json:"-"`
+	Nonce        string     `gorm:"not null" json:"-"`
+	UserID       *uuid.UUID `gorm:"type:uuid" json:"userId"`
+	ExpiresAt    time.Time  `gorm:"index;not null" json:"expiresAt"`
+	CreatedAt    time.Time  `json:"createdAt"`
+}
+
+func (OIDCState) TableName() string {
+	return "oidc_states"
+}
@@ -11,3 +11,35 @@ type PaginationParams struct {
 	Page     int `json:"page"`
 	PageSize int `json:"perPage"`
 }
+
+type CursorDirection string
+
+const (
+	CursorNext CursorDirection = "next"
+	CursorPrev CursorDirection = "prev"
+)
+
+// ExpenseCursor is a position in the Sort order: the cursor row's value for each
+// key, including the id tiebreaker. Clients get it as an opaque signed cursor.
+type ExpenseCursor struct {
+	Sort      string          `json:"s"`
+	Filter    string          `json:"f"`
+	Values    []interface{}   `json:"v"`
+	Direction CursorDirection `json:"d"`
+}
+
+type CursorLinks struct {
+	Next string `json:"next,omitempty"`
+	Prev string `json:"prev,omitempty"`
+}
+
+// ExpenseCursorPage is one keyset page. TotalItems is only computed on request
+// (includeTotal=true).
+type ExpenseCursorPage struct {
+	Data       []ExpenseResponse `json:"data"`
+	NextCursor string            `json:"nextCursor,omitempty"`
+	PrevCursor string            `json:"prevCursor,omitempty"`
+	Links      CursorLinks       `json:"links"`
+	TotalItems *int64            `json:"totalItems,omitempty"`
+	PerPage    int               `json:"perPage"`
+}
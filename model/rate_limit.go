@@ -0,0 +1,11 @@
+package model
+
+import "time"
+
+// RateLimitBucket is a token bucket state for the Postgres rate limit backend.
+type RateLimitBucket struct {
+	Key       string    `gorm:"primaryKey"`
+	Tokens    float64   `gorm:"not null"`
+	Allowed   bool      `gorm:"not null"`
+	UpdatedAt time.Time `gorm:"index;not null"`
+}
@@ -0,0 +1,38 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// LoginAttempt holds login failures per key ("account:<email>", "ip:<ip>").
+type LoginAttempt struct {
+	Key           string     `gorm:"primaryKey" json:"key"`
+	Failures      int        `gorm:"not null;default:0" json:"failures"`
+	LastFailureAt time.Time  `gorm:"not null" json:"lastFailureAt"`
+	LockedUntil   *time.Time `json:"lockedUntil"`
+}
+
+type SecurityEventType string
+
+const (
+	LoginLockoutEvent SecurityEventType = "LOGIN_LOCKOUT"
+)
+
+// SecurityEvent records security events worth auditing.
+type SecurityEvent struct {
+	ID        uuid.UUID         `gorm:"type:uuid;primaryKey" json:"id"`
+	Type      SecurityEventType `gorm:"type:varchar(50);index;not null" json:"type"`
+	UserID    *uuid.UUID        `gorm:"type:uuid;index" json:"userId"`
+	Subject   string            `gorm:"not null" json:"subject"`
+	IP        string            `gorm:"type:varchar(45)" json:"ip"`
+	Details   string            `json:"details"`
+	CreatedAt time.Time         `gorm:"index" json:"createdAt"`
+}
+
+func (e *SecurityEvent) BeforeCreate(tx *gorm.DB) (err error) {
+	e.ID = uuid.New()
+	return
+}
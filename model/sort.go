@@ -0,0 +1,129 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// SortKey is one sort column. Field is the name the API exposes.
+type SortKey struct {
+	Field  string
+	Column string
+	Desc   bool
+}
+
+const maxExpenseSortKeys = 3
+
+const DefaultExpenseSort = "-transactionAt"
+
+// expenseSortColumns whitelists the sortable fields, each indexed with user_id
+// on Expense.
+var expenseSortColumns = map[string]string{
+	"transactionAt": "transaction_at",
+	"amount":        "amount",
+	"category":      "category",
+	"description":   "description",
+	"createdAt":     "created_at",
+}
+
+// ParseExpenseSort reads "field,-field2" ("-" = descending). id is always
+// appended as a tiebreaker, in the first key's direction, for a stable order.
+func ParseExpenseSort(value string) ([]SortKey, error) {
+	if strings.TrimSpace(value) == "" {
+		value = DefaultExpenseSort
+	}
+
+	var keys []SortKey
+	seen := map[string]bool{}
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		desc := strings.HasPrefix(part, "-")
+		field := strings.TrimPrefix(strings.TrimPrefix(part, "-"), "+")
+
+		column, ok := expenseSortColumns[field]
+		if !ok {
+			return nil, fmt.Errorf("invalid sort field: %q", field)
+		}
+		if seen[field] {
+			return nil, fmt.Errorf("duplicate sort field: %q", field)
+		}
+		seen[field] = true
+		keys = append(keys, SortKey{Field: field, Column: column, Desc: desc})
+	}
+	if len(keys) > maxExpenseSortKeys {
+		return nil, errors.New("too many sort fields")
+	}
+
+	return append(keys, SortKey{Field: "id", Column: "id", Desc: keys[0].Desc}), nil
+}
+
+// SortString returns the canonical sort, used to bind cursors.
+func SortString(keys []SortKey) string {
+	parts := make([]string, 0, len(keys))
+	for _, key := range keys {
+		if key.Desc {
+			parts = append(parts, "-"+key.Field)
+		} else {
+			parts = append(parts, key.Field)
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// OrderClause builds the ORDER BY; reverse flips every direction.
+func OrderClause(keys []SortKey, reverse bool) string {
+	parts := make([]string, 0, len(keys))
+	for _, key := range keys {
+		if key.Desc != reverse {
+			parts = append(parts, key.Column+" DESC")
+		} else {
+			parts = append(parts, key.Column+" ASC")
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// SortValue returns the expense's value for a sort field.
+func (e Expense) SortValue(field string) interface{} {
+	switch field {
+	case "transactionAt":
+		return e.TransactionAt
+	case "amount":
+		return e.Amount
+	case "category":
+		return string(e.Category)
+	case "description":
+		return e.Description
+	case "createdAt":
+		return e.CreatedAt
+	case "id":
+		return e.ID.String()
+	}
+	return nil
+}
+
+// DecodeSortValue converts a cursor JSON value to the column's type.
+func DecodeSortValue(field string, value interface{}) (interface{}, error) {
+	switch field {
+	case "transactionAt", "createdAt":
+		s, ok := value.(string)
+		if !ok {
+			return nil, errors.New("invalid cursor value")
+		}
+		return time.Parse(time.RFC3339Nano, s)
+	case "amount":
+		f, ok := value.(float64)
+		if !ok {
+			return nil, errors.New("invalid cursor value")
+		}
+		return f, nil
+	default:
+		s, ok := value.(string)
+		if !ok {
+			return nil, errors.New("invalid cursor value")
+		}
+		return s, nil
+	}
+}
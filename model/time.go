@@ -1,13 +1,14 @@
 package model
 
 import (
-	"os"
 	"strings"
 	"time"
 )
 
 const LayoutYYYYMMDDHHMM = "2006-01-02 15:04"
 
+// JSONTime is a wall clock time in LayoutYYYYMMDDHHMM without a zone. Readers
+// place it in the app location with In.
 type JSONTime struct {
 	time.Time
 }
@@ -18,39 +19,29 @@ func (jt *JSONTime) UnmarshalJSON(b []byte) error {
 		jt.Time = time.Time{}
 		return nil
 	}
-	loc := getAppLocation()
-	t, err := time.ParseInLocation(LayoutYYYYMMDDHHMM, s, loc)
+	t, err := time.Parse(LayoutYYYYMMDDHHMM, s)
 	if err != nil {
 		return err
 	}
-	jt.Time = t.In(loc)
+	jt.Time = t
 	return nil
 }
 
+// MarshalJSON writes the wall clock time as is; convert to the wanted zone
+// before building the JSONTime.
 func (jt JSONTime) MarshalJSON() ([]byte, error) {
 	if jt.Time.IsZero() {
 		return []byte("null"), nil
 	}
-	loc := getAppLocation()
-	return []byte("\"" + jt.Time.In(loc).Format(LayoutYYYYMMDDHHMM) + "\""), nil
+	return []byte("\"" + jt.Time.Format(LayoutYYYYMMDDHHMM) + "\""), nil
 }
 
-func (jt JSONTime) ToTime() time.Time {
-	return jt.Time.In(getAppLocation())
+// In reads the wall clock time in loc.
+func (jt JSONTime) In(loc *time.Location) time.Time {
+	t := jt.Time
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), loc)
 }
 
 func (jt JSONTime) IsZero() bool {
 	return jt.Time.IsZero()
 }
-
-func getAppLocation() *time.Location {
-	tz := os.Getenv("APP_TIMEZONE")
-	if tz == "" {
-		tz = "America/Sao_Paulo"
-	}
-	loc, err := time.LoadLocation(tz)
-	if err != nil {
-		return time.Local
-	}
-	return loc
-}
@@ -8,12 +8,16 @@ import (
 )
 
 type User struct {
-	ID        uuid.UUID `gorm:"type:uuid;primaryKey" json:"id"`
-	Name      string    `gorm:"not null" json:"name"`
-	Email     string    `gorm:"unique;not null" json:"email"`
-	Password  string    `gorm:"not null" json:"-"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID               uuid.UUID  `gorm:"type:uuid;primaryKey" json:"id"`
+	Name             string     `gorm:"not null" json:"name"`
+	Email            string     `gorm:"unique;not null" json:"email"`
+	Password         string     `gorm:"not null" json:"-"`
+	EmailVerifiedAt  *time.Time `json:"email_verified_at"`
+	TOTPSecret       string     `gorm:"column:totp_secret" json:"-"`
+	TOTPLastStep     int64      `gorm:"column:totp_last_step;not null;default:0" json:"-"`
+	TwoFactorEnabled bool       `gorm:"not null;default:false" json:"two_factor_enabled"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
 }
 
 func (u *User) BeforeCreate(tx *gorm.DB) (err error) {
@@ -31,3 +35,27 @@ type LoginUserInput struct {
 	Email    string `json:"email" binding:"required,email"`
 	Password string `json:"password" binding:"required"`
 }
+
+type UpdateProfileInput struct {
+	Name  *string `json:"name" binding:"omitempty,min=1"`
+	Email *string `json:"email" binding:"omitempty,email"`
+}
+
+type ChangePasswordInput struct {
+	CurrentPassword string `json:"currentPassword" binding:"required"`
+	NewPassword     string `json:"newPassword" binding:"required,min=6"`
+}
+
+type RequestAccountDeletionInput struct {
+	Password string `json:"password" binding:"required"`
+}
+
+type DeleteAccountInput struct {
+	Token string `json:"token" binding:"required"`
+}
+
+type LoginResult struct {
+	Tokens            *AuthTokens
+	TwoFactorRequired bool
+	PreAuthToken      string
+}
@@ -0,0 +1,51 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type UserTokenPurpose string
+
+const (
+	EmailVerificationPurpose UserTokenPurpose = "EMAIL_VERIFICATION"
+	PasswordResetPurpose     UserTokenPurpose = "PASSWORD_RESET"
+	AccountDeletionPurpose   UserTokenPurpose = "ACCOUNT_DELETION"
+)
+
+// UserToken is a single-use emailed token (verification, password reset,
+// account deletion). Only its hash is stored.
+type UserToken struct {
+	ID        uuid.UUID        `gorm:"type:uuid;primaryKey" json:"id"`
+	UserID    uuid.UUID        `gorm:"type:uuid;index;not null" json:"userId"`
+	User      User             `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"-"`
+	Purpose   UserTokenPurpose `gorm:"type:varchar(30);not null" json:"purpose"`
+	TokenHash string           `gorm:"uniqueIndex;not null" json:"-"`
+	ExpiresAt time.Time        `gorm:"not null" json:"expiresAt"`
+	UsedAt    *time.Time       `json:"usedAt"`
+	CreatedAt time.Time        `json:"createdAt"`
+}
+
+func (t *UserToken) BeforeCreate(tx *gorm.DB) (err error) {
+	t.ID = uuid.New()
+	return
+}
+
+type ForgotPasswordInput struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+type ResetPasswordInput struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required,min=6"`
+}
+
+type VerifyEmailInput struct {
+	Token string `json:"token" binding:"required"`
+}
+
+type ResendVerificationInput struct {
+	Email string `json:"email" binding:"required,email"`
+}
@@ -0,0 +1,199 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestCodeChallengeS256(t *testing.T) {
+	// BASE64URL(SHA256(verifier)) without padding, per RFC 7636.
+	got := CodeChallengeS256("dBjftJeZ4CVP-mJ92IhjhNrcC8Lh0t9Rv6jrhTZSnO8")
+	if want := "XLGDjbLvRpyr6UeQ6RGh38SaXdisDN4v5ZI3-8XlRw0"; got != want {
+		t.Fatalf("CodeChallengeS256 = %q, want %q", got, want)
+	}
+}
+
+// mockIssuer is a local OIDC issuer: it serves discovery and JWKS and only
+// exchanges the code when the code_verifier matches the authorization's
+// code_challenge.
+type mockIssuer struct {
+	server    *httptest.Server
+	key       *rsa.PrivateKey
+	challenge string
+	nonce     string
+	// idToken lets each test change the claims and the signing method.
+	idToken func(claims jwt.MapClaims) (string, error)
+}
+
+func newMockIssuer(t *testing.T) *mockIssuer {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := &mockIssuer{key: key}
+	m.idToken = m.sign
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 m.server.URL,
+			"authorization_endpoint": m.server.URL + "/authorize",
+			"token_endpoint":         m.server.URL + "/token",
+			"jwks_uri":               m.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{"keys": []map[string]string{{
+			"kid": "k1",
+			"kty": "RSA",
+			"use": "sig",
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		if r.Form.Get("code") != "auth-code" || CodeChallengeS256(r.Form.Get("code_verifier")) != m.challenge {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+			return
+		}
+		idToken, err := m.idToken(jwt.MapClaims{
+			"iss":            m.server.URL,
+			"aud":            "client-id",
+			"sub":            "user-123",
+			"email":          "alice@example.com",
+			"email_verified": true,
+			"nonce":          m.nonce,
+			"exp":            time.Now().Add(time.Minute).Unix(),
+		})
+		if err != nil {
+			t.Error(err)
+		}
+		json.NewEncoder(w).Encode(map[string]string{"id_token": idToken})
+	})
+	m.server = httptest.NewServer(mux)
+	t.Cleanup(m.server.Close)
+	return m
+}
+
+func (m *mockIssuer) sign(claims jwt.MapClaims) (string, error) {
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = "k1"
+	return token.SignedString(m.key)
+}
+
+// authorize starts the flow as a browser would and returns the provider.
+func (m *mockIssuer) authorize(t *testing.T, verifier string) *Provider {
+	t.Helper()
+	provider := NewProvider(Config{Name: "mock", Issuer: m.server.URL, ClientID: "client-id", RedirectURL: "http://localhost:81/auth/oidc/mock/callback"})
+
+	authURL, err := provider.AuthCodeURL(context.Background(), "state-1", "nonce-1", CodeChallengeS256(verifier))
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q := u.Query()
+	if !strings.HasPrefix(authURL, m.server.URL+"/authorize?") || q.Get("state") != "state-1" || q.Get("code_challenge_method") != "S256" {
+		t.Fatalf("authorization url = %s", authURL)
+	}
+	if q.Get("scope") != "openid email profile" {
+		t.Errorf("scope = %q, want the default scopes", q.Get("scope"))
+	}
+	m.challenge, m.nonce = q.Get("code_challenge"), q.Get("nonce")
+	return provider
+}
+
+func TestExchange(t *testing.T) {
+	const verifier = "verifier-0123456789-0123456789-0123456789"
+
+	tests := []struct {
+		name     string
+		verifier string
+		nonce    string
+		idToken  func(m *mockIssuer) func(jwt.MapClaims) (string, error)
+		wantErr  string
+	}{
+		{name: "valid", verifier: verifier, nonce: "nonce-1"},
+		{name: "wrong code verifier", verifier: "another-verifier", nonce: "nonce-1", wantErr: "invalid_grant"},
+		{name: "nonce mismatch", verifier: verifier, nonce: "nonce-2", wantErr: "nonce mismatch"},
+		{
+			name: "wrong audience", verifier: verifier, nonce: "nonce-1", wantErr: "invalid id token",
+			idToken: func(m *mockIssuer) func(jwt.MapClaims) (string, error) {
+				return func(claims jwt.MapClaims) (string, error) {
+					claims["aud"] = "another-client"
+					return m.sign(claims)
+				}
+			},
+		},
+		{
+			name: "expired", verifier: verifier, nonce: "nonce-1", wantErr: "invalid id token",
+			idToken: func(m *mockIssuer) func(jwt.MapClaims) (string, error) {
+				return func(claims jwt.MapClaims) (string, error) {
+					claims["exp"] = time.Now().Add(-time.Minute).Unix()
+					return m.sign(claims)
+				}
+			},
+		},
+		{
+			name: "hmac signed", verifier: verifier, nonce: "nonce-1", wantErr: "invalid id token",
+			idToken: func(m *mockIssuer) func(jwt.MapClaims) (string, error) {
+				return func(claims jwt.MapClaims) (string, error) {
+					token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+					token.Header["kid"] = "k1"
+					return token.SignedString([]byte("client-secret"))
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newMockIssuer(t)
+			if tt.idToken != nil {
+				m.idToken = tt.idToken(m)
+			}
+			provider := m.authorize(t, verifier)
+
+			claims, err := provider.Exchange(context.Background(), "auth-code", tt.verifier, tt.nonce)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Exchange = %+v, %v, want error with %q", claims, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if claims.Subject != "user-123" || claims.Email != "alice@example.com" || !claims.EmailVerified {
+				t.Fatalf("claims = %+v", claims)
+			}
+		})
+	}
+}
+
+func TestRegistryLooksUpProvidersCaseInsensitively(t *testing.T) {
+	registry := NewRegistry([]Config{{Name: "Google", Issuer: "https://accounts.google.com", ClientID: "id"}})
+
+	if p, ok := registry.Get("GOOGLE"); !ok || p.Name() != "google" {
+		t.Fatalf("Get(GOOGLE) = %v, %v", p, ok)
+	}
+	if _, ok := registry.Get("github"); ok {
+		t.Fatal("Get(github) found a provider that is not configured")
+	}
+}
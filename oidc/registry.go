@@ -0,0 +1,32 @@
+package oidc
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"strings"
+)
+
+// Registry holds the configured providers by lowercase name.
+type Registry struct {
+	providers map[string]*Provider
+}
+
+func NewRegistry(configs []Config) *Registry {
+	providers := make(map[string]*Provider, len(configs))
+	for _, config := range configs {
+		config.Name = strings.ToLower(config.Name)
+		providers[config.Name] = NewProvider(config)
+	}
+	return &Registry{providers: providers}
+}
+
+func (r *Registry) Get(name string) (*Provider, bool) {
+	p, ok := r.providers[strings.ToLower(name)]
+	return p, ok
+}
+
+// CodeChallengeS256 derives the PKCE code_challenge from the code_verifier.
+func CodeChallengeS256(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
@@ -0,0 +1,98 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Policy is a token bucket: bursts of up to Capacity requests, refilled
+// continuously at RefillPerSecond.
+type Policy struct {
+	Name            string
+	Capacity        int
+	RefillPerSecond float64
+}
+
+// ParsePolicy reads "<n>/<unit>" policies ("10/min", "2/s", "1000/h").
+func ParsePolicy(name, value string) (Policy, error) {
+	parts := strings.Split(strings.TrimSpace(value), "/")
+	if len(parts) != 2 {
+		return Policy{}, fmt.Errorf("invalid rate limit %q, expected <n>/<s|min|h>", value)
+	}
+	n, err := strconv.Atoi(parts[0])
+	if err != nil || n <= 0 {
+		return Policy{}, fmt.Errorf("invalid rate limit %q", value)
+	}
+
+	var per time.Duration
+	switch parts[1] {
+	case "s", "sec", "second":
+		per = time.Second
+	case "m", "min", "minute":
+		per = time.Minute
+	case "h", "hour":
+		per = time.Hour
+	default:
+		return Policy{}, fmt.Errorf("invalid rate limit unit %q", parts[1])
+	}
+
+	return Policy{Name: name, Capacity: n, RefillPerSecond: float64(n) / per.Seconds()}, nil
+}
+
+// fillTime is how long an empty bucket takes to refill.
+func (p Policy) fillTime() time.Duration {
+	return secondsToDuration(float64(p.Capacity) / p.RefillPerSecond)
+}
+
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// RetryAfter is the wait for the next token (only when denied).
+	RetryAfter time.Duration
+	// ResetAfter is the wait until the bucket is full again.
+	ResetAfter time.Duration
+}
+
+// Store keeps the buckets. Take must be atomic per key.
+type Store interface {
+	// Take consumes a token if available and returns the remaining balance.
+	Take(ctx context.Context, key string, policy Policy) (tokens float64, allowed bool, err error)
+}
+
+type Limiter struct {
+	store Store
+}
+
+func NewLimiter(store Store) *Limiter {
+	return &Limiter{store: store}
+}
+
+func (l *Limiter) Allow(ctx context.Context, key string, policy Policy) (Result, error) {
+	tokens, allowed, err := l.store.Take(ctx, policy.Name+":"+key, policy)
+	if err != nil {
+		return Result{}, err
+	}
+
+	result := Result{
+		Allowed:    allowed,
+		Limit:      policy.Capacity,
+		Remaining:  int(math.Floor(tokens)),
+		ResetAfter: secondsToDuration((float64(policy.Capacity) - tokens) / policy.RefillPerSecond),
+	}
+	if !allowed {
+		result.RetryAfter = secondsToDuration((1 - tokens) / policy.RefillPerSecond)
+	}
+	return result, nil
+}
+
+func secondsToDuration(s float64) time.Duration {
+	if s <= 0 {
+		return 0
+	}
+	return time.Duration(s * float64(time.Second))
+}
@@ -0,0 +1,116 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func newTestStore(now *time.Time) *MemoryStore {
+	s := NewMemoryStore()
+	s.now = func() time.Time { return *now }
+	return s
+}
+
+func TestLimiterAllow(t *testing.T) {
+	policy := Policy{Name: "test", Capacity: 3, RefillPerSecond: 1}
+
+	type step struct {
+		after         time.Duration
+		wantAllowed   bool
+		wantRemaining int
+		wantRetry     time.Duration
+		wantReset     time.Duration
+	}
+	tests := []struct {
+		name  string
+		steps []step
+	}{
+		{name: "burst up to capacity", steps: []step{
+			{wantAllowed: true, wantRemaining: 2, wantReset: time.Second},
+			{wantAllowed: true, wantRemaining: 1, wantReset: 2 * time.Second},
+			{wantAllowed: true, wantRemaining: 0, wantReset: 3 * time.Second},
+			{wantAllowed: false, wantRemaining: 0, wantRetry: time.Second, wantReset: 3 * time.Second},
+		}},
+		{name: "partial refill", steps: []step{
+			{wantAllowed: true, wantRemaining: 2, wantReset: time.Second},
+			{wantAllowed: true, wantRemaining: 1, wantReset: 2 * time.Second},
+			{wantAllowed: true, wantRemaining: 0, wantReset: 3 * time.Second},
+			{after: 500 * time.Millisecond, wantAllowed: false, wantRemaining: 0, wantRetry: 500 * time.Millisecond, wantReset: 2500 * time.Millisecond},
+			{after: 500 * time.Millisecond, wantAllowed: true, wantRemaining: 0, wantReset: 3 * time.Second},
+		}},
+		{name: "refill is capped at capacity", steps: []step{
+			{wantAllowed: true, wantRemaining: 2, wantReset: time.Second},
+			{after: time.Hour, wantAllowed: true, wantRemaining: 2, wantReset: time.Second},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := time.Date(2025, 10, 15, 12, 0, 0, 0, time.UTC)
+			limiter := NewLimiter(newTestStore(&now))
+
+			for i, st := range tt.steps {
+				now = now.Add(st.after)
+				got, err := limiter.Allow(context.Background(), "203.0.113.7", policy)
+				if err != nil {
+					t.Fatal(err)
+				}
+				want := Result{Allowed: st.wantAllowed, Limit: 3, Remaining: st.wantRemaining, RetryAfter: st.wantRetry, ResetAfter: st.wantReset}
+				if got != want {
+					t.Fatalf("step %d: Allow = %+v, want %+v", i+1, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestParsePolicy(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    Policy
+		wantErr bool
+	}{
+		{value: "10/min", want: Policy{Name: "p", Capacity: 10, RefillPerSecond: 10.0 / 60}},
+		{value: "2/s", want: Policy{Name: "p", Capacity: 2, RefillPerSecond: 2}},
+		{value: "3600/h", want: Policy{Name: "p", Capacity: 3600, RefillPerSecond: 1}},
+		{value: "0/min", wantErr: true},
+		{value: "10/day", wantErr: true},
+		{value: "10", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParsePolicy("p", tt.value)
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Fatalf("ParsePolicy(%q) = %+v, %v, want %+v", tt.value, got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestMemoryStoreEvictsByBucketPolicy(t *testing.T) {
+	now := time.Date(2025, 10, 15, 12, 0, 0, 0, time.UTC)
+	store := newTestStore(&now)
+	store.maxBuckets = 2
+	auth := Policy{Name: "auth", Capacity: 1, RefillPerSecond: 1.0 / 60}
+	api := Policy{Name: "api", Capacity: 2, RefillPerSecond: 2}
+	ctx := context.Background()
+
+	if _, allowed, _ := store.Take(ctx, "auth:alice", auth); !allowed {
+		t.Fatal("first login attempt denied")
+	}
+	store.Take(ctx, "api:bob", api)
+
+	// Both api buckets would be full by now, but the auth bucket still drains.
+	now = now.Add(10 * time.Second)
+	store.Take(ctx, "api:carol", api)
+
+	if _, ok := store.buckets["auth:alice"]; !ok {
+		t.Fatal("auth bucket evicted with the api fill time")
+	}
+	if _, ok := store.buckets["api:bob"]; ok {
+		t.Error("full api bucket kept")
+	}
+	if _, allowed, _ := store.Take(ctx, "auth:alice", auth); allowed {
+		t.Fatal("throttled login key was reset to full capacity")
+	}
+}
@@ -0,0 +1,66 @@
+package ratelimit
+
+import (
+	"context"
+	"financial-track/model"
+	"log"
+	"math/rand"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// PostgresStore shares buckets between replicas. The refill is computed in the
+// UPSERT itself with the database clock.
+type PostgresStore struct {
+	db *gorm.DB
+}
+
+func NewPostgresStore(db *gorm.DB) *PostgresStore {
+	return &PostgresStore{db: db}
+}
+
+func (s *PostgresStore) Take(ctx context.Context, key string, policy Policy) (float64, bool, error) {
+	var row struct {
+		Tokens  float64
+		Allowed bool
+	}
+	err := s.db.WithContext(ctx).Raw(`
+		INSERT INTO rate_limit_buckets AS b (key, tokens, allowed, updated_at)
+		VALUES (@key, @capacity - 1, TRUE, now())
+		ON CONFLICT (key) DO UPDATE SET
+			tokens = CASE
+				WHEN LEAST(@capacity, b.tokens + EXTRACT(EPOCH FROM now() - b.updated_at) * @rate) >= 1
+				THEN LEAST(@capacity, b.tokens + EXTRACT(EPOCH FROM now() - b.updated_at) * @rate) - 1
+				ELSE LEAST(@capacity, b.tokens + EXTRACT(EPOCH FROM now() - b.updated_at) * @rate)
+			END,
+			allowed = LEAST(@capacity, b.tokens + EXTRACT(EPOCH FROM now() - b.updated_at) * @rate) >= 1,
+			updated_at = now()
+		RETURNING tokens, allowed`,
+		map[string]interface{}{
+			"key":      key,
+			"capacity": float64(policy.Capacity),
+			"rate":     policy.RefillPerSecond,
+		},
+	).Scan(&row).Error
+	if err != nil {
+		return 0, false, err
+	}
+
+	// Opportunistic cleanup of idle buckets, which equal full ones.
+	if rand.Intn(1000) == 0 {
+		go s.deleteIdle(time.Hour)
+	}
+
+	return row.Tokens, row.Allowed, nil
+}
+
+// deleteIdle runs in the background, outside the request context.
+func (s *PostgresStore) deleteIdle(idle time.Duration) {
+	err := s.db.
+		Where("updated_at < ?", time.Now().Add(-idle)).
+		Delete(&model.RateLimitBucket{}).Error
+	if err != nil {
+		log.Println("⚠️ Failed to clean rate limit buckets:", err)
+	}
+}
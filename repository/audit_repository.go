@@ -0,0 +1,135 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"financial-track/model"
+	"reflect"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type AuditRepository struct {
+	db *gorm.DB
+}
+
+func NewAuditRepository(db *gorm.DB) *AuditRepository {
+	return &AuditRepository{db: db}
+}
+
+// ListByUser returns the user's audit trail, newest first.
+func (r *AuditRepository) ListByUser(ctx context.Context, userID uuid.UUID, entityType string, page, pageSize int) (model.PagedAuditLog, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize <= 0 {
+		pageSize = 15
+	}
+
+	query := r.db.WithContext(ctx).Model(&model.AuditLog{}).Where("user_id = ?", userID)
+	if entityType != "" {
+		query = query.Where("entity_type = ?", entityType)
+	}
+
+	var totalItems int64
+	if err := query.Count(&totalItems).Error; err != nil {
+		return model.PagedAuditLog{}, err
+	}
+
+	logs := []model.AuditLog{}
+	if err := query.
+		Order("created_at DESC").
+		Limit(pageSize).
+		Offset((page - 1) * pageSize).
+		Find(&logs).Error; err != nil {
+		return model.PagedAuditLog{}, err
+	}
+
+	return model.PagedAuditLog{
+		Data:        logs,
+		CurrentPage: page,
+		LastPage:    int((totalItems + int64(pageSize) - 1) / int64(pageSize)),
+		TotalItems:  totalItems,
+		PerPage:     pageSize,
+	}, nil
+}
+
+// writeAudit writes the entry in the change's transaction. Updates keep only the
+// changed fields in before/after and write nothing when nothing changed.
+func writeAudit(tx *gorm.DB, actor model.AuditActor, ownerID uuid.UUID, entityType, entityID string, action model.AuditAction, before, after interface{}) error {
+	beforeMap, err := auditSnapshot(before)
+	if err != nil {
+		return err
+	}
+	afterMap, err := auditSnapshot(after)
+	if err != nil {
+		return err
+	}
+
+	if action == model.AuditUpdate {
+		if !diffAudit(beforeMap, afterMap) {
+			return nil
+		}
+	}
+
+	entry := model.AuditLog{
+		UserID:     ownerID,
+		ActorID:    actor.UserID,
+		EntityType: entityType,
+		EntityID:   entityID,
+		Action:     action,
+		IP:         actor.IP,
+		RequestID:  actor.RequestID,
+	}
+	if entry.Before, err = marshalAudit(beforeMap); err != nil {
+		return err
+	}
+	if entry.After, err = marshalAudit(afterMap); err != nil {
+		return err
+	}
+	return tx.Create(&entry).Error
+}
+
+// Timestamps that change on every update; users serialize them in snake_case.
+var auditIgnoredKeys = []string{"updatedAt", "updated_at"}
+
+// diffAudit keeps only the changed keys and reports whether anything changed.
+func diffAudit(before, after map[string]interface{}) bool {
+	for key, value := range after {
+		if reflect.DeepEqual(before[key], value) {
+			delete(before, key)
+			delete(after, key)
+		}
+	}
+	for _, key := range auditIgnoredKeys {
+		delete(before, key)
+		delete(after, key)
+	}
+	return len(before) > 0 || len(after) > 0
+}
+
+// auditSnapshot uses the entity's JSON form, which already omits secrets
+// (password, TOTP secret).
+func auditSnapshot(entity interface{}) (map[string]interface{}, error) {
+	if entity == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(entity)
+	if err != nil {
+		return nil, err
+	}
+	var snapshot map[string]interface{}
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+func marshalAudit(snapshot map[string]interface{}) (model.RawJSON, error) {
+	if snapshot == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(snapshot)
+	return model.RawJSON(data), err
+}
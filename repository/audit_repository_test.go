@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"financial-track/model"
+
+	"github.com/google/uuid"
+)
+
+func TestDiffAuditIgnoresTimestamps(t *testing.T) {
+	before := model.User{ID: uuid.New(), Name: "Alice", Email: "alice@example.com", UpdatedAt: time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC)}
+
+	tests := []struct {
+		name        string
+		change      func(*model.User)
+		wantChanged []string
+	}{
+		{name: "only updated_at", change: func(u *model.User) { u.UpdatedAt = u.UpdatedAt.Add(time.Hour) }},
+		{name: "name", change: func(u *model.User) {
+			u.Name = "Alice Doe"
+			u.UpdatedAt = u.UpdatedAt.Add(time.Hour)
+		}, wantChanged: []string{"name"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			after := before
+			tt.change(&after)
+			beforeMap, err := auditSnapshot(before)
+			if err != nil {
+				t.Fatal(err)
+			}
+			afterMap, err := auditSnapshot(after)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			changed := diffAudit(beforeMap, afterMap)
+			if changed != (len(tt.wantChanged) > 0) || len(afterMap) != len(tt.wantChanged) {
+				t.Fatalf("changed = %v, diff = %v, want %v", changed, afterMap, tt.wantChanged)
+			}
+			for _, key := range tt.wantChanged {
+				if _, ok := afterMap[key]; !ok {
+					t.Errorf("diff %v misses %q", afterMap, key)
+				}
+			}
+		})
+	}
+}
@@ -1,33 +1,68 @@
 package repository
 
 import (
-	"financial-track/database"
+	"context"
+	"encoding/json"
+	"errors"
 	"financial-track/model"
+	"financial-track/usecase"
+	"strings"
 	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
-type ExpenseRepository struct{}
+type ExpenseRepository struct {
+	db *gorm.DB
+}
 
-func NewExpenseRepository() *ExpenseRepository {
-	return &ExpenseRepository{}
+func NewExpenseRepository(db *gorm.DB) *ExpenseRepository {
+	return &ExpenseRepository{db: db}
 }
 
-func (r *ExpenseRepository) Create(expense *model.Expense) error {
-	return database.DB.Create(expense).Error
+func (r *ExpenseRepository) Create(ctx context.Context, expense *model.Expense, actor model.AuditActor) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(expense).Error; err != nil {
+			return err
+		}
+		return writeAudit(tx, actor, expense.UserID, model.AuditEntityExpense, expense.ID.String(), model.AuditCreate, nil, expense.Response())
+	})
 }
 
-func (r *ExpenseRepository) GetSummary(startDate, endDate time.Time, page, pageSize int) (model.PagedSummary, error) {
-	var summary model.Summary
+// CreateBatch inserts all expenses in one transaction, all or nothing.
+func (r *ExpenseRepository) CreateBatch(ctx context.Context, expenses []model.Expense, actor model.AuditActor) error {
+	if len(expenses) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.CreateInBatches(&expenses, 100).Error; err != nil {
+			return err
+		}
+		for _, expense := range expenses {
+			if err := writeAudit(tx, actor, expense.UserID, model.AuditEntityExpense, expense.ID.String(), model.AuditCreate, nil, expense.Response()); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// GetSummary totals and pages the user's expenses in the period.
+func (r *ExpenseRepository) GetSummary(ctx context.Context, userID uuid.UUID, startDate, endDate time.Time, page, pageSize int, sort []model.SortKey) (model.PagedSummary, error) {
+	inPeriod := func() *gorm.DB {
+		return r.db.WithContext(ctx).Model(&model.Expense{}).
+			Where("user_id = ? AND transaction_at BETWEEN ? AND ?", userID, startDate, endDate)
+	}
 
-	if err := database.DB.Model(&model.Expense{}).
-		Where("transaction_at BETWEEN ? AND ?", startDate, endDate).
-		Select("COALESCE(SUM(amount),0)").Scan(&summary.TotalAmount).Error; err != nil {
+	var summary model.Summary
+	if err := inPeriod().Select("COALESCE(SUM(amount),0)").Scan(&summary.TotalAmount).Error; err != nil {
+		return model.PagedSummary{}, err
 	}
 
 	var totalItems int64
-	if err := database.DB.Model(&model.Expense{}).
-		Where("transaction_at BETWEEN ? AND ?", startDate, endDate).
-		Count(&totalItems).Error; err != nil {
+	if err := inPeriod().Count(&totalItems).Error; err != nil {
 		return model.PagedSummary{}, err
 	}
 
@@ -40,32 +75,19 @@ func (r *ExpenseRepository) GetSummary(startDate, endDate time.Time, page, pageS
 	offset := (page - 1) * pageSize
 
 	var expensesDB []model.Expense
-	if err := database.DB.
-		Where("transaction_at BETWEEN ? AND ?", startDate, endDate).
-		Order("transaction_at DESC").
+	if err := inPeriod().
+		Order(model.OrderClause(sort, false)).
 		Limit(pageSize).
 		Offset(offset).
 		Find(&expensesDB).Error; err != nil {
 		return model.PagedSummary{}, err
 	}
 
-	totalPages := 0
-	if pageSize > 0 {
-		totalPages = int((totalItems + int64(pageSize) - 1) / int64(pageSize))
-	}
+	totalPages := int((totalItems + int64(pageSize) - 1) / int64(pageSize))
 
 	expenses := make([]model.ExpenseResponse, 0, len(expensesDB))
 	for _, e := range expensesDB {
-		expenses = append(expenses, model.ExpenseResponse{
-			ID:            e.ID,
-			UserID:        e.UserID,
-			Category:      e.Category,
-			Amount:        e.Amount,
-			Description:   e.Description,
-			TransactionAt: e.TransactionAt,
-			CreatedAt:     e.CreatedAt,
-			UpdatedAt:     e.UpdatedAt,
-		})
+		expenses = append(expenses, e.Response())
 	}
 
 	return model.PagedSummary{
@@ -77,3 +99,358 @@ func (r *ExpenseRepository) GetSummary(startDate, endDate time.Time, page, pageS
 		PerPage:     pageSize,
 	}, nil
 }
+
+func (r *ExpenseRepository) FindByID(ctx context.Context, userID, id uuid.UUID) (*model.Expense, error) {
+	var expense model.Expense
+	if err := r.db.WithContext(ctx).Where("id = ? AND user_id = ?", id, userID).First(&expense).Error; err != nil {
+		return nil, err
+	}
+	return &expense, nil
+}
+
+// Update applies change if the current version satisfies match and bumps the
+// version. The row stays locked during the check.
+func (r *ExpenseRepository) Update(ctx context.Context, userID, id uuid.UUID, match model.VersionMatch, change func(*model.Expense) map[string]interface{}, actor model.AuditActor) (*model.Expense, error) {
+	var expense model.Expense
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND user_id = ?", id, userID).
+			First(&expense).Error; err != nil {
+			return err
+		}
+		if !match.Matches(expense.Version) {
+			return usecase.ErrVersionMismatch
+		}
+
+		before := expense.Response()
+		fields := change(&expense)
+		if len(fields) == 0 {
+			return nil
+		}
+		fields["version"] = gorm.Expr("version + 1")
+		if err := tx.Model(&expense).Updates(fields).Error; err != nil {
+			return err
+		}
+		expense.Version++
+		return writeAudit(tx, actor, userID, model.AuditEntityExpense, id.String(), model.AuditUpdate, before, expense.Response())
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &expense, nil
+}
+
+// SoftDelete moves the expense to the trash if the current version satisfies
+// match.
+func (r *ExpenseRepository) SoftDelete(ctx context.Context, userID, id uuid.UUID, match model.VersionMatch, actor model.AuditActor) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var expense model.Expense
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND user_id = ?", id, userID).
+			First(&expense).Error; err != nil {
+			return err
+		}
+		if !match.Matches(expense.Version) {
+			return usecase.ErrVersionMismatch
+		}
+		if err := tx.Delete(&expense).Error; err != nil {
+			return err
+		}
+		return writeAudit(tx, actor, userID, model.AuditEntityExpense, id.String(), model.AuditDelete, expense.Response(), nil)
+	})
+}
+
+// Restore takes the expense out of the trash.
+func (r *ExpenseRepository) Restore(ctx context.Context, userID, id uuid.UUID, actor model.AuditActor) (*model.Expense, error) {
+	var expense model.Expense
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().
+			Where("id = ? AND user_id = ? AND deleted_at IS NOT NULL", id, userID).
+			First(&expense).Error; err != nil {
+			return err
+		}
+		before := expense.Response()
+		if err := tx.Unscoped().Model(&expense).Updates(map[string]interface{}{
+			"deleted_at": nil,
+			"version":    gorm.Expr("version + 1"),
+		}).Error; err != nil {
+			return err
+		}
+		expense.DeletedAt = gorm.DeletedAt{}
+		expense.Version++
+		return writeAudit(tx, actor, userID, model.AuditEntityExpense, id.String(), model.AuditRestore, before, expense.Response())
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &expense, nil
+}
+
+// Purge permanently deletes a trashed expense.
+func (r *ExpenseRepository) Purge(ctx context.Context, userID, id uuid.UUID, actor model.AuditActor) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var expense model.Expense
+		if err := tx.Unscoped().
+			Where("id = ? AND user_id = ? AND deleted_at IS NOT NULL", id, userID).
+			First(&expense).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Delete(&expense).Error; err != nil {
+			return err
+		}
+		return writeAudit(tx, actor, userID, model.AuditEntityExpense, id.String(), model.AuditPurge, expense.Response(), nil)
+	})
+}
+
+// PurgeTrashedBefore permanently deletes, in batches, expenses trashed before
+// cutoff and returns how many it removed.
+func (r *ExpenseRepository) PurgeTrashedBefore(ctx context.Context, cutoff time.Time, batchSize int) (int64, error) {
+	var total int64
+	for {
+		var purged int
+		err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			var expenses []model.Expense
+			if err := tx.Unscoped().
+				Where("deleted_at IS NOT NULL AND deleted_at < ?", cutoff).
+				Limit(batchSize).
+				Find(&expenses).Error; err != nil {
+				return err
+			}
+			if len(expenses) == 0 {
+				return nil
+			}
+
+			ids := make([]uuid.UUID, 0, len(expenses))
+			for _, e := range expenses {
+				ids = append(ids, e.ID)
+				if err := writeAudit(tx, model.AuditActor{}, e.UserID, model.AuditEntityExpense, e.ID.String(), model.AuditPurge, e.Response(), nil); err != nil {
+					return err
+				}
+			}
+			if err := tx.Unscoped().Where("id IN ?", ids).Delete(&model.Expense{}).Error; err != nil {
+				return err
+			}
+			purged = len(expenses)
+			return nil
+		})
+		if err != nil {
+			return total, err
+		}
+		total += int64(purged)
+		if purged < batchSize {
+			return total, nil
+		}
+	}
+}
+
+func (r *ExpenseRepository) ListTrash(ctx context.Context, userID uuid.UUID, page, pageSize int) (model.PagedSummary, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize <= 0 {
+		pageSize = 15
+	}
+
+	trash := r.db.WithContext(ctx).Unscoped().Model(&model.Expense{}).
+		Where("user_id = ? AND deleted_at IS NOT NULL", userID)
+
+	var totalAmount float64
+	if err := trash.Session(&gorm.Session{}).
+		Select("COALESCE(SUM(amount),0)").Scan(&totalAmount).Error; err != nil {
+		return model.PagedSummary{}, err
+	}
+
+	var totalItems int64
+	if err := trash.Session(&gorm.Session{}).Count(&totalItems).Error; err != nil {
+		return model.PagedSummary{}, err
+	}
+
+	var expensesDB []model.Expense
+	if err := trash.Session(&gorm.Session{}).
+		Order("deleted_at DESC").
+		Limit(pageSize).
+		Offset((page - 1) * pageSize).
+		Find(&expensesDB).Error; err != nil {
+		return model.PagedSummary{}, err
+	}
+
+	expenses := make([]model.ExpenseResponse, 0, len(expensesDB))
+	for _, e := range expensesDB {
+		expenses = append(expenses, e.Response())
+	}
+
+	return model.PagedSummary{
+		Amount:      totalAmount,
+		Data:        expenses,
+		CurrentPage: page,
+		LastPage:    int((totalItems + int64(pageSize) - 1) / int64(pageSize)),
+		TotalItems:  totalItems,
+		PerPage:     pageSize,
+	}, nil
+}
+
+var errBulkDryRun = errors.New("bulk dry run")
+
+// Bulk applies change, or deletion when change is nil, to the expenses picked by
+// ids or filter in one transaction. A dry run rolls back after building the
+// report.
+func (r *ExpenseRepository) Bulk(
+	ctx context.Context,
+	userID uuid.UUID,
+	ids []uuid.UUID,
+	filter *model.ExpenseFilter,
+	limit int,
+	change func(*model.Expense) map[string]interface{},
+	dryRun bool,
+	actor model.AuditActor,
+) ([]model.BulkItemResult, error) {
+	var results []model.BulkItemResult
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		results = nil
+
+		query := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("user_id = ?", userID)
+		if filter != nil {
+			query = applyExpenseFilter(query, *filter)
+		} else {
+			query = query.Where("id IN ?", ids)
+		}
+
+		var expenses []model.Expense
+		if err := query.Order("transaction_at DESC").Limit(limit + 1).Find(&expenses).Error; err != nil {
+			return err
+		}
+		if len(expenses) > limit {
+			return usecase.ErrBulkTooLarge
+		}
+
+		for i := range expenses {
+			e := &expenses[i]
+			before := e.Response()
+			item := model.BulkItemResult{ID: e.ID.String(), Status: model.BulkItemApplied, Before: &before}
+
+			if change == nil {
+				if err := tx.Delete(e).Error; err != nil {
+					return err
+				}
+				if err := writeAudit(tx, actor, userID, model.AuditEntityExpense, item.ID, model.AuditDelete, before, nil); err != nil {
+					return err
+				}
+			} else {
+				fields := change(e)
+				fields["version"] = gorm.Expr("version + 1")
+				if err := tx.Model(e).Updates(fields).Error; err != nil {
+					return err
+				}
+				e.Version++
+				after := e.Response()
+				item.After = &after
+				if err := writeAudit(tx, actor, userID, model.AuditEntityExpense, item.ID, model.AuditUpdate, before, after); err != nil {
+					return err
+				}
+			}
+			results = append(results, item)
+		}
+
+		if filter == nil {
+			found := make(map[uuid.UUID]bool, len(expenses))
+			for _, e := range expenses {
+				found[e.ID] = true
+			}
+			for _, id := range ids {
+				if !found[id] {
+					results = append(results, model.BulkItemResult{ID: id.String(), Status: model.BulkItemNotFound})
+				}
+			}
+		}
+
+		if dryRun {
+			return errBulkDryRun
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errBulkDryRun) {
+		return nil, err
+	}
+	return results, nil
+}
+
+func applyExpenseFilter(query *gorm.DB, filter model.ExpenseFilter) *gorm.DB {
+	if filter.Category != "" {
+		query = query.Where("category = ?", filter.Category)
+	}
+	if !filter.From.IsZero() {
+		query = query.Where("transaction_at >= ?", filter.From.Time)
+	}
+	if !filter.To.IsZero() {
+		query = query.Where("transaction_at <= ?", filter.To.Time)
+	}
+	if filter.DescriptionContains != "" {
+		query = query.Where("description ILIKE ?", "%"+escapeLike(filter.DescriptionContains)+"%")
+	}
+	if filter.Tag != "" {
+		tag, _ := json.Marshal([]string{strings.ToLower(strings.TrimSpace(filter.Tag))})
+		query = query.Where("tags @> ?::jsonb", string(tag))
+	}
+	return query
+}
+
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+// ListByCursor pages by keyset in sort order, which ends in id to be stable.
+// values are the cursor row's values in sort order. It returns up to limit
+// expenses in display order and whether more remain in that direction.
+func (r *ExpenseRepository) ListByCursor(ctx context.Context, userID uuid.UUID, filter model.ExpenseFilter, sort []model.SortKey, values []interface{}, backward bool, limit int) ([]model.Expense, bool, error) {
+	query := applyExpenseFilter(r.db.WithContext(ctx).Where("user_id = ?", userID), filter)
+	if values != nil {
+		condition, args := keysetCondition(sort, values, backward)
+		query = query.Where(condition, args...)
+	}
+
+	var expenses []model.Expense
+	if err := query.Order(model.OrderClause(sort, backward)).Limit(limit + 1).Find(&expenses).Error; err != nil {
+		return nil, false, err
+	}
+
+	hasMore := len(expenses) > limit
+	if hasMore {
+		expenses = expenses[:limit]
+	}
+	if backward {
+		for i, j := 0, len(expenses)-1; i < j; i, j = i+1, j-1 {
+			expenses[i], expenses[j] = expenses[j], expenses[i]
+		}
+	}
+	return expenses, hasMore, nil
+}
+
+// keysetCondition builds "after the cursor row" for mixed directions:
+// (a > va) OR (a = va AND b < vb) OR ...
+func keysetCondition(sort []model.SortKey, values []interface{}, backward bool) (string, []interface{}) {
+	var clauses []string
+	var args []interface{}
+	for i, key := range sort {
+		var parts []string
+		for j := 0; j < i; j++ {
+			parts = append(parts, sort[j].Column+" = ?")
+			args = append(args, values[j])
+		}
+		op := ">"
+		if key.Desc != backward {
+			op = "<"
+		}
+		parts = append(parts, key.Column+" "+op+" ?")
+		args = append(args, values[i])
+		clauses = append(clauses, "("+strings.Join(parts, " AND ")+")")
+	}
+	return "(" + strings.Join(clauses, " OR ") + ")", args
+}
+
+func (r *ExpenseRepository) Count(ctx context.Context, userID uuid.UUID, filter model.ExpenseFilter) (int64, error) {
+	var total int64
+	err := applyExpenseFilter(r.db.WithContext(ctx).Model(&model.Expense{}).Where("user_id = ?", userID), filter).
+		Count(&total).Error
+	return total, err
+}
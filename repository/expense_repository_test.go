@@ -0,0 +1,213 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"financial-track/model"
+
+	"github.com/google/uuid"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// recordingPool is a gorm connection pool that executes nothing (the DB runs in
+// DryRun mode) and records how its transaction ended.
+type recordingPool struct {
+	tx *recordingTx
+}
+
+func (p *recordingPool) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	return nil, errors.New("not supported")
+}
+
+func (p *recordingPool) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return nil, errors.New("not supported")
+}
+
+func (p *recordingPool) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return nil, errors.New("not supported")
+}
+
+func (p *recordingPool) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	return nil
+}
+
+func (p *recordingPool) BeginTx(ctx context.Context, opts *sql.TxOptions) (gorm.ConnPool, error) {
+	p.tx = &recordingTx{recordingPool: p}
+	return p.tx, nil
+}
+
+type recordingTx struct {
+	*recordingPool
+	committed, rolledBack bool
+}
+
+func (t *recordingTx) Commit() error {
+	t.committed = true
+	return nil
+}
+
+func (t *recordingTx) Rollback() error {
+	t.rolledBack = true
+	return nil
+}
+
+// sqlRecorder keeps the SQL generated by gorm.
+type sqlRecorder struct {
+	logger.Interface
+	statements []string
+}
+
+func (r *sqlRecorder) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	sql, _ := fc()
+	r.statements = append(r.statements, sql)
+}
+
+func newDryRunDB(t *testing.T) (*gorm.DB, *recordingPool, *sqlRecorder) {
+	t.Helper()
+	pool := &recordingPool{}
+	recorder := &sqlRecorder{Interface: logger.Discard}
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: pool}), &gorm.Config{DryRun: true, Logger: recorder})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db, pool, recorder
+}
+
+func TestBulkLocksRowsAndRollsBackDryRuns(t *testing.T) {
+	category := func(e *model.Expense) map[string]interface{} {
+		return map[string]interface{}{"category": model.Category("FOOD")}
+	}
+
+	tests := []struct {
+		name         string
+		dryRun       bool
+		wantCommit   bool
+		wantRollback bool
+	}{
+		{name: "applies", wantCommit: true},
+		{name: "dry run", dryRun: true, wantRollback: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, pool, recorder := newDryRunDB(t)
+			repo := NewExpenseRepository(db)
+
+			_, err := repo.Bulk(context.Background(), uuid.New(), []uuid.UUID{uuid.New()}, nil, 10, category, tt.dryRun, model.AuditActor{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if pool.tx == nil || pool.tx.committed != tt.wantCommit || pool.tx.rolledBack != tt.wantRollback {
+				t.Fatalf("transaction = %+v, want committed %v, rolled back %v", pool.tx, tt.wantCommit, tt.wantRollback)
+			}
+			if len(recorder.statements) == 0 || !strings.HasSuffix(recorder.statements[0], "FOR UPDATE") {
+				t.Fatalf("statements = %q, want the selection locked FOR UPDATE", recorder.statements)
+			}
+		})
+	}
+}
+
+// findExpense makes single expense lookups return a row with the given version,
+// since DryRun executes nothing.
+func findExpense(t *testing.T, db *gorm.DB, version int) {
+	t.Helper()
+	err := db.Callback().Query().After("gorm:query").Register("test:find_expense", func(tx *gorm.DB) {
+		if dest, ok := tx.Statement.Dest.(*model.Expense); ok {
+			*dest = model.Expense{ID: uuid.New(), UserID: uuid.New(), Version: version}
+			tx.RowsAffected = 1
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTrashStatements(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		run  func(r *ExpenseRepository) error
+		// want holds, per statement, fragments it must contain.
+		want [][]string
+		// trashOnly lookups must not carry gorm's soft delete scope.
+		trashOnly bool
+	}{
+		{
+			name: "soft delete locks a live row and only marks it deleted",
+			run: func(r *ExpenseRepository) error {
+				return r.SoftDelete(ctx, uuid.New(), uuid.New(), model.VersionMatch{Any: true}, model.AuditActor{})
+			},
+			want: [][]string{
+				{`"expenses"."deleted_at" IS NULL`, "FOR UPDATE"},
+				{`UPDATE "expenses" SET "deleted_at"=`},
+				{`INSERT INTO "audit_logs"`, "'DELETE'"},
+			},
+		},
+		{
+			name: "restore only finds trashed rows and bumps the version",
+			run: func(r *ExpenseRepository) error {
+				_, err := r.Restore(ctx, uuid.New(), uuid.New(), model.AuditActor{})
+				return err
+			},
+			want: [][]string{
+				{"deleted_at IS NOT NULL"},
+				{`"deleted_at"=NULL`, `"version"=version + 1`},
+				{`INSERT INTO "audit_logs"`, "'RESTORE'"},
+			},
+			trashOnly: true,
+		},
+		{
+			name: "purge only finds trashed rows and deletes them for good",
+			run: func(r *ExpenseRepository) error {
+				return r.Purge(ctx, uuid.New(), uuid.New(), model.AuditActor{})
+			},
+			want: [][]string{
+				{"deleted_at IS NOT NULL"},
+				{`DELETE FROM "expenses"`},
+				{`INSERT INTO "audit_logs"`, "'PURGE'"},
+			},
+			trashOnly: true,
+		},
+		{
+			name: "retention purge selects old trash in batches",
+			run: func(r *ExpenseRepository) error {
+				_, err := r.PurgeTrashedBefore(ctx, time.Now(), 500)
+				return err
+			},
+			want: [][]string{
+				{"deleted_at IS NOT NULL AND deleted_at <", "LIMIT 500"},
+			},
+			trashOnly: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, _, recorder := newDryRunDB(t)
+			findExpense(t, db, 2)
+
+			if err := tt.run(NewExpenseRepository(db)); err != nil {
+				t.Fatal(err)
+			}
+			if len(recorder.statements) != len(tt.want) {
+				t.Fatalf("statements = %q, want %d", recorder.statements, len(tt.want))
+			}
+			for i, fragments := range tt.want {
+				for _, fragment := range fragments {
+					if !strings.Contains(recorder.statements[i], fragment) {
+						t.Errorf("statement %d = %q, want %q", i, recorder.statements[i], fragment)
+					}
+				}
+			}
+			if tt.trashOnly && strings.Contains(recorder.statements[0], `"expenses"."deleted_at" IS NULL`) {
+				t.Errorf("trash lookup %q is scoped to live rows", recorder.statements[0])
+			}
+		})
+	}
+}
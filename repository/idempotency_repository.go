@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"context"
+	"financial-track/model"
+	"financial-track/usecase"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type IdempotencyRepository struct {
+	db    *gorm.DB
+	clock usecase.Clock
+}
+
+func NewIdempotencyRepository(db *gorm.DB, clock usecase.Clock) *IdempotencyRepository {
+	return &IdempotencyRepository{db: db, clock: clock}
+}
+
+// Reserve stores the key as in progress. It returns false if an unexpired key
+// exists.
+func (r *IdempotencyRepository) Reserve(ctx context.Context, entry *model.IdempotencyKey) (bool, error) {
+	err := r.db.WithContext(ctx).
+		Where("user_id = ? AND key = ? AND expires_at < ?", entry.UserID, entry.Key, r.clock.Now()).
+		Delete(&model.IdempotencyKey{}).Error
+	if err != nil {
+		return false, err
+	}
+
+	result := r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(entry)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (r *IdempotencyRepository) Find(ctx context.Context, userID uuid.UUID, key string) (*model.IdempotencyKey, error) {
+	var entry model.IdempotencyKey
+	err := r.db.WithContext(ctx).Where("user_id = ? AND key = ?", userID, key).First(&entry).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &entry, nil
+}
+
+func (r *IdempotencyRepository) Complete(ctx context.Context, userID uuid.UUID, key string, status int, contentType string, body []byte) error {
+	return r.db.WithContext(ctx).Model(&model.IdempotencyKey{}).
+		Where("user_id = ? AND key = ?", userID, key).
+		Updates(map[string]interface{}{
+			"status_code":   status,
+			"content_type":  contentType,
+			"response_body": body,
+			"completed_at":  r.clock.Now(),
+		}).Error
+}
+
+// Release deletes the key so the request can be retried (after a 5xx).
+func (r *IdempotencyRepository) Release(ctx context.Context, userID uuid.UUID, key string) error {
+	return r.db.WithContext(ctx).Where("user_id = ? AND key = ?", userID, key).Delete(&model.IdempotencyKey{}).Error
+}
+
+func (r *IdempotencyRepository) DeleteExpired(ctx context.Context) (int64, error) {
+	result := r.db.WithContext(ctx).Where("expires_at < ?", r.clock.Now()).Delete(&model.IdempotencyKey{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"context"
+	"financial-track/model"
+	"financial-track/usecase"
+
+	"gorm.io/gorm"
+)
+
+type IdentityRepository struct {
+	db    *gorm.DB
+	clock usecase.Clock
+}
+
+func NewIdentityRepository(db *gorm.DB, clock usecase.Clock) *IdentityRepository {
+	return &IdentityRepository{db: db, clock: clock}
+}
+
+func (r *IdentityRepository) Create(ctx context.Context, identity *model.UserIdentity) error {
+	return r.db.WithContext(ctx).Create(identity).Error
+}
+
+func (r *IdentityRepository) FindByProviderSubject(ctx context.Context, provider, subject string) (*model.UserIdentity, error) {
+	var identity model.UserIdentity
+	err := r.db.WithContext(ctx).Where("provider = ? AND subject = ?", provider, subject).First(&identity).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &identity, nil
+}
+
+func (r *IdentityRepository) ListByUser(ctx context.Context, userID string) ([]model.UserIdentity, error) {
+	var identities []model.UserIdentity
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("created_at").Find(&identities).Error
+	return identities, err
+}
+
+func (r *IdentityRepository) Delete(ctx context.Context, userID string, id string) (bool, error) {
+	res := r.db.WithContext(ctx).Where("id = ? AND user_id = ?", id, userID).Delete(&model.UserIdentity{})
+	return res.RowsAffected == 1, res.Error
+}
+
+// CreateUserWithIdentity creates the user and identity in one transaction.
+func (r *IdentityRepository) CreateUserWithIdentity(ctx context.Context, user *model.User, identity *model.UserIdentity) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(user).Error; err != nil {
+			return err
+		}
+		identity.UserID = user.ID
+		return tx.Create(identity).Error
+	})
+}
+
+func (r *IdentityRepository) CreateState(ctx context.Context, state *model.OIDCState) error {
+	return r.db.WithContext(ctx).Create(state).Error
+}
+
+// ConsumeState deletes and returns the state (single use), or nil if it is
+// missing or expired.
+func (r *IdentityRepository) ConsumeState(ctx context.Context, stateHash string) (*model.OIDCState, error) {
+	var state model.OIDCState
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("state_hash = ?", stateHash).First(&state).Error; err != nil {
+			return err
+		}
+		res := tx.Where("state_hash = ?", stateHash).Delete(&model.OIDCState{})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if r.clock.Now().After(state.ExpiresAt) {
+		return nil, nil
+	}
+	return &state, nil
+}
+
+func (r *IdentityRepository) DeleteExpiredStates(ctx context.Context) error {
+	return r.db.WithContext(ctx).Where("expires_at < ?", r.clock.Now()).Delete(&model.OIDCState{}).Error
+}
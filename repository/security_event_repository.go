@@ -0,0 +1,20 @@
+package repository
+
+import (
+	"context"
+	"financial-track/model"
+
+	"gorm.io/gorm"
+)
+
+type SecurityEventRepository struct {
+	db *gorm.DB
+}
+
+func NewSecurityEventRepository(db *gorm.DB) *SecurityEventRepository {
+	return &SecurityEventRepository{db: db}
+}
+
+func (r *SecurityEventRepository) Create(ctx context.Context, event *model.SecurityEvent) error {
+	return r.db.WithContext(ctx).Create(event).Error
+}
@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"context"
+	"financial-track/model"
+	"financial-track/usecase"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type SessionRepository struct {
+	db    *gorm.DB
+	clock usecase.Clock
+}
+
+func NewSessionRepository(db *gorm.DB, clock usecase.Clock) *SessionRepository {
+	return &SessionRepository{db: db, clock: clock}
+}
+
+func (r *SessionRepository) Create(ctx context.Context, session *model.Session) error {
+	return r.db.WithContext(ctx).Create(session).Error
+}
+
+func (r *SessionRepository) FindByID(ctx context.Context, id string) (*model.Session, error) {
+	var session model.Session
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&session).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &session, nil
+}
+
+func (r *SessionRepository) ListActiveByUser(ctx context.Context, userID string) ([]model.Session, error) {
+	var sessions []model.Session
+	err := r.db.WithContext(ctx).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Order("last_seen_at DESC").
+		Find(&sessions).Error
+	return sessions, err
+}
+
+func (r *SessionRepository) Touch(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Model(&model.Session{}).
+		Where("id = ?", id).
+		Update("last_seen_at", r.clock.Now()).Error
+}
+
+// Revoke ends the session and revokes its refresh tokens.
+func (r *SessionRepository) Revoke(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return revokeSessions(tx, r.clock.Now(), tx.Where("id = ?", id))
+	})
+}
+
+// RevokeAllExcept ends every active session of the user but keepID.
+func (r *SessionRepository) RevokeAllExcept(ctx context.Context, userID string, keepID string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return revokeSessions(tx, r.clock.Now(), tx.Where("user_id = ? AND id <> ?", userID, keepID))
+	})
+}
+
+func (r *SessionRepository) RevokeAllByUser(ctx context.Context, userID uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return revokeSessions(tx, r.clock.Now(), tx.Where("user_id = ?", userID))
+	})
+}
+
+func revokeSessions(tx *gorm.DB, now time.Time, scope *gorm.DB) error {
+	var ids []uuid.UUID
+	if err := scope.Model(&model.Session{}).Where("revoked_at IS NULL").Pluck("id", &ids).Error; err != nil {
+		return err
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	if err := tx.Model(&model.Session{}).Where("id IN ?", ids).Update("revoked_at", now).Error; err != nil {
+		return err
+	}
+	return tx.Model(&model.RefreshToken{}).
+		Where("session_id IN ? AND revoked_at IS NULL", ids).
+		Update("revoked_at", now).Error
+}
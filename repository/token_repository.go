@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"context"
+	"financial-track/model"
+	"financial-track/usecase"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type TokenRepository struct {
+	db    *gorm.DB
+	clock usecase.Clock
+}
+
+func NewTokenRepository(db *gorm.DB, clock usecase.Clock) *TokenRepository {
+	return &TokenRepository{db: db, clock: clock}
+}
+
+func (r *TokenRepository) CreateRefreshToken(ctx context.Context, token *model.RefreshToken) error {
+	return r.db.WithContext(ctx).Create(token).Error
+}
+
+func (r *TokenRepository) FindRefreshTokenByHash(ctx context.Context, hash string) (*model.RefreshToken, error) {
+	var token model.RefreshToken
+	err := r.db.WithContext(ctx).Where("token_hash = ?", hash).First(&token).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &token, nil
+}
+
+// RotateRefreshToken revokes the current token and stores its replacement in one
+// transaction. It returns gorm.ErrRecordNotFound if a concurrent call already
+// rotated it.
+func (r *TokenRepository) RotateRefreshToken(ctx context.Context, current *model.RefreshToken, next *model.RefreshToken) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(next).Error; err != nil {
+			return err
+		}
+		res := tx.Model(&model.RefreshToken{}).
+			Where("id = ? AND revoked_at IS NULL", current.ID).
+			Updates(map[string]interface{}{"revoked_at": r.clock.Now(), "replaced_by_id": next.ID})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	})
+}
+
+func (r *TokenRepository) RevokeJTI(ctx context.Context, jti string, expiresAt time.Time) error {
+	return r.db.WithContext(ctx).
+		Where(model.RevokedToken{JTI: jti}).
+		FirstOrCreate(&model.RevokedToken{JTI: jti, ExpiresAt: expiresAt}).Error
+}
+
+func (r *TokenRepository) IsJTIRevoked(ctx context.Context, jti string) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&model.RevokedToken{}).Where("jti = ?", jti).Count(&count).Error
+	return count > 0, err
+}
+
+func (r *TokenRepository) DeleteExpiredRevocations(ctx context.Context) error {
+	return r.db.WithContext(ctx).Where("expires_at < ?", r.clock.Now()).Delete(&model.RevokedToken{}).Error
+}
@@ -1,21 +1,26 @@
 package repository
 
 import (
-	"financial-track/database"
+	"context"
 	"financial-track/model"
+	"financial-track/usecase"
 
+	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
-type UserRepository struct{}
+type UserRepository struct {
+	db    *gorm.DB
+	clock usecase.Clock
+}
 
-func NewUserRepository() *UserRepository {
-	return &UserRepository{}
+func NewUserRepository(db *gorm.DB, clock usecase.Clock) *UserRepository {
+	return &UserRepository{db: db, clock: clock}
 }
 
-func (r *UserRepository) FindByEmail(email string) (*model.User, error) {
+func (r *UserRepository) FindByEmail(ctx context.Context, email string) (*model.User, error) {
 	var user model.User
-	err := database.DB.Where("email = ?", email).First(&user).Error
+	err := r.db.WithContext(ctx).Where("email = ?", email).First(&user).Error
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, nil
@@ -25,13 +30,13 @@ func (r *UserRepository) FindByEmail(email string) (*model.User, error) {
 	return &user, nil
 }
 
-func (r *UserRepository) Create(user *model.User) error {
-	return database.DB.Create(user).Error
+func (r *UserRepository) Create(ctx context.Context, user *model.User) error {
+	return r.db.WithContext(ctx).Create(user).Error
 }
 
-func (r *UserRepository) FindByID(id string) (*model.User, error) {
+func (r *UserRepository) FindByID(ctx context.Context, id string) (*model.User, error) {
 	var user model.User
-	err := database.DB.Where("id = ?", id).First(&user).Error
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&user).Error
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, nil
@@ -40,3 +45,43 @@ func (r *UserRepository) FindByID(id string) (*model.User, error) {
 	}
 	return &user, nil
 }
+
+func (r *UserRepository) UpdatePassword(ctx context.Context, id uuid.UUID, hashedPassword string) error {
+	return r.db.WithContext(ctx).Model(&model.User{}).Where("id = ?", id).Update("password", hashedPassword).Error
+}
+
+func (r *UserRepository) MarkEmailVerified(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Model(&model.User{}).Where("id = ?", id).Update("email_verified_at", r.clock.Now()).Error
+}
+
+// Update changes profile fields and audits the difference.
+func (r *UserRepository) Update(ctx context.Context, id uuid.UUID, fields map[string]interface{}, actor model.AuditActor) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var before model.User
+		if err := tx.Where("id = ?", id).First(&before).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&model.User{}).Where("id = ?", id).Updates(fields).Error; err != nil {
+			return err
+		}
+		var after model.User
+		if err := tx.Where("id = ?", id).First(&after).Error; err != nil {
+			return err
+		}
+		return writeAudit(tx, actor, id, model.AuditEntityUser, id.String(), model.AuditUpdate, before, after)
+	})
+}
+
+// Delete removes the user; expenses, sessions and tokens go by ON DELETE CASCADE.
+func (r *UserRepository) Delete(ctx context.Context, id uuid.UUID, actor model.AuditActor) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var user model.User
+		if err := tx.Where("id = ?", id).First(&user).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&user).Error; err != nil {
+			return err
+		}
+		return writeAudit(tx, actor, id, model.AuditEntityUser, id.String(), model.AuditDelete, user, nil)
+	})
+}
@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"context"
+	"financial-track/model"
+	"financial-track/usecase"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type UserTokenRepository struct {
+	db    *gorm.DB
+	clock usecase.Clock
+}
+
+func NewUserTokenRepository(db *gorm.DB, clock usecase.Clock) *UserTokenRepository {
+	return &UserTokenRepository{db: db, clock: clock}
+}
+
+// Create stores a token and invalidates unused ones of the same purpose.
+func (r *UserTokenRepository) Create(ctx context.Context, token *model.UserToken) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&model.UserToken{}).
+			Where("user_id = ? AND purpose = ? AND used_at IS NULL", token.UserID, token.Purpose).
+			Update("used_at", r.clock.Now()).Error; err != nil {
+			return err
+		}
+		return tx.Create(token).Error
+	})
+}
+
+func (r *UserTokenRepository) FindByHash(ctx context.Context, hash string, purpose model.UserTokenPurpose) (*model.UserToken, error) {
+	var token model.UserToken
+	err := r.db.WithContext(ctx).Where("token_hash = ? AND purpose = ?", hash, purpose).First(&token).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &token, nil
+}
+
+// Consume marks the token used. It returns false if it already was.
+func (r *UserTokenRepository) Consume(ctx context.Context, id uuid.UUID) (bool, error) {
+	res := r.db.WithContext(ctx).Model(&model.UserToken{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", r.clock.Now())
+	return res.RowsAffected == 1, res.Error
+}
@@ -0,0 +1,14 @@
+package route
+
+import "financial-track/controller"
+
+// Controllers groups the wired controllers.
+type Controllers struct {
+	User    *controller.UserController
+	Session *controller.SessionController
+	Profile *controller.ProfileController
+	OIDC    *controller.OIDCController
+	APIKey  *controller.APIKeyController
+	Audit   *controller.AuditController
+	Expense *controller.ExpenseController
+}
@@ -2,14 +2,25 @@ package route
 
 import (
 	"financial-track/controller"
+	"financial-track/middleware"
+	"financial-track/model"
 
 	"github.com/gin-gonic/gin"
 )
 
-func RegisterExpenseRoutes(r *gin.RouterGroup) {
+func RegisterExpenseRoutes(r *gin.RouterGroup, expenseController *controller.ExpenseController) {
 	expense := r.Group("/expenses")
 	{
-		expense.POST("/", controller.CreateExpense)
-		expense.GET("/mensal-summary", controller.GetMensalSummary)
+		expense.POST("/", middleware.RequireScope(model.ScopeExpensesWrite), expenseController.CreateExpense)
+		expense.GET("/", middleware.RequireScope(model.ScopeExpensesRead), expenseController.ListExpenses)
+		expense.GET("/mensal-summary", middleware.RequireScope(model.ScopeExpensesRead), expenseController.GetMensalSummary)
+		expense.GET("/:id", middleware.RequireScope(model.ScopeExpensesRead), expenseController.GetExpense)
+		expense.PATCH("/:id", middleware.RequireScope(model.ScopeExpensesWrite), expenseController.UpdateExpense)
+		expense.DELETE("/:id", middleware.RequireScope(model.ScopeExpensesWrite), expenseController.DeleteExpense)
+		expense.POST("/bulk", middleware.RequireScope(model.ScopeExpensesWrite), expenseController.BulkExpenses)
+
+		expense.GET("/trash", middleware.RequireScope(model.ScopeExpensesRead), expenseController.ListExpenseTrash)
+		expense.POST("/trash/:id/restore", middleware.RequireScope(model.ScopeExpensesWrite), expenseController.RestoreExpense)
+		expense.DELETE("/trash/:id", middleware.RequireScope(model.ScopeExpensesWrite), expenseController.PurgeExpense)
 	}
 }
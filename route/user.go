@@ -1,19 +1,67 @@
 package route
 
 import (
-	"financial-track/controller"
+	"financial-track/middleware"
 
 	"github.com/gin-gonic/gin"
 )
 
-func RegisterUserRoutes(r *gin.Engine) {
-	userController := controller.NewUserController()
+func RegisterUserRoutes(r *gin.Engine, controllers Controllers, handlers ...gin.HandlerFunc) {
+	userController := controllers.User
 	api := r.Group("/")
 	{
-		auth := api.Group("/auth")
+		auth := api.Group("/auth", handlers...)
 		{
 			auth.POST("/register", userController.RegisterUser)
 			auth.POST("/login", userController.LoginUser)
+			auth.POST("/2fa/verify", userController.VerifyTwoFactor)
+			auth.POST("/refresh", userController.RefreshToken)
+			auth.POST("/forgot-password", userController.ForgotPassword)
+			auth.POST("/reset-password", userController.ResetPassword)
+			auth.POST("/verify-email", userController.VerifyEmail)
+			auth.POST("/verify-email/resend", userController.ResendVerification)
+
+			oidcController := controllers.OIDC
+			auth.GET("/oidc/:provider/login", oidcController.Login)
+			auth.GET("/oidc/:provider/callback", oidcController.Callback)
 		}
 	}
 }
+
+func RegisterAuthenticatedUserRoutes(r *gin.RouterGroup, controllers Controllers) {
+	userController := controllers.User
+	sessionController := controllers.Session
+	auth := r.Group("/auth", middleware.RequireSession())
+	{
+		auth.POST("/logout", userController.Logout)
+		auth.GET("/sessions", sessionController.ListSessions)
+		auth.DELETE("/sessions", sessionController.RevokeOtherSessions)
+		auth.DELETE("/sessions/:id", sessionController.RevokeSession)
+	}
+
+	profileController := controllers.Profile
+	me := r.Group("/me", middleware.RequireSession())
+	{
+		me.GET("", profileController.GetProfile)
+		me.PATCH("", profileController.UpdateProfile)
+		me.POST("/password", profileController.ChangePassword)
+		me.POST("/delete-request", profileController.RequestAccountDeletion)
+		me.DELETE("", profileController.DeleteAccount)
+		me.POST("/2fa/setup", profileController.SetupTwoFactor)
+		me.POST("/2fa/enable", profileController.EnableTwoFactor)
+		me.POST("/2fa/disable", profileController.DisableTwoFactor)
+
+		oidcController := controllers.OIDC
+		me.GET("/identities", oidcController.ListIdentities)
+		me.POST("/oidc/:provider/link", oidcController.LinkIdentity)
+		me.DELETE("/identities/:id", oidcController.UnlinkIdentity)
+
+		apiKeyController := controllers.APIKey
+		me.GET("/api-keys", apiKeyController.ListAPIKeys)
+		me.POST("/api-keys", apiKeyController.CreateAPIKey)
+		me.DELETE("/api-keys/:id", apiKeyController.DeleteAPIKey)
+
+		auditController := controllers.Audit
+		me.GET("/audit-log", auditController.ListAuditLog)
+	}
+}
@@ -0,0 +1,21 @@
+package route
+
+import (
+	"financial-track/authtoken"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterWellKnownRoutes(r *gin.Engine, tokens *authtoken.Service) {
+	wellKnown := r.Group("/.well-known")
+	{
+		wellKnown.GET("/jwks.json", jwksRoute(tokens))
+	}
+}
+
+func jwksRoute(tokens *authtoken.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header("Cache-Control", "public, max-age=300")
+		c.JSON(200, tokens.JWKS())
+	}
+}
@@ -0,0 +1,207 @@
+package seed
+
+import (
+	"financial-track/model"
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+)
+
+// DefaultUntil is the end of the generated period when Options.Until is not set,
+// fixed so that the same seed generates the same data on any day.
+const DefaultUntil = "2026-01-01"
+
+// Options controls volume and reproducibility. The same Seed and Until give the
+// same result.
+type Options struct {
+	Seed   int64
+	Users  int
+	Months int
+	// Volume scales how often variable expenses happen (1 = default).
+	Volume float64
+	// Until is the exclusive end of the period; months count back from it.
+	// Zero means DefaultUntil in UTC.
+	Until time.Time
+	// EmailDomain is used in generated emails (default "seed.local").
+	EmailDomain string
+}
+
+type User struct {
+	Name     string                     `json:"name"`
+	Email    string                     `json:"email"`
+	Expenses []model.CreateExpenseInput `json:"expenses"`
+}
+
+var firstNames = []string{
+	"Ana", "Bruno", "Carla", "Diego", "Eduarda", "Felipe", "Gabriela", "Henrique",
+	"Isabela", "João", "Larissa", "Marcos", "Natália", "Otávio", "Paula", "Rafael",
+}
+
+var lastNames = []string{
+	"Silva", "Souza", "Oliveira", "Santos", "Pereira", "Costa", "Ferreira", "Almeida",
+	"Ribeiro", "Carvalho", "Gomes", "Martins",
+}
+
+// occasional is a variable expense: daily chance, amount range and
+// descriptions.
+type occasional struct {
+	category     model.Category
+	chance       float64
+	weekendBoost float64
+	min, max     float64
+	descriptions []string
+}
+
+var occasionals = []occasional{
+	{model.Food, 0.9, 1, 15, 70, []string{"Almoço", "Lanche", "Café", "Padaria", "Jantar", "Delivery"}},
+	{model.Food, 0.15, 1.5, 120, 600, []string{"Supermercado", "Feira", "Atacadão"}},
+	{model.Transportation, 0.55, 0.5, 5, 45, []string{"Uber", "Ônibus", "Metrô", "Estacionamento"}},
+	{model.Transportation, 0.08, 1, 150, 320, []string{"Combustível"}},
+	{model.Health, 0.05, 1, 20, 250, []string{"Farmácia", "Consulta", "Exames"}},
+	{model.Education, 0.03, 1, 40, 200, []string{"Livros", "Curso online", "Material"}},
+	{model.Entertainment, 0.12, 3, 30, 220, []string{"Cinema", "Bar", "Show", "Restaurante", "Jogos"}},
+	{model.Clothing, 0.04, 2, 60, 400, []string{"Roupas", "Calçados", "Acessórios"}},
+	{model.Personal, 0.07, 1, 15, 150, []string{"Cabeleireiro", "Cosméticos", "Presente"}},
+	{model.Finance, 0.02, 1, 5, 80, []string{"Tarifa bancária", "Juros", "IOF"}},
+	{model.Others, 0.04, 1, 10, 150, []string{"Pet shop", "Doação", "Correios", "Diversos"}},
+}
+
+// Rare big purchases, on any day of the month.
+var bigPurchases = []occasional{
+	{model.Housing, 0, 1, 1500, 6000, []string{"Móveis", "Geladeira", "Reforma"}},
+	{model.Entertainment, 0, 1, 2000, 9000, []string{"Viagem", "Videogame"}},
+	{model.Clothing, 0, 1, 1200, 3000, []string{"Roupas de inverno"}},
+	{model.Education, 0, 1, 1500, 5000, []string{"Notebook", "Pós-graduação (matrícula)"}},
+	{model.Others, 0, 1, 1000, 4000, []string{"Celular novo", "Bicicleta"}},
+}
+
+const bigPurchaseChance = 0.008
+
+// Generate builds the users and their expenses without touching the database.
+func Generate(opts Options) []User {
+	if opts.Volume <= 0 {
+		opts.Volume = 1
+	}
+	if opts.EmailDomain == "" {
+		opts.EmailDomain = "seed.local"
+	}
+
+	if opts.Until.IsZero() {
+		opts.Until, _ = time.Parse("2006-01-02", DefaultUntil)
+	}
+
+	rng := rand.New(rand.NewSource(opts.Seed))
+	loc := opts.Until.Location()
+	end := time.Date(opts.Until.Year(), opts.Until.Month(), opts.Until.Day(), 0, 0, 0, 0, loc)
+	start := end.AddDate(0, -opts.Months, 0)
+
+	users := make([]User, 0, opts.Users)
+	for i := 0; i < opts.Users; i++ {
+		first := firstNames[rng.Intn(len(firstNames))]
+		last := lastNames[rng.Intn(len(lastNames))]
+		users = append(users, User{
+			Name:     first + " " + last,
+			Email:    fmt.Sprintf("seed-%d-%d@%s", opts.Seed, i+1, opts.EmailDomain),
+			Expenses: generateExpenses(rng, start, end, opts.Volume),
+		})
+	}
+	return users
+}
+
+// profile holds a user's fixed expenses, drawn once.
+type profile struct {
+	rent, utilities, gym, streaming, course, investment float64
+	rentDay                                             int
+}
+
+func generateExpenses(rng *rand.Rand, start, end time.Time, volume float64) []model.CreateExpenseInput {
+	p := profile{
+		rent:       roundTo(amountBetween(rng, 1200, 3500), 50),
+		utilities:  amountBetween(rng, 180, 450),
+		rentDay:    5 + rng.Intn(5),
+		streaming:  []float64{0, 21.9, 39.9, 55.9}[rng.Intn(4)],
+		investment: []float64{0, 0, 300, 500, 1000}[rng.Intn(5)],
+	}
+	if rng.Float64() < 0.6 {
+		p.gym = amountBetween(rng, 90, 180)
+	}
+	if rng.Float64() < 0.3 {
+		p.course = amountBetween(rng, 300, 1200)
+	}
+
+	var expenses []model.CreateExpenseInput
+	add := func(day time.Time, category model.Category, amount float64, description string) {
+		at := day.Add(time.Duration(7+rng.Intn(15))*time.Hour + time.Duration(rng.Intn(60))*time.Minute)
+		expenses = append(expenses, model.CreateExpenseInput{
+			Category:      category,
+			Amount:        amount,
+			Description:   description,
+			TransactionAt: model.JSONTime{Time: at},
+		})
+	}
+
+	for day := start; day.Before(end); day = day.AddDate(0, 0, 1) {
+		// Monthly bills: rent and utilities with stable amounts.
+		switch day.Day() {
+		case p.rentDay:
+			add(day, model.Housing, p.rent, "Aluguel")
+		case 10:
+			add(day, model.Housing, jitter(rng, p.utilities, 0.15), "Luz, água e internet")
+			if p.gym > 0 {
+				add(day, model.Health, p.gym, "Academia")
+			}
+		case 15:
+			if p.streaming > 0 {
+				add(day, model.Entertainment, p.streaming, "Streaming")
+			}
+			if p.course > 0 {
+				add(day, model.Education, p.course, "Mensalidade do curso")
+			}
+		case 20:
+			if p.investment > 0 {
+				add(day, model.Finance, p.investment, "Aporte mensal")
+			}
+		}
+
+		weekend := day.Weekday() == time.Saturday || day.Weekday() == time.Sunday
+		for _, o := range occasionals {
+			chance := o.chance * volume
+			if weekend {
+				chance *= o.weekendBoost
+			}
+			if rng.Float64() < chance {
+				add(day, o.category, amountBetween(rng, o.min, o.max), pick(rng, o.descriptions))
+			}
+		}
+
+		if rng.Float64() < bigPurchaseChance*volume {
+			o := bigPurchases[rng.Intn(len(bigPurchases))]
+			add(day, o.category, roundTo(amountBetween(rng, o.min, o.max), 10), pick(rng, o.descriptions))
+		}
+	}
+	return expenses
+}
+
+// amountBetween draws an amount skewed to the low end of the range, like
+// everyday spending.
+func amountBetween(rng *rand.Rand, min, max float64) float64 {
+	f := rng.Float64()
+	return cents(min + (max-min)*f*f)
+}
+
+func jitter(rng *rand.Rand, value, spread float64) float64 {
+	return cents(value * (1 - spread + 2*spread*rng.Float64()))
+}
+
+func pick(rng *rand.Rand, options []string) string {
+	return options[rng.Intn(len(options))]
+}
+
+func cents(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+func roundTo(v, step float64) float64 {
+	return math.Round(v/step) * step
+}
@@ -0,0 +1,46 @@
+package seed
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestGenerateIsDeterministic(t *testing.T) {
+	opts := Options{Seed: 42, Users: 3, Months: 2}
+
+	first, second := Generate(opts), Generate(opts)
+	if !reflect.DeepEqual(first, second) {
+		t.Fatal("the same options generated different data")
+	}
+	a, _ := json.Marshal(first)
+	b, _ := json.Marshal(second)
+	if string(a) != string(b) {
+		t.Fatal("the same options generated different JSON")
+	}
+
+	if other := Generate(Options{Seed: 43, Users: 3, Months: 2}); reflect.DeepEqual(first, other) {
+		t.Fatal("different seeds generated the same data")
+	}
+}
+
+func TestGenerateDefaultPeriod(t *testing.T) {
+	until, _ := time.Parse("2006-01-02", DefaultUntil)
+	start := until.AddDate(0, -2, 0)
+
+	users := Generate(Options{Seed: 1, Users: 2, Months: 2})
+	if !reflect.DeepEqual(users, Generate(Options{Seed: 1, Users: 2, Months: 2, Until: until})) {
+		t.Fatal("zero Until does not default to DefaultUntil")
+	}
+	for _, user := range users {
+		if len(user.Expenses) == 0 {
+			t.Fatalf("%s has no expenses", user.Email)
+		}
+		for _, expense := range user.Expenses {
+			if at := expense.TransactionAt.Time; at.Before(start) || !at.Before(until) {
+				t.Fatalf("%s: expense at %s outside %s..%s", user.Email, at, start, until)
+			}
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"financial-track/mailer"
+	"financial-track/model"
+	"financial-track/utils"
+	"fmt"
+	"log"
+	"net/url"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	emailVerificationTTL = 24 * time.Hour
+	passwordResetTTL     = time.Hour
+)
+
+var ErrInvalidUserToken = errors.New("invalid or expired token")
+
+// ForgotPassword emails a reset link. It never reveals whether the email exists.
+func (u *UserUseCase) ForgotPassword(ctx context.Context, input model.ForgotPasswordInput) error {
+	user, err := u.repo.FindByEmail(ctx, input.Email)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return nil
+	}
+
+	raw, err := u.createUserToken(ctx, user, model.PasswordResetPurpose, passwordResetTTL)
+	if err != nil {
+		return err
+	}
+
+	// Send errors are only logged so the response cannot reveal the account.
+	logMailError(u.mailer.Send(mailer.Message{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nUse the link below to choose a new password. It expires in 1 hour.\n\n%s\n\nIf you did not request this, you can ignore this email.\n",
+			user.Name, u.appLink("/reset-password", raw),
+		),
+	}))
+	return nil
+}
+
+// ResetPassword sets a new password and revokes all sessions and API keys.
+func (u *UserUseCase) ResetPassword(ctx context.Context, input model.ResetPasswordInput) error {
+	token, err := u.consumeUserToken(ctx, input.Token, model.PasswordResetPurpose)
+	if err != nil {
+		return err
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(input.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return errors.New("error to hash password")
+	}
+
+	if err := u.repo.UpdatePassword(ctx, token.UserID, string(hashedPassword)); err != nil {
+		return err
+	}
+
+	if err := u.sessionRepo.RevokeAllByUser(ctx, token.UserID); err != nil {
+		return err
+	}
+	return u.apiKeyRepo.DeleteAllByUser(ctx, token.UserID)
+}
+
+func (u *UserUseCase) VerifyEmail(ctx context.Context, input model.VerifyEmailInput) error {
+	token, err := u.consumeUserToken(ctx, input.Token, model.EmailVerificationPurpose)
+	if err != nil {
+		return err
+	}
+	return u.repo.MarkEmailVerified(ctx, token.UserID)
+}
+
+func (u *UserUseCase) ResendVerification(ctx context.Context, input model.ResendVerificationInput) error {
+	user, err := u.repo.FindByEmail(ctx, input.Email)
+	if err != nil {
+		return err
+	}
+	if user == nil || user.EmailVerifiedAt != nil {
+		return nil
+	}
+	logMailError(u.sendVerificationEmail(ctx, user))
+	return nil
+}
+
+func (u *UserUseCase) sendVerificationEmail(ctx context.Context, user *model.User) error {
+	raw, err := u.createUserToken(ctx, user, model.EmailVerificationPurpose, emailVerificationTTL)
+	if err != nil {
+		return err
+	}
+
+	return u.mailer.Send(mailer.Message{
+		To:      user.Email,
+		Subject: "Confirm your email",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nPlease confirm your email address using the link below. It expires in 24 hours.\n\n%s\n",
+			user.Name, u.appLink("/verify-email", raw),
+		),
+	})
+}
+
+func (u *UserUseCase) createUserToken(ctx context.Context, user *model.User, purpose model.UserTokenPurpose, ttl time.Duration) (string, error) {
+	raw, hash, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return "", err
+	}
+
+	token := model.UserToken{
+		UserID:    user.ID,
+		Purpose:   purpose,
+		TokenHash: hash,
+		ExpiresAt: u.clock.Now().Add(ttl),
+	}
+	if err := u.userTokenRepo.Create(ctx, &token); err != nil {
+		return "", err
+	}
+	return raw, nil
+}
+
+func (u *UserUseCase) consumeUserToken(ctx context.Context, raw string, purpose model.UserTokenPurpose) (*model.UserToken, error) {
+	token, err := u.userTokenRepo.FindByHash(ctx, utils.HashToken(raw), purpose)
+	if err != nil {
+		return nil, err
+	}
+	if token == nil || token.UsedAt != nil || u.clock.Now().After(token.ExpiresAt) {
+		return nil, ErrInvalidUserToken
+	}
+
+	consumed, err := u.userTokenRepo.Consume(ctx, token.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !consumed {
+		return nil, ErrInvalidUserToken
+	}
+	return token, nil
+}
+
+// AppInfo names the app (the 2FA issuer) and sets the base URL, without a
+// trailing slash, of emailed links.
+type AppInfo struct {
+	Name string
+	URL  string
+}
+
+func (u *UserUseCase) appLink(path string, token string) string {
+	return u.app.URL + path + "?token=" + url.QueryEscape(token)
+}
+
+func logMailError(err error) {
+	if err != nil {
+		log.Println("⚠️ Failed to send email:", err)
+	}
+}
@@ -0,0 +1,90 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"financial-track/model"
+	"testing"
+	"time"
+)
+
+func TestUserTokens(t *testing.T) {
+	tests := []struct {
+		name    string
+		send    func(uc *UserUseCase, f *userFakes) error
+		consume func(uc *UserUseCase, token string) error
+		ttl     time.Duration
+	}{
+		{
+			name: "password reset",
+			send: func(uc *UserUseCase, f *userFakes) error {
+				return uc.ForgotPassword(context.Background(), model.ForgotPasswordInput{Email: f.alice.Email})
+			},
+			consume: func(uc *UserUseCase, token string) error {
+				return uc.ResetPassword(context.Background(), model.ResetPasswordInput{Token: token, Password: "new password"})
+			},
+			ttl: passwordResetTTL,
+		},
+		{
+			name: "email verification",
+			send: func(uc *UserUseCase, f *userFakes) error {
+				return uc.ResendVerification(context.Background(), model.ResendVerificationInput{Email: f.alice.Email})
+			},
+			consume: func(uc *UserUseCase, token string) error {
+				return uc.VerifyEmail(context.Background(), model.VerifyEmailInput{Token: token})
+			},
+			ttl: emailVerificationTTL,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name+" is single use", func(t *testing.T) {
+			uc, f := newUserUseCaseWithFakes(t, newFakeLoginGuard())
+			if err := tt.send(uc, f); err != nil {
+				t.Fatal(err)
+			}
+			token := mailedToken(t, f.mailer)
+
+			if err := tt.consume(uc, token); err != nil {
+				t.Fatalf("first use = %v", err)
+			}
+			if err := tt.consume(uc, token); !errors.Is(err, ErrInvalidUserToken) {
+				t.Fatalf("second use = %v, want %v", err, ErrInvalidUserToken)
+			}
+		})
+		t.Run(tt.name+" expires", func(t *testing.T) {
+			uc, f := newUserUseCaseWithFakes(t, newFakeLoginGuard())
+			if err := tt.send(uc, f); err != nil {
+				t.Fatal(err)
+			}
+			token := mailedToken(t, f.mailer)
+
+			f.clock.now = f.clock.now.Add(tt.ttl + time.Second)
+			if err := tt.consume(uc, token); !errors.Is(err, ErrInvalidUserToken) {
+				t.Fatalf("expired use = %v, want %v", err, ErrInvalidUserToken)
+			}
+		})
+	}
+}
+
+func TestUserTokenPurposesDoNotMix(t *testing.T) {
+	uc, f := newUserUseCaseWithFakes(t, newFakeLoginGuard())
+	if err := uc.ResendVerification(context.Background(), model.ResendVerificationInput{Email: f.alice.Email}); err != nil {
+		t.Fatal(err)
+	}
+
+	err := uc.ResetPassword(context.Background(), model.ResetPasswordInput{Token: mailedToken(t, f.mailer), Password: "new password"})
+	if !errors.Is(err, ErrInvalidUserToken) {
+		t.Fatalf("ResetPassword with a verification token = %v, want %v", err, ErrInvalidUserToken)
+	}
+}
+
+func TestForgotPasswordUnknownEmail(t *testing.T) {
+	uc, f := newUserUseCaseWithFakes(t, newFakeLoginGuard())
+
+	if err := uc.ForgotPassword(context.Background(), model.ForgotPasswordInput{Email: "nobody@example.com"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(f.mailer.sent) != 0 || len(f.userTokens.tokens) != 0 {
+		t.Error("unknown email must not create or mail a token")
+	}
+}
@@ -0,0 +1,87 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"financial-track/loginguard"
+	"financial-track/model"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Admin operations for the CLI; they skip the email flows.
+
+func (u *UserUseCase) GetUserByEmail(ctx context.Context, email string) (*model.User, error) {
+	user, err := u.repo.FindByEmail(ctx, email)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+	return user, nil
+}
+
+// AdminCreateUser creates the account; with verified the email is confirmed and
+// no verification email is sent.
+func (u *UserUseCase) AdminCreateUser(ctx context.Context, input model.CreateUserInput, verified bool) (*model.User, error) {
+	if !verified {
+		return u.RegisterUser(ctx, input)
+	}
+
+	existing, err := u.repo.FindByEmail(ctx, input.Email)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, ErrEmailInUse
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(input.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, errors.New("error to hash password")
+	}
+
+	user := model.User{
+		Name:     input.Name,
+		Email:    input.Email,
+		Password: string(hashedPassword),
+	}
+	if err := u.repo.Create(ctx, &user); err != nil {
+		return nil, err
+	}
+	if err := u.repo.MarkEmailVerified(ctx, user.ID); err != nil {
+		return nil, err
+	}
+
+	return u.GetProfile(ctx, user.ID.String())
+}
+
+// AdminSetPassword sets a new password, revokes all sessions and API keys and
+// clears any login lockout.
+func (u *UserUseCase) AdminSetPassword(ctx context.Context, email, password string) error {
+	if len(password) < 6 {
+		return errors.New("password must have at least 6 characters")
+	}
+
+	user, err := u.GetUserByEmail(ctx, email)
+	if err != nil {
+		return err
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return errors.New("error to hash password")
+	}
+	if err := u.repo.UpdatePassword(ctx, user.ID, string(hashedPassword)); err != nil {
+		return err
+	}
+	if err := u.sessionRepo.RevokeAllByUser(ctx, user.ID); err != nil {
+		return err
+	}
+	if err := u.apiKeyRepo.DeleteAllByUser(ctx, user.ID); err != nil {
+		return err
+	}
+	return u.guard.Reset(ctx, loginguard.Account(strings.ToLower(user.Email)))
+}
@@ -0,0 +1,131 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"financial-track/model"
+	"financial-track/utils"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// APIKeyPrefix marks an API key in the Authorization header.
+const APIKeyPrefix = "ftk_"
+
+var (
+	ErrInvalidAPIKeyScope = errors.New("invalid api key scope")
+	ErrAPIKeyNotFound     = errors.New("api key not found")
+	ErrInvalidAPIKey      = errors.New("invalid or expired api key")
+)
+
+type APIKeyUseCase struct {
+	repo  APIKeyStore
+	clock Clock
+}
+
+func NewAPIKeyUseCase(repo APIKeyStore, clock Clock) *APIKeyUseCase {
+	return &APIKeyUseCase{repo: repo, clock: clock}
+}
+
+func IsAPIKey(token string) bool {
+	return strings.HasPrefix(token, APIKeyPrefix)
+}
+
+func (a *APIKeyUseCase) CreateAPIKey(ctx context.Context, userID string, input model.CreateAPIKeyInput) (*model.CreatedAPIKeyResponse, error) {
+	uid, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, errors.New("invalid user id")
+	}
+
+	scopes := make([]string, 0, len(input.Scopes))
+	for _, scope := range input.Scopes {
+		if !model.IsValidAPIKeyScope(scope) {
+			return nil, ErrInvalidAPIKeyScope
+		}
+		scopes = append(scopes, string(scope))
+	}
+
+	raw, _, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+	fullKey := APIKeyPrefix + raw
+
+	key := model.APIKey{
+		UserID:  uid,
+		Name:    strings.TrimSpace(input.Name),
+		Prefix:  fullKey[:12],
+		KeyHash: utils.HashToken(fullKey),
+		Scopes:  strings.Join(scopes, " "),
+	}
+	if input.ExpiresInDays != nil {
+		expiresAt := a.clock.Now().AddDate(0, 0, *input.ExpiresInDays)
+		key.ExpiresAt = &expiresAt
+	}
+
+	if err := a.repo.Create(ctx, &key); err != nil {
+		return nil, err
+	}
+
+	return &model.CreatedAPIKeyResponse{APIKeyResponse: toAPIKeyResponse(key), Key: fullKey}, nil
+}
+
+func (a *APIKeyUseCase) ListAPIKeys(ctx context.Context, userID string) ([]model.APIKeyResponse, error) {
+	keys, err := a.repo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := make([]model.APIKeyResponse, 0, len(keys))
+	for _, key := range keys {
+		resp = append(resp, toAPIKeyResponse(key))
+	}
+	return resp, nil
+}
+
+func (a *APIKeyUseCase) DeleteAPIKey(ctx context.Context, userID string, id string) error {
+	if _, err := uuid.Parse(id); err != nil {
+		return ErrAPIKeyNotFound
+	}
+	deleted, err := a.repo.Delete(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrAPIKeyNotFound
+	}
+	return nil
+}
+
+// Authenticate checks the presented key and returns its record.
+func (a *APIKeyUseCase) Authenticate(ctx context.Context, fullKey string) (*model.APIKey, error) {
+	key, err := a.repo.FindByHash(ctx, utils.HashToken(fullKey))
+	if err != nil {
+		return nil, err
+	}
+	now := a.clock.Now()
+	if key == nil || (key.ExpiresAt != nil && now.After(*key.ExpiresAt)) {
+		return nil, ErrInvalidAPIKey
+	}
+
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) > time.Minute {
+		if err := a.repo.TouchLastUsed(ctx, key.ID); err != nil {
+			return nil, err
+		}
+	}
+	return key, nil
+}
+
+func toAPIKeyResponse(key model.APIKey) model.APIKeyResponse {
+	return model.APIKeyResponse{
+		ID:         key.ID,
+		Name:       key.Name,
+		Prefix:     key.Prefix,
+		Scopes:     key.ScopeList(),
+		ExpiresAt:  key.ExpiresAt,
+		LastUsedAt: key.LastUsedAt,
+		CreatedAt:  key.CreatedAt,
+	}
+}
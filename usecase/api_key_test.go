@@ -0,0 +1,120 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"financial-track/model"
+	"financial-track/utils"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCreateAPIKey(t *testing.T) {
+	now := time.Date(2025, 10, 15, 12, 0, 0, 0, time.UTC)
+	days := 30
+
+	tests := []struct {
+		name        string
+		input       model.CreateAPIKeyInput
+		want        error
+		wantExpires *time.Time
+	}{
+		{name: "read only", input: model.CreateAPIKeyInput{Name: " script ", Scopes: []model.APIKeyScope{model.ScopeExpensesRead}}},
+		{name: "with expiry", input: model.CreateAPIKeyInput{Name: "ci", Scopes: []model.APIKeyScope{model.ScopeExpensesRead, model.ScopeExpensesWrite}, ExpiresInDays: &days}, wantExpires: ptrTime(now.AddDate(0, 0, days))},
+		{name: "unknown scope", input: model.CreateAPIKeyInput{Name: "admin", Scopes: []model.APIKeyScope{model.ScopeExpensesRead, "users:write"}}, want: ErrInvalidAPIKeyScope},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newFakeAPIKeyStore()
+			uc := NewAPIKeyUseCase(store, &fixedClock{now: now})
+
+			created, err := uc.CreateAPIKey(context.Background(), expenseTestUser, tt.input)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("CreateAPIKey = %v, want %v", err, tt.want)
+			}
+			if tt.want != nil {
+				if len(store.keys) != 0 {
+					t.Fatal("key with an invalid scope reached the store")
+				}
+				return
+			}
+
+			if !IsAPIKey(created.Key) || !strings.HasPrefix(created.Key, created.Prefix) {
+				t.Fatalf("key = %q, prefix = %q", created.Key, created.Prefix)
+			}
+			stored := store.keys[utils.HashToken(created.Key)]
+			if stored == nil {
+				t.Fatal("key not stored by its hash")
+			}
+			if stored.KeyHash == created.Key || strings.Contains(stored.Scopes+stored.Name+stored.Prefix, created.Key) {
+				t.Fatal("plain key persisted")
+			}
+			if stored.Name != strings.TrimSpace(tt.input.Name) {
+				t.Errorf("Name = %q", stored.Name)
+			}
+			if got := stored.ScopeList(); len(got) != len(tt.input.Scopes) || got[0] != tt.input.Scopes[0] {
+				t.Errorf("scopes = %v, want %v", got, tt.input.Scopes)
+			}
+			if (stored.ExpiresAt == nil) != (tt.wantExpires == nil) || (tt.wantExpires != nil && !stored.ExpiresAt.Equal(*tt.wantExpires)) {
+				t.Errorf("ExpiresAt = %v, want %v", stored.ExpiresAt, tt.wantExpires)
+			}
+		})
+	}
+}
+
+func TestAuthenticateAPIKey(t *testing.T) {
+	now := time.Date(2025, 10, 15, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		// change edits the stored key and returns the presented one.
+		change      func(key *model.APIKey, raw string) string
+		want        error
+		wantTouched bool
+	}{
+		{name: "valid key", wantTouched: true},
+		{name: "unknown key", change: func(key *model.APIKey, raw string) string { return raw + "x" }, want: ErrInvalidAPIKey},
+		{name: "expired key", change: func(key *model.APIKey, raw string) string {
+			key.ExpiresAt = ptrTime(now.Add(-time.Second))
+			return raw
+		}, want: ErrInvalidAPIKey},
+		{name: "recently used key is not touched", change: func(key *model.APIKey, raw string) string {
+			key.LastUsedAt = ptrTime(now.Add(-30 * time.Second))
+			return raw
+		}},
+		{name: "key used long ago is touched", change: func(key *model.APIKey, raw string) string {
+			key.LastUsedAt = ptrTime(now.Add(-time.Hour))
+			return raw
+		}, wantTouched: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newFakeAPIKeyStore()
+			uc := NewAPIKeyUseCase(store, &fixedClock{now: now})
+			created, err := uc.CreateAPIKey(context.Background(), expenseTestUser, model.CreateAPIKeyInput{Name: "script", Scopes: []model.APIKeyScope{model.ScopeExpensesRead}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			presented := created.Key
+			if tt.change != nil {
+				presented = tt.change(store.keys[utils.HashToken(created.Key)], created.Key)
+			}
+
+			key, err := uc.Authenticate(context.Background(), presented)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("Authenticate = %v, want %v", err, tt.want)
+			}
+			if tt.want == nil && (key == nil || key.ID != created.ID || !key.HasScope(model.ScopeExpensesRead) || key.HasScope(model.ScopeExpensesWrite)) {
+				t.Fatalf("key = %+v, want the read-only key %s", key, created.ID)
+			}
+			if touched := len(store.touched) == 1; touched != tt.wantTouched {
+				t.Errorf("touched = %v, want %v", store.touched, tt.wantTouched)
+			}
+		})
+	}
+}
+
+func ptrTime(t time.Time) *time.Time {
+	return &t
+}
@@ -0,0 +1,28 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"financial-track/model"
+
+	"github.com/google/uuid"
+)
+
+type AuditUseCase struct {
+	repo AuditStore
+}
+
+func NewAuditUseCase(repo AuditStore) *AuditUseCase {
+	return &AuditUseCase{repo: repo}
+}
+
+func (a *AuditUseCase) ListAuditLog(ctx context.Context, userID string, entityType string, page, pageSize int) (model.PagedAuditLog, error) {
+	id, err := uuid.Parse(userID)
+	if err != nil {
+		return model.PagedAuditLog{}, errors.New("invalid user id")
+	}
+	if entityType != "" && entityType != model.AuditEntityExpense && entityType != model.AuditEntityUser {
+		return model.PagedAuditLog{}, errors.New("invalid entity type")
+	}
+	return a.repo.ListByUser(ctx, id, entityType, page, pageSize)
+}
@@ -1,23 +1,96 @@
 package usecase
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
 	"errors"
 	"financial-track/model"
-	"financial-track/repository"
+	"financial-track/utils"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
+var (
+	ErrExpenseNotFound        = errors.New("expense not found")
+	ErrExpenseVersionMismatch = errors.New("expense was modified by another request")
+)
+
+// ExpenseUseCase reads client dates (model.JSONTime) in the app location loc.
 type ExpenseUseCase struct {
-	repo *repository.ExpenseRepository
+	repo    ExpenseStore
+	clock   Clock
+	cursors CursorCodec
+	loc     *time.Location
 }
 
-func NewExpenseUseCase(repo *repository.ExpenseRepository) *ExpenseUseCase {
-	return &ExpenseUseCase{repo: repo}
+func NewExpenseUseCase(repo ExpenseStore, clock Clock, cursors CursorCodec, loc *time.Location) *ExpenseUseCase {
+	return &ExpenseUseCase{repo: repo, clock: clock, cursors: cursors, loc: loc}
+}
+
+func (e *ExpenseUseCase) CreateExpense(ctx context.Context, input model.CreateExpenseInput, actor model.AuditActor) (model.Expense, error) {
+	expense, err := e.newExpense(input)
+	if err != nil {
+		return model.Expense{}, err
+	}
+
+	if err := e.repo.Create(ctx, &expense, actor); err != nil {
+		return model.Expense{}, err
+	}
+	return expense, nil
 }
 
-func (e *ExpenseUseCase) CreateExpense(input model.CreateExpenseInput) (model.Expense, error) {
+// MaxBatchExpenses caps a batch create request.
+const MaxBatchExpenses = 100
+
+// CreateExpenses inserts the expenses in one transaction. Inputs must already
+// have passed binding validation; CreateExpense's rules apply.
+func (e *ExpenseUseCase) CreateExpenses(ctx context.Context, inputs []model.CreateExpenseInput, actor model.AuditActor) ([]model.Expense, error) {
+	if len(inputs) > MaxBatchExpenses {
+		return nil, fmt.Errorf("at most %d expenses per request", MaxBatchExpenses)
+	}
+
+	expenses := make([]model.Expense, 0, len(inputs))
+	for _, input := range inputs {
+		expense, err := e.newExpense(input)
+		if err != nil {
+			return nil, err
+		}
+		expenses = append(expenses, expense)
+	}
+
+	if err := e.repo.CreateBatch(ctx, expenses, actor); err != nil {
+		return nil, err
+	}
+	return expenses, nil
+}
+
+// ImportExpenses inserts any number of expenses in one transaction, all or
+// nothing. Used by the CLI import.
+func (e *ExpenseUseCase) ImportExpenses(ctx context.Context, inputs []model.CreateExpenseInput, actor model.AuditActor) ([]model.Expense, error) {
+	expenses := make([]model.Expense, 0, len(inputs))
+	for i, input := range inputs {
+		if !model.IsValidCategory(input.Category) {
+			return nil, fmt.Errorf("item %d: invalid category", i)
+		}
+		expense, err := e.newExpense(input)
+		if err != nil {
+			return nil, fmt.Errorf("item %d: %w", i, err)
+		}
+		expenses = append(expenses, expense)
+	}
+
+	if err := e.repo.CreateBatch(ctx, expenses, actor); err != nil {
+		return nil, err
+	}
+	return expenses, nil
+}
+
+func (e *ExpenseUseCase) newExpense(input model.CreateExpenseInput) (model.Expense, error) {
 	if input.Amount <= 0 {
 		return model.Expense{}, errors.New("invalid amount")
 	}
@@ -29,25 +102,402 @@ func (e *ExpenseUseCase) CreateExpense(input model.CreateExpenseInput) (model.Ex
 	if err != nil {
 		return model.Expense{}, errors.New("invalid user id")
 	}
+	tags, err := model.NormalizeTags(input.Tags)
+	if err != nil {
+		return model.Expense{}, err
+	}
 
-	expense := model.Expense{
+	return model.Expense{
 		UserID:        userId,
 		Amount:        input.Amount,
 		Description:   input.Description,
-		TransactionAt: input.TransactionAt.ToTime(),
+		TransactionAt: input.TransactionAt.In(e.loc),
 		Category:      input.Category,
+		Tags:          tags,
+	}, nil
+}
+
+// GetMensalSummary summarizes the user's expenses from the start of the current
+// month, in the app location, until now.
+func (e *ExpenseUseCase) GetMensalSummary(ctx context.Context, userID string, page, pageSize int, sort string) (model.PagedSummary, error) {
+	uid, err := uuid.Parse(userID)
+	if err != nil {
+		return model.PagedSummary{}, errors.New("invalid user id")
+	}
+	sortKeys, err := model.ParseExpenseSort(sort)
+	if err != nil {
+		return model.PagedSummary{}, err
 	}
+	endDate := e.clock.Now().In(e.loc)
+	startDate := time.Date(endDate.Year(), endDate.Month(), 1, 0, 0, 0, 0, e.loc)
+	paged, err := e.repo.GetSummary(ctx, uid, startDate, endDate, page, pageSize, sortKeys)
+	if err != nil {
+		return model.PagedSummary{}, err
+	}
+	return paged, nil
+}
 
-	if err := e.repo.Create(&expense); err != nil {
-		return model.Expense{}, err
+func (e *ExpenseUseCase) GetExpense(ctx context.Context, userID, expenseID string) (*model.Expense, error) {
+	uid, id, err := parseExpenseIDs(userID, expenseID)
+	if err != nil {
+		return nil, err
+	}
+	expense, err := e.repo.FindByID(ctx, uid, id)
+	if err != nil {
+		return nil, expenseRepoErr(err)
 	}
 	return expense, nil
 }
 
-func (e *ExpenseUseCase) GetMensalSummary(startDate, endDate time.Time, page, pageSize int) (model.PagedSummary, error) {
-	paged, err := e.repo.GetSummary(startDate, endDate, page, pageSize)
+// UpdateExpense changes the given fields if the current version satisfies match.
+func (e *ExpenseUseCase) UpdateExpense(ctx context.Context, userID, expenseID string, match model.VersionMatch, input model.UpdateExpenseInput, actor model.AuditActor) (*model.Expense, error) {
+	uid, id, err := parseExpenseIDs(userID, expenseID)
 	if err != nil {
-		return model.PagedSummary{}, err
+		return nil, err
 	}
-	return paged, nil
+
+	fields := map[string]interface{}{}
+	if input.Category != nil {
+		if !model.IsValidCategory(*input.Category) {
+			return nil, errors.New("invalid category")
+		}
+		fields["category"] = *input.Category
+	}
+	if input.Amount != nil {
+		if *input.Amount <= 0 {
+			return nil, errors.New("invalid amount")
+		}
+		fields["amount"] = *input.Amount
+	}
+	if input.Description != nil {
+		if *input.Description == "" {
+			return nil, errors.New("description cannot be empty")
+		}
+		fields["description"] = *input.Description
+	}
+	if input.TransactionAt != nil {
+		if input.TransactionAt.IsZero() {
+			return nil, errors.New("transactionAt cannot be empty")
+		}
+		fields["transaction_at"] = input.TransactionAt.In(e.loc)
+	}
+	var tags model.Tags
+	if input.Tags != nil {
+		if tags, err = model.NormalizeTags(*input.Tags); err != nil {
+			return nil, err
+		}
+		fields["tags"] = tags
+	}
+
+	expense, err := e.repo.Update(ctx, uid, id, match, func(expense *model.Expense) map[string]interface{} {
+		if input.Category != nil {
+			expense.Category = *input.Category
+		}
+		if input.Amount != nil {
+			expense.Amount = *input.Amount
+		}
+		if input.Description != nil {
+			expense.Description = *input.Description
+		}
+		if input.TransactionAt != nil {
+			expense.TransactionAt = input.TransactionAt.In(e.loc)
+		}
+		if input.Tags != nil {
+			expense.Tags = tags
+		}
+		return fields
+	}, actor)
+	if err != nil {
+		return nil, expenseRepoErr(err)
+	}
+	return expense, nil
+}
+
+// DeleteExpense moves the expense to the trash, out of the summaries.
+func (e *ExpenseUseCase) DeleteExpense(ctx context.Context, userID, expenseID string, match model.VersionMatch, actor model.AuditActor) error {
+	uid, id, err := parseExpenseIDs(userID, expenseID)
+	if err != nil {
+		return err
+	}
+	return expenseRepoErr(e.repo.SoftDelete(ctx, uid, id, match, actor))
+}
+
+func (e *ExpenseUseCase) ListTrash(ctx context.Context, userID string, page, pageSize int) (model.PagedSummary, error) {
+	uid, err := uuid.Parse(userID)
+	if err != nil {
+		return model.PagedSummary{}, errors.New("invalid user id")
+	}
+	return e.repo.ListTrash(ctx, uid, page, pageSize)
+}
+
+func (e *ExpenseUseCase) RestoreExpense(ctx context.Context, userID, expenseID string, actor model.AuditActor) (*model.Expense, error) {
+	uid, id, err := parseExpenseIDs(userID, expenseID)
+	if err != nil {
+		return nil, err
+	}
+	expense, err := e.repo.Restore(ctx, uid, id, actor)
+	if err != nil {
+		return nil, expenseRepoErr(err)
+	}
+	return expense, nil
+}
+
+// PurgeExpense permanently deletes a trashed expense.
+func (e *ExpenseUseCase) PurgeExpense(ctx context.Context, userID, expenseID string, actor model.AuditActor) error {
+	uid, id, err := parseExpenseIDs(userID, expenseID)
+	if err != nil {
+		return err
+	}
+	return expenseRepoErr(e.repo.Purge(ctx, uid, id, actor))
+}
+
+// PurgeExpiredTrash deletes expenses trashed longer than retention ago.
+func (e *ExpenseUseCase) PurgeExpiredTrash(ctx context.Context, retention time.Duration) (int64, error) {
+	return e.repo.PurgeTrashedBefore(ctx, e.clock.Now().Add(-retention), 500)
+}
+
+func parseExpenseIDs(userID, expenseID string) (uuid.UUID, uuid.UUID, error) {
+	uid, err := uuid.Parse(userID)
+	if err != nil {
+		return uuid.Nil, uuid.Nil, errors.New("invalid user id")
+	}
+	id, err := uuid.Parse(expenseID)
+	if err != nil {
+		return uuid.Nil, uuid.Nil, ErrExpenseNotFound
+	}
+	return uid, id, nil
+}
+
+func expenseRepoErr(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrExpenseNotFound
+	}
+	if errors.Is(err, ErrVersionMismatch) {
+		return ErrExpenseVersionMismatch
+	}
+	return err
+}
+
+// bulkLimit caps the expenses one bulk operation may touch.
+const bulkLimit = 1000
+
+// BulkExpenses applies the operation atomically to the user's expenses picked by
+// id or by filter.
+func (e *ExpenseUseCase) BulkExpenses(ctx context.Context, userID string, input model.BulkExpenseInput, actor model.AuditActor) (model.BulkExpenseResult, error) {
+	uid, err := uuid.Parse(userID)
+	if err != nil {
+		return model.BulkExpenseResult{}, errors.New("invalid user id")
+	}
+
+	if (len(input.IDs) == 0) == (input.Filter == nil) {
+		return model.BulkExpenseResult{}, errors.New("provide either ids or filter")
+	}
+	if input.Filter != nil && input.Filter.IsEmpty() {
+		return model.BulkExpenseResult{}, errors.New("filter must have at least one criterion")
+	}
+	if input.Filter != nil && input.Filter.Category != "" && !model.IsValidCategory(input.Filter.Category) {
+		return model.BulkExpenseResult{}, errors.New("invalid filter category")
+	}
+	if len(input.IDs) > bulkLimit {
+		return model.BulkExpenseResult{}, fmt.Errorf("at most %d ids per request", bulkLimit)
+	}
+
+	ids := make([]uuid.UUID, 0, len(input.IDs))
+	for _, raw := range input.IDs {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			return model.BulkExpenseResult{}, fmt.Errorf("invalid expense id: %s", raw)
+		}
+		ids = append(ids, id)
+	}
+
+	var change func(*model.Expense) map[string]interface{}
+	switch input.Operation {
+	case model.BulkRecategorize:
+		if !model.IsValidCategory(input.Category) {
+			return model.BulkExpenseResult{}, errors.New("invalid category")
+		}
+		change = func(expense *model.Expense) map[string]interface{} {
+			expense.Category = input.Category
+			return map[string]interface{}{"category": input.Category}
+		}
+	case model.BulkShiftDates:
+		if input.ShiftDays == 0 {
+			return model.BulkExpenseResult{}, errors.New("shiftDays must not be zero")
+		}
+		change = func(expense *model.Expense) map[string]interface{} {
+			expense.TransactionAt = expense.TransactionAt.AddDate(0, 0, input.ShiftDays)
+			return map[string]interface{}{"transaction_at": expense.TransactionAt}
+		}
+	case model.BulkAddTags, model.BulkRemoveTags:
+		if len(input.Tags) == 0 {
+			return model.BulkExpenseResult{}, errors.New("tags must not be empty")
+		}
+		tags, err := model.NormalizeTags(input.Tags)
+		if err != nil {
+			return model.BulkExpenseResult{}, err
+		}
+		change = func(expense *model.Expense) map[string]interface{} {
+			if input.Operation == model.BulkAddTags {
+				expense.Tags = expense.Tags.Add(tags)
+			} else {
+				expense.Tags = expense.Tags.Remove(tags)
+			}
+			return map[string]interface{}{"tags": expense.Tags}
+		}
+	case model.BulkDelete:
+		change = nil
+	default:
+		return model.BulkExpenseResult{}, errors.New("invalid operation")
+	}
+
+	filter := input.Filter
+	if filter != nil {
+		local := e.localFilter(*filter)
+		filter = &local
+	}
+	results, err := e.repo.Bulk(ctx, uid, ids, filter, bulkLimit, change, input.DryRun, actor)
+	if err != nil {
+		if errors.Is(err, ErrBulkTooLarge) {
+			return model.BulkExpenseResult{}, fmt.Errorf("filter matches more than %d expenses", bulkLimit)
+		}
+		return model.BulkExpenseResult{}, err
+	}
+
+	affected := 0
+	for _, r := range results {
+		if r.Status == model.BulkItemApplied {
+			affected++
+		}
+	}
+
+	return model.BulkExpenseResult{
+		Operation: input.Operation,
+		DryRun:    input.DryRun,
+		Affected:  affected,
+		Results:   results,
+	}, nil
+}
+
+// ListExpenses pages the user's expenses by keyset in sort order (newest first
+// by default). An empty cursor starts at the beginning.
+func (e *ExpenseUseCase) ListExpenses(ctx context.Context, userID string, filter model.ExpenseFilter, sort string, cursor string, limit int, includeTotal bool) (model.ExpenseCursorPage, error) {
+	uid, err := uuid.Parse(userID)
+	if err != nil {
+		return model.ExpenseCursorPage{}, errors.New("invalid user id")
+	}
+	if filter.Category != "" && !model.IsValidCategory(filter.Category) {
+		return model.ExpenseCursorPage{}, errors.New("invalid category")
+	}
+	sortKeys, err := model.ParseExpenseSort(sort)
+	if err != nil {
+		return model.ExpenseCursorPage{}, err
+	}
+	filter = e.localFilter(filter)
+
+	var position *model.ExpenseCursor
+	var values []interface{}
+	if cursor != "" {
+		position = &model.ExpenseCursor{}
+		if err := e.cursors.Decode(cursor, position); err != nil {
+			return model.ExpenseCursorPage{}, err
+		}
+		if position.Sort != model.SortString(sortKeys) || len(position.Values) != len(sortKeys) {
+			return model.ExpenseCursorPage{}, errors.New("cursor does not match the requested sort")
+		}
+		if position.Filter != filterHash(filter) {
+			return model.ExpenseCursorPage{}, errors.New("cursor does not match the requested filter")
+		}
+		for i, key := range sortKeys {
+			value, err := model.DecodeSortValue(key.Field, position.Values[i])
+			if err != nil {
+				return model.ExpenseCursorPage{}, utils.ErrInvalidCursor
+			}
+			values = append(values, value)
+		}
+	}
+
+	backward := position != nil && position.Direction == model.CursorPrev
+	expenses, hasMore, err := e.repo.ListByCursor(ctx, uid, filter, sortKeys, values, backward, limit)
+	if err != nil {
+		return model.ExpenseCursorPage{}, err
+	}
+
+	page := model.ExpenseCursorPage{
+		Data:    make([]model.ExpenseResponse, 0, len(expenses)),
+		PerPage: limit,
+	}
+	for _, expense := range expenses {
+		page.Data = append(page.Data, expense.Response())
+	}
+
+	if len(expenses) > 0 {
+		first, last := expenses[0], expenses[len(expenses)-1]
+		// Going back there is always a next page (where we came from); going
+		// forward past the first page there is always a previous one.
+		if hasMore || backward {
+			if page.NextCursor, err = e.encodeCursor(last, sortKeys, filter, model.CursorNext); err != nil {
+				return model.ExpenseCursorPage{}, err
+			}
+		}
+		if (backward && hasMore) || (!backward && position != nil) {
+			if page.PrevCursor, err = e.encodeCursor(first, sortKeys, filter, model.CursorPrev); err != nil {
+				return model.ExpenseCursorPage{}, err
+			}
+		}
+	}
+
+	if includeTotal {
+		total, err := e.repo.Count(ctx, uid, filter)
+		if err != nil {
+			return model.ExpenseCursorPage{}, err
+		}
+		page.TotalItems = &total
+	}
+
+	return page, nil
+}
+
+// localFilter reads the filter dates in the app location.
+func (e *ExpenseUseCase) localFilter(filter model.ExpenseFilter) model.ExpenseFilter {
+	if !filter.From.IsZero() {
+		filter.From = model.JSONTime{Time: filter.From.In(e.loc)}
+	}
+	if !filter.To.IsZero() {
+		filter.To = model.JSONTime{Time: filter.To.In(e.loc)}
+	}
+	return filter
+}
+
+func (e *ExpenseUseCase) encodeCursor(expense model.Expense, sortKeys []model.SortKey, filter model.ExpenseFilter, direction model.CursorDirection) (string, error) {
+	values := make([]interface{}, 0, len(sortKeys))
+	for _, key := range sortKeys {
+		values = append(values, expense.SortValue(key.Field))
+	}
+	return e.cursors.Encode(model.ExpenseCursor{
+		Sort:      model.SortString(sortKeys),
+		Filter:    filterHash(filter),
+		Values:    values,
+		Direction: direction,
+	})
+}
+
+// filterHash identifies the filter a cursor was issued for. filter must already
+// be in the application location (see localFilter).
+func filterHash(filter model.ExpenseFilter) string {
+	var from, to string
+	if !filter.From.IsZero() {
+		from = filter.From.UTC().Format(time.RFC3339)
+	}
+	if !filter.To.IsZero() {
+		to = filter.To.UTC().Format(time.RFC3339)
+	}
+	sum := sha256.Sum256([]byte(strings.Join([]string{
+		string(filter.Category),
+		from,
+		to,
+		filter.DescriptionContains,
+		strings.ToLower(strings.TrimSpace(filter.Tag)),
+	}, "\x00")))
+	return base64.RawURLEncoding.EncodeToString(sum[:12])
 }
@@ -0,0 +1,120 @@
+package usecase
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"financial-track/model"
+
+	"github.com/google/uuid"
+)
+
+func TestBulkExpenses(t *testing.T) {
+	lunch := model.Expense{ID: uuid.New(), Category: model.Category("OTHERS"), Amount: 20, Description: "lunch", Tags: model.Tags{"work"}, Version: 1}
+	missing := uuid.New()
+
+	tests := []struct {
+		name         string
+		input        model.BulkExpenseInput
+		wantAffected int
+		wantNotFound int
+		wantStored   model.Expense
+		wantTrashed  bool
+	}{
+		{
+			name:         "recategorize dry run",
+			input:        model.BulkExpenseInput{IDs: []string{lunch.ID.String()}, Operation: model.BulkRecategorize, Category: model.Category("FOOD"), DryRun: true},
+			wantAffected: 1,
+			wantStored:   lunch,
+		},
+		{
+			name:         "recategorize",
+			input:        model.BulkExpenseInput{IDs: []string{lunch.ID.String()}, Operation: model.BulkRecategorize, Category: model.Category("FOOD")},
+			wantAffected: 1,
+			wantStored:   model.Expense{ID: lunch.ID, Category: model.Category("FOOD"), Amount: 20, Description: "lunch", Tags: model.Tags{"work"}, Version: 2},
+		},
+		{
+			name:         "add tags",
+			input:        model.BulkExpenseInput{IDs: []string{lunch.ID.String()}, Operation: model.BulkAddTags, Tags: []string{" Trip "}},
+			wantAffected: 1,
+			wantStored:   model.Expense{ID: lunch.ID, Category: lunch.Category, Amount: 20, Description: "lunch", Tags: model.Tags{"trip", "work"}, Version: 2},
+		},
+		{
+			name:         "delete reports unknown ids",
+			input:        model.BulkExpenseInput{IDs: []string{lunch.ID.String(), missing.String()}, Operation: model.BulkDelete},
+			wantAffected: 1,
+			wantNotFound: 1,
+			wantStored:   lunch,
+			wantTrashed:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stored := lunch
+			stored.Tags = append(model.Tags{}, lunch.Tags...)
+			store := &fakeExpenseStore{expenses: []model.Expense{stored}}
+			uc := newTestExpenseUseCase(store, time.Now())
+
+			result, err := uc.BulkExpenses(context.Background(), expenseTestUser, tt.input, model.AuditActor{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Affected != tt.wantAffected || result.DryRun != tt.input.DryRun || result.Operation != tt.input.Operation {
+				t.Fatalf("result = %+v", result)
+			}
+			notFound := 0
+			for _, item := range result.Results {
+				switch item.Status {
+				case model.BulkItemNotFound:
+					notFound++
+				case model.BulkItemApplied:
+					if item.Before == nil || (tt.input.Operation != model.BulkDelete) != (item.After != nil) {
+						t.Errorf("item %+v misses its before/after report", item)
+					}
+				}
+			}
+			if notFound != tt.wantNotFound {
+				t.Errorf("not found = %d, want %d", notFound, tt.wantNotFound)
+			}
+
+			got := store.expenses[0]
+			if got.Category != tt.wantStored.Category || got.Version != tt.wantStored.Version || strings.Join(got.Tags, ",") != strings.Join(tt.wantStored.Tags, ",") {
+				t.Errorf("stored = %+v, want %+v", got, tt.wantStored)
+			}
+			if trashed := len(store.trashed) == 1; trashed != tt.wantTrashed {
+				t.Errorf("trashed = %v, want %v", store.trashed, tt.wantTrashed)
+			}
+		})
+	}
+}
+
+func TestBulkExpensesValidation(t *testing.T) {
+	id := uuid.NewString()
+	food := &model.ExpenseFilter{Category: model.Category("FOOD")}
+
+	tests := []struct {
+		name  string
+		input model.BulkExpenseInput
+		want  string
+	}{
+		{name: "ids and filter", input: model.BulkExpenseInput{IDs: []string{id}, Filter: food, Operation: model.BulkDelete}, want: "either ids or filter"},
+		{name: "neither ids nor filter", input: model.BulkExpenseInput{Operation: model.BulkDelete}, want: "either ids or filter"},
+		{name: "empty filter", input: model.BulkExpenseInput{Filter: &model.ExpenseFilter{}, Operation: model.BulkDelete}, want: "at least one criterion"},
+		{name: "zero shift", input: model.BulkExpenseInput{IDs: []string{id}, Operation: model.BulkShiftDates}, want: "shiftDays"},
+		{name: "no tags", input: model.BulkExpenseInput{IDs: []string{id}, Operation: model.BulkRemoveTags}, want: "tags must not be empty"},
+		{name: "unknown operation", input: model.BulkExpenseInput{IDs: []string{id}, Operation: "archive"}, want: "invalid operation"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &fakeExpenseStore{}
+			uc := newTestExpenseUseCase(store, time.Now())
+
+			_, err := uc.BulkExpenses(context.Background(), expenseTestUser, tt.input, model.AuditActor{})
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("BulkExpenses = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,333 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"financial-track/model"
+	"financial-track/utils"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const expenseTestUser = "0b6f2a4e-8f0e-4a47-9b59-2f3c1c7d9e10"
+
+// brt is a fixed UTC-3 zone so tests do not depend on the host tzdata.
+var brt = time.FixedZone("BRT", -3*60*60)
+
+func newTestExpenseUseCase(store *fakeExpenseStore, now time.Time) *ExpenseUseCase {
+	cursors := utils.NewCursorCodec([]byte("0123456789abcdef0123456789abcdef"))
+	return NewExpenseUseCase(store, &fixedClock{now: now}, cursors, brt)
+}
+
+func wallClock(s string) model.JSONTime {
+	t, err := time.Parse(model.LayoutYYYYMMDDHHMM, s)
+	if err != nil {
+		panic(err)
+	}
+	return model.JSONTime{Time: t}
+}
+
+func TestCreateExpense(t *testing.T) {
+	valid := model.CreateExpenseInput{
+		UserID:        expenseTestUser,
+		Category:      model.Category("FOOD"),
+		Amount:        10,
+		Description:   "lunch",
+		TransactionAt: wallClock("2025-10-05 12:30"),
+		Tags:          []string{" Work ", "trip", "work"},
+	}
+
+	tests := []struct {
+		name    string
+		change  func(*model.CreateExpenseInput)
+		wantErr string
+	}{
+		{name: "valid"},
+		{name: "zero amount", change: func(in *model.CreateExpenseInput) { in.Amount = 0 }, wantErr: "invalid amount"},
+		{name: "empty description", change: func(in *model.CreateExpenseInput) { in.Description = "" }, wantErr: "description cannot be empty"},
+		{name: "invalid user", change: func(in *model.CreateExpenseInput) { in.UserID = "nope" }, wantErr: "invalid user id"},
+		{name: "empty tag", change: func(in *model.CreateExpenseInput) { in.Tags = []string{" "} }, wantErr: "tags cannot be empty"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &fakeExpenseStore{}
+			uc := newTestExpenseUseCase(store, time.Now())
+			input := valid
+			if tt.change != nil {
+				tt.change(&input)
+			}
+
+			expense, err := uc.CreateExpense(context.Background(), input, model.AuditActor{})
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want %q", err, tt.wantErr)
+				}
+				if len(store.created) != 0 {
+					t.Fatal("invalid expense reached the store")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := time.Date(2025, 10, 5, 12, 30, 0, 0, brt)
+			if !expense.TransactionAt.Equal(want) {
+				t.Errorf("TransactionAt = %s, want %s", expense.TransactionAt, want)
+			}
+			if strings.Join(expense.Tags, ",") != "trip,work" {
+				t.Errorf("Tags = %v, want [trip work]", expense.Tags)
+			}
+		})
+	}
+}
+
+func TestUpdateExpenseMapsStoreErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		expenseID string
+		storeErr  error
+		want      error
+	}{
+		{name: "not found", expenseID: uuid.NewString(), storeErr: gorm.ErrRecordNotFound, want: ErrExpenseNotFound},
+		{name: "malformed id", expenseID: "nope", want: ErrExpenseNotFound},
+		{name: "version mismatch", expenseID: uuid.NewString(), storeErr: ErrVersionMismatch, want: ErrExpenseVersionMismatch},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := newTestExpenseUseCase(&fakeExpenseStore{updateErr: tt.storeErr}, time.Now())
+			description := "dinner"
+
+			_, err := uc.UpdateExpense(context.Background(), expenseTestUser, tt.expenseID, model.VersionMatch{}, model.UpdateExpenseInput{Description: &description}, model.AuditActor{})
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("UpdateExpense = %v, want %v", err, tt.want)
+			}
+			if err := uc.DeleteExpense(context.Background(), expenseTestUser, tt.expenseID, model.VersionMatch{}, model.AuditActor{}); !errors.Is(err, tt.want) {
+				t.Fatalf("DeleteExpense = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetMensalSummaryPeriodUsesClockAndLocation(t *testing.T) {
+	tests := []struct {
+		name      string
+		now       time.Time
+		wantStart time.Time
+	}{
+		{
+			name:      "middle of the month",
+			now:       time.Date(2025, 10, 15, 12, 0, 0, 0, time.UTC),
+			wantStart: time.Date(2025, 10, 1, 0, 0, 0, 0, brt),
+		},
+		{
+			// 03-01 02:00 UTC is still 02-28 in the app location.
+			name:      "new month in UTC only",
+			now:       time.Date(2025, 3, 1, 2, 0, 0, 0, time.UTC),
+			wantStart: time.Date(2025, 2, 1, 0, 0, 0, 0, brt),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &fakeExpenseStore{}
+			uc := newTestExpenseUseCase(store, tt.now)
+
+			if _, err := uc.GetMensalSummary(context.Background(), expenseTestUser, 1, 15, ""); err != nil {
+				t.Fatal(err)
+			}
+			if !store.summaryStart.Equal(tt.wantStart) || !store.summaryEnd.Equal(tt.now) {
+				t.Fatalf("period = %s..%s, want %s..%s", store.summaryStart, store.summaryEnd, tt.wantStart, tt.now)
+			}
+		})
+	}
+}
+
+func TestExpenseFiltersAreInterpretedInLocation(t *testing.T) {
+	store := &fakeExpenseStore{}
+	uc := newTestExpenseUseCase(store, time.Now())
+	filter := model.ExpenseFilter{From: wallClock("2025-10-01 00:00")}
+	wantFrom := time.Date(2025, 10, 1, 0, 0, 0, 0, brt)
+
+	if _, err := uc.ListExpenses(context.Background(), expenseTestUser, filter, "", "", 10, false); err != nil {
+		t.Fatal(err)
+	}
+	if !store.listFilter.From.Time.Equal(wantFrom) || !store.listFilter.To.IsZero() {
+		t.Fatalf("list filter = %+v, want from %s and no upper bound", store.listFilter, wantFrom)
+	}
+
+	input := model.BulkExpenseInput{Operation: model.BulkDelete, Filter: &filter}
+	if _, err := uc.BulkExpenses(context.Background(), expenseTestUser, input, model.AuditActor{}); err != nil {
+		t.Fatal(err)
+	}
+	if store.bulkFilter == nil || !store.bulkFilter.From.Time.Equal(wantFrom) {
+		t.Fatalf("bulk filter = %+v, want from %s", store.bulkFilter, wantFrom)
+	}
+}
+
+func TestSummarySortWhitelist(t *testing.T) {
+	tests := []struct {
+		sort    string
+		want    string
+		wantErr string
+	}{
+		{sort: "", want: "-transactionAt,-id"},
+		{sort: "amount, -createdAt", want: "amount,-createdAt,id"},
+		{sort: "+description,-category", want: "description,-category,id"},
+		{sort: "password", wantErr: "invalid sort field"},
+		{sort: "amount;DROP TABLE expenses", wantErr: "invalid sort field"},
+		{sort: "transaction_at", wantErr: "invalid sort field"},
+		{sort: "amount,-amount", wantErr: "duplicate sort field"},
+		{sort: "amount,category,description,createdAt", wantErr: "too many sort fields"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.sort, func(t *testing.T) {
+			store := &fakeExpenseStore{}
+			uc := newTestExpenseUseCase(store, time.Now())
+
+			_, err := uc.GetMensalSummary(context.Background(), expenseTestUser, 1, 10, tt.sort)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want %q", err, tt.wantErr)
+				}
+				if store.summarySort != nil {
+					t.Error("store queried with a rejected sort")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := model.SortString(store.summarySort); got != tt.want {
+				t.Errorf("sort = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPurgeExpiredTrashUsesClock(t *testing.T) {
+	now := time.Date(2025, 10, 15, 12, 0, 0, 0, time.UTC)
+	store := &fakeExpenseStore{}
+	uc := newTestExpenseUseCase(store, now)
+
+	if _, err := uc.PurgeExpiredTrash(context.Background(), 30*24*time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if want := now.AddDate(0, 0, -30); !store.purgeCutoff.Equal(want) {
+		t.Fatalf("cutoff = %s, want %s", store.purgeCutoff, want)
+	}
+}
+
+func TestRestoreAndPurgeExpense(t *testing.T) {
+	tests := []struct {
+		name      string
+		trashed   bool
+		purge     bool
+		expenseID string
+		wantErr   error
+		wantLeft  int
+	}{
+		{name: "restore trashed", trashed: true, wantLeft: 1},
+		{name: "restore live", wantErr: ErrExpenseNotFound, wantLeft: 1},
+		{name: "purge trashed", trashed: true, purge: true},
+		{name: "purge live", purge: true, wantErr: ErrExpenseNotFound, wantLeft: 1},
+		{name: "malformed id", expenseID: "nope", wantErr: ErrExpenseNotFound, wantLeft: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expense := model.Expense{ID: uuid.New(), Description: "lunch"}
+			store := &fakeExpenseStore{expenses: []model.Expense{expense}}
+			if tt.trashed {
+				store.trashed = []uuid.UUID{expense.ID}
+			}
+			uc := newTestExpenseUseCase(store, time.Now())
+			id := tt.expenseID
+			if id == "" {
+				id = expense.ID.String()
+			}
+
+			var err error
+			if tt.purge {
+				err = uc.PurgeExpense(context.Background(), expenseTestUser, id, model.AuditActor{})
+			} else {
+				_, err = uc.RestoreExpense(context.Background(), expenseTestUser, id, model.AuditActor{})
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if len(store.expenses) != tt.wantLeft || (err == nil && len(store.trashed) != 0) {
+				t.Errorf("expenses = %d, trashed = %v", len(store.expenses), store.trashed)
+			}
+		})
+	}
+}
+
+func TestListExpensesCursorRoundTrip(t *testing.T) {
+	page := []model.Expense{
+		{ID: uuid.New(), Description: "b", TransactionAt: time.Date(2025, 10, 9, 12, 0, 0, 0, time.UTC)},
+		{ID: uuid.New(), Description: "a", TransactionAt: time.Date(2025, 10, 8, 12, 0, 0, 0, time.UTC)},
+	}
+	store := &fakeExpenseStore{listResult: page, listHasMore: true}
+	uc := newTestExpenseUseCase(store, time.Now())
+	ctx := context.Background()
+
+	first, err := uc.ListExpenses(ctx, expenseTestUser, model.ExpenseFilter{}, "", "", 2, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.NextCursor == "" || first.PrevCursor != "" {
+		t.Fatalf("first page cursors = next %q, prev %q, want only next", first.NextCursor, first.PrevCursor)
+	}
+	if store.listValues != nil || store.listBackward {
+		t.Fatalf("first page queried from %v (backward %v)", store.listValues, store.listBackward)
+	}
+
+	second, err := uc.ListExpenses(ctx, expenseTestUser, model.ExpenseFilter{}, "", first.NextCursor, 2, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if store.listBackward || len(store.listValues) == 0 || !store.listValues[0].(time.Time).Equal(page[1].TransactionAt) {
+		t.Fatalf("next page queried from %v (backward %v), want after %s", store.listValues, store.listBackward, page[1].TransactionAt)
+	}
+	if second.PrevCursor == "" {
+		t.Fatal("second page without prev cursor")
+	}
+
+	store.listHasMore = false
+	if _, err := uc.ListExpenses(ctx, expenseTestUser, model.ExpenseFilter{}, "", second.PrevCursor, 2, false); err != nil {
+		t.Fatal(err)
+	}
+	if !store.listBackward || !store.listValues[0].(time.Time).Equal(page[0].TransactionAt) {
+		t.Fatalf("prev page queried from %v (backward %v), want before %s", store.listValues, store.listBackward, page[0].TransactionAt)
+	}
+}
+
+func TestListExpensesRejectsForeignCursors(t *testing.T) {
+	store := &fakeExpenseStore{listResult: []model.Expense{{ID: uuid.New(), Amount: 10}}, listHasMore: true}
+	uc := newTestExpenseUseCase(store, time.Now())
+	ctx := context.Background()
+
+	byAmount, err := uc.ListExpenses(ctx, expenseTestUser, model.ExpenseFilter{}, "-amount", "", 1, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := uc.ListExpenses(ctx, expenseTestUser, model.ExpenseFilter{}, "", byAmount.NextCursor, 1, false); err == nil {
+		t.Error("cursor accepted with a different sort")
+	}
+	if _, err := uc.ListExpenses(ctx, expenseTestUser, model.ExpenseFilter{}, "-amount", byAmount.NextCursor+"x", 1, false); !errors.Is(err, utils.ErrInvalidCursor) {
+		t.Errorf("tampered cursor = %v, want %v", err, utils.ErrInvalidCursor)
+	}
+
+	food := model.ExpenseFilter{Category: model.Category("FOOD")}
+	if _, err := uc.ListExpenses(ctx, expenseTestUser, food, "-amount", byAmount.NextCursor, 1, false); err == nil || !strings.Contains(err.Error(), "filter") {
+		t.Errorf("cursor with a different filter = %v, want a filter mismatch", err)
+	}
+
+	other := NewExpenseUseCase(store, &fixedClock{}, utils.NewCursorCodec([]byte("fedcba9876543210fedcba9876543210")), brt)
+	if _, err := other.ListExpenses(ctx, expenseTestUser, model.ExpenseFilter{}, "-amount", byAmount.NextCursor, 1, false); !errors.Is(err, utils.ErrInvalidCursor) {
+		t.Errorf("cursor signed with another secret = %v, want %v", err, utils.ErrInvalidCursor)
+	}
+}
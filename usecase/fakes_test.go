@@ -0,0 +1,544 @@
+package usecase
+
+import (
+	"context"
+	"financial-track/loginguard"
+	"financial-track/mailer"
+	"financial-track/model"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// In-memory fakes of the use case ports. Each implements only what tests observe.
+
+type fixedClock struct {
+	now time.Time
+}
+
+func (c *fixedClock) Now() time.Time {
+	return c.now
+}
+
+// fakeExpenseStore records created expenses and query arguments; ListByCursor
+// returns listResult and listHasMore.
+type fakeExpenseStore struct {
+	ExpenseStore
+
+	created   []model.Expense
+	updateErr error
+
+	// expenses are the rows Bulk selects by id; trashed records its deletions.
+	expenses []model.Expense
+	trashed  []uuid.UUID
+
+	summaryStart, summaryEnd time.Time
+	summarySort              []model.SortKey
+	listFilter               model.ExpenseFilter
+	listValues               []interface{}
+	listBackward             bool
+	listResult               []model.Expense
+	listHasMore              bool
+	bulkFilter               *model.ExpenseFilter
+	purgeCutoff              time.Time
+}
+
+func (s *fakeExpenseStore) Create(ctx context.Context, expense *model.Expense, actor model.AuditActor) error {
+	expense.ID = uuid.New()
+	s.created = append(s.created, *expense)
+	return nil
+}
+
+func (s *fakeExpenseStore) CreateBatch(ctx context.Context, expenses []model.Expense, actor model.AuditActor) error {
+	s.created = append(s.created, expenses...)
+	return nil
+}
+
+func (s *fakeExpenseStore) GetSummary(ctx context.Context, userID uuid.UUID, startDate, endDate time.Time, page, pageSize int, sort []model.SortKey) (model.PagedSummary, error) {
+	s.summaryStart, s.summaryEnd = startDate, endDate
+	s.summarySort = sort
+	return model.PagedSummary{}, nil
+}
+
+func (s *fakeExpenseStore) Update(ctx context.Context, userID, id uuid.UUID, match model.VersionMatch, change func(*model.Expense) map[string]interface{}, actor model.AuditActor) (*model.Expense, error) {
+	if s.updateErr != nil {
+		return nil, s.updateErr
+	}
+	expense := &model.Expense{ID: id, UserID: userID}
+	change(expense)
+	return expense, nil
+}
+
+func (s *fakeExpenseStore) SoftDelete(ctx context.Context, userID, id uuid.UUID, match model.VersionMatch, actor model.AuditActor) error {
+	return s.updateErr
+}
+
+// Restore and Purge only find expenses listed in trashed.
+func (s *fakeExpenseStore) Restore(ctx context.Context, userID, id uuid.UUID, actor model.AuditActor) (*model.Expense, error) {
+	if !s.untrash(id) {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &s.expenses[s.indexOf(id)], nil
+}
+
+func (s *fakeExpenseStore) Purge(ctx context.Context, userID, id uuid.UUID, actor model.AuditActor) error {
+	if !s.untrash(id) {
+		return gorm.ErrRecordNotFound
+	}
+	i := s.indexOf(id)
+	s.expenses = append(s.expenses[:i], s.expenses[i+1:]...)
+	return nil
+}
+
+func (s *fakeExpenseStore) untrash(id uuid.UUID) bool {
+	for i, trashed := range s.trashed {
+		if trashed == id {
+			s.trashed = append(s.trashed[:i], s.trashed[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+func (s *fakeExpenseStore) PurgeTrashedBefore(ctx context.Context, cutoff time.Time, batchSize int) (int64, error) {
+	s.purgeCutoff = cutoff
+	return 0, nil
+}
+
+func (s *fakeExpenseStore) Bulk(ctx context.Context, userID uuid.UUID, ids []uuid.UUID, filter *model.ExpenseFilter, limit int, change func(*model.Expense) map[string]interface{}, dryRun bool, actor model.AuditActor) ([]model.BulkItemResult, error) {
+	s.bulkFilter = filter
+	var results []model.BulkItemResult
+	for _, id := range ids {
+		i := s.indexOf(id)
+		if i < 0 {
+			results = append(results, model.BulkItemResult{ID: id.String(), Status: model.BulkItemNotFound})
+			continue
+		}
+		expense := s.expenses[i]
+		expense.Tags = append(model.Tags{}, expense.Tags...)
+		before := expense.Response()
+		item := model.BulkItemResult{ID: id.String(), Status: model.BulkItemApplied, Before: &before}
+		if change != nil {
+			change(&expense)
+			expense.Version++
+			after := expense.Response()
+			item.After = &after
+		}
+		if !dryRun {
+			if change == nil {
+				s.trashed = append(s.trashed, id)
+			} else {
+				s.expenses[i] = expense
+			}
+		}
+		results = append(results, item)
+	}
+	return results, nil
+}
+
+func (s *fakeExpenseStore) indexOf(id uuid.UUID) int {
+	for i, expense := range s.expenses {
+		if expense.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+func (s *fakeExpenseStore) ListByCursor(ctx context.Context, userID uuid.UUID, filter model.ExpenseFilter, sort []model.SortKey, values []interface{}, backward bool, limit int) ([]model.Expense, bool, error) {
+	s.listFilter, s.listValues, s.listBackward = filter, values, backward
+	return s.listResult, s.listHasMore, nil
+}
+
+// fakeUserStore indexes users by email.
+type fakeUserStore struct {
+	UserStore
+
+	users   map[string]*model.User
+	deleted []uuid.UUID
+}
+
+func (s *fakeUserStore) FindByEmail(ctx context.Context, email string) (*model.User, error) {
+	if user, ok := s.users[email]; ok {
+		copied := *user
+		return &copied, nil
+	}
+	return nil, nil
+}
+
+func (s *fakeUserStore) FindByID(ctx context.Context, id string) (*model.User, error) {
+	for _, user := range s.users {
+		if user.ID.String() == id {
+			copied := *user
+			return &copied, nil
+		}
+	}
+	return nil, nil
+}
+
+func (s *fakeUserStore) byID(id uuid.UUID) *model.User {
+	for _, user := range s.users {
+		if user.ID == id {
+			return user
+		}
+	}
+	return nil
+}
+
+func (s *fakeUserStore) UpdatePassword(ctx context.Context, id uuid.UUID, hashedPassword string) error {
+	s.byID(id).Password = hashedPassword
+	return nil
+}
+
+func (s *fakeUserStore) MarkEmailVerified(ctx context.Context, id uuid.UUID) error {
+	now := time.Now()
+	s.byID(id).EmailVerifiedAt = &now
+	return nil
+}
+
+func (s *fakeUserStore) Update(ctx context.Context, id uuid.UUID, fields map[string]interface{}, actor model.AuditActor) error {
+	user := s.byID(id)
+	if name, ok := fields["name"].(string); ok {
+		user.Name = name
+	}
+	if email, ok := fields["email"].(string); ok {
+		delete(s.users, user.Email)
+		user.Email = email
+		s.users[email] = user
+	}
+	if verifiedAt, ok := fields["email_verified_at"]; ok {
+		user.EmailVerifiedAt, _ = verifiedAt.(*time.Time)
+	}
+	return nil
+}
+
+func (s *fakeUserStore) Delete(ctx context.Context, id uuid.UUID, actor model.AuditActor) error {
+	delete(s.users, s.byID(id).Email)
+	s.deleted = append(s.deleted, id)
+	return nil
+}
+
+// fakeTokenStore mirrors TokenRepository rotation: only an unrevoked refresh
+// token can rotate.
+type fakeTokenStore struct {
+	TokenStore
+
+	mu     sync.Mutex
+	tokens map[string]*model.RefreshToken
+}
+
+func newFakeTokenStore() *fakeTokenStore {
+	return &fakeTokenStore{tokens: map[string]*model.RefreshToken{}}
+}
+
+func (s *fakeTokenStore) CreateRefreshToken(ctx context.Context, token *model.RefreshToken) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	token.ID = uuid.New()
+	copied := *token
+	s.tokens[token.TokenHash] = &copied
+	return nil
+}
+
+func (s *fakeTokenStore) FindRefreshTokenByHash(ctx context.Context, hash string) (*model.RefreshToken, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	token, ok := s.tokens[hash]
+	if !ok {
+		return nil, nil
+	}
+	copied := *token
+	return &copied, nil
+}
+
+func (s *fakeTokenStore) RotateRefreshToken(ctx context.Context, current *model.RefreshToken, next *model.RefreshToken) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stored := s.tokens[current.TokenHash]
+	if stored == nil || stored.RevokedAt != nil {
+		return gorm.ErrRecordNotFound
+	}
+	next.ID = uuid.New()
+	now := time.Now()
+	stored.RevokedAt = &now
+	stored.ReplacedByID = &next.ID
+	copied := *next
+	s.tokens[next.TokenHash] = &copied
+	return nil
+}
+
+// fakeSessionStore records created and revoked sessions.
+type fakeSessionStore struct {
+	SessionStore
+
+	created    []model.Session
+	revoked    []uuid.UUID
+	touched    []uuid.UUID
+	revokedAll []string // user ids, with the kept session after a colon
+}
+
+func (s *fakeSessionStore) Create(ctx context.Context, session *model.Session) error {
+	session.ID = uuid.New()
+	s.created = append(s.created, *session)
+	return nil
+}
+
+func (s *fakeSessionStore) FindByID(ctx context.Context, id string) (*model.Session, error) {
+	for _, session := range s.created {
+		if session.ID.String() == id {
+			return &session, nil
+		}
+	}
+	return nil, nil
+}
+
+func (s *fakeSessionStore) ListActiveByUser(ctx context.Context, userID string) ([]model.Session, error) {
+	var sessions []model.Session
+	for _, session := range s.created {
+		if session.UserID.String() == userID && session.RevokedAt == nil {
+			sessions = append(sessions, session)
+		}
+	}
+	return sessions, nil
+}
+
+func (s *fakeSessionStore) Touch(ctx context.Context, id uuid.UUID) error {
+	s.touched = append(s.touched, id)
+	return nil
+}
+
+func (s *fakeSessionStore) Revoke(ctx context.Context, id uuid.UUID) error {
+	s.revoked = append(s.revoked, id)
+	for i := range s.created {
+		if s.created[i].ID == id {
+			now := time.Now()
+			s.created[i].RevokedAt = &now
+		}
+	}
+	return nil
+}
+
+func (s *fakeSessionStore) RevokeAllExcept(ctx context.Context, userID string, keepID string) error {
+	s.revokedAll = append(s.revokedAll, userID+":"+keepID)
+	return nil
+}
+
+func (s *fakeSessionStore) RevokeAllByUser(ctx context.Context, userID uuid.UUID) error {
+	s.revokedAll = append(s.revokedAll, userID.String())
+	return nil
+}
+
+// fakeUserTokenStore keeps email tokens by hash and consumes each one once.
+type fakeUserTokenStore struct {
+	tokens map[string]*model.UserToken
+}
+
+func newFakeUserTokenStore() *fakeUserTokenStore {
+	return &fakeUserTokenStore{tokens: map[string]*model.UserToken{}}
+}
+
+func (s *fakeUserTokenStore) Create(ctx context.Context, token *model.UserToken) error {
+	token.ID = uuid.New()
+	copied := *token
+	s.tokens[token.TokenHash] = &copied
+	return nil
+}
+
+func (s *fakeUserTokenStore) FindByHash(ctx context.Context, hash string, purpose model.UserTokenPurpose) (*model.UserToken, error) {
+	token, ok := s.tokens[hash]
+	if !ok || token.Purpose != purpose {
+		return nil, nil
+	}
+	copied := *token
+	return &copied, nil
+}
+
+func (s *fakeUserTokenStore) Consume(ctx context.Context, id uuid.UUID) (bool, error) {
+	for _, token := range s.tokens {
+		if token.ID == id && token.UsedAt == nil {
+			now := time.Now()
+			token.UsedAt = &now
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// fakeTwoFactorStore mirrors TwoFactorRepository: each TOTP step and recovery
+// code is accepted once.
+type fakeTwoFactorStore struct {
+	TwoFactorStore
+
+	lastStep      map[uuid.UUID]int64
+	recoveryCodes map[string]bool // hash -> used
+}
+
+func newFakeTwoFactorStore(recoveryHashes ...string) *fakeTwoFactorStore {
+	s := &fakeTwoFactorStore{lastStep: map[uuid.UUID]int64{}, recoveryCodes: map[string]bool{}}
+	for _, hash := range recoveryHashes {
+		s.recoveryCodes[hash] = false
+	}
+	return s
+}
+
+func (s *fakeTwoFactorStore) ConsumeTOTPStep(ctx context.Context, userID uuid.UUID, step int64) (bool, error) {
+	if s.lastStep[userID] >= step {
+		return false, nil
+	}
+	s.lastStep[userID] = step
+	return true, nil
+}
+
+func (s *fakeTwoFactorStore) ConsumeRecoveryCode(ctx context.Context, userID uuid.UUID, codeHash string) (bool, error) {
+	used, ok := s.recoveryCodes[codeHash]
+	if !ok || used {
+		return false, nil
+	}
+	s.recoveryCodes[codeHash] = true
+	return true, nil
+}
+
+// fakeIdentityStore keeps OIDC states; ConsumeState removes them, as
+// IdentityRepository does.
+type fakeIdentityStore struct {
+	IdentityStore
+
+	states map[string]model.OIDCState
+}
+
+func newFakeIdentityStore() *fakeIdentityStore {
+	return &fakeIdentityStore{states: map[string]model.OIDCState{}}
+}
+
+func (s *fakeIdentityStore) CreateState(ctx context.Context, state *model.OIDCState) error {
+	s.states[state.StateHash] = *state
+	return nil
+}
+
+func (s *fakeIdentityStore) ConsumeState(ctx context.Context, stateHash string) (*model.OIDCState, error) {
+	state, ok := s.states[stateHash]
+	if !ok {
+		return nil, nil
+	}
+	delete(s.states, stateHash)
+	return &state, nil
+}
+
+func (s *fakeIdentityStore) DeleteExpiredStates(ctx context.Context) error {
+	return nil
+}
+
+// fakeAPIKeyStore indexes keys by hash, like the key_hash column.
+type fakeAPIKeyStore struct {
+	APIKeyStore
+
+	keys    map[string]*model.APIKey
+	touched []uuid.UUID
+	revoked []uuid.UUID // users whose keys were all deleted
+}
+
+func newFakeAPIKeyStore() *fakeAPIKeyStore {
+	return &fakeAPIKeyStore{keys: map[string]*model.APIKey{}}
+}
+
+func (s *fakeAPIKeyStore) Create(ctx context.Context, key *model.APIKey) error {
+	key.ID = uuid.New()
+	copied := *key
+	s.keys[key.KeyHash] = &copied
+	return nil
+}
+
+func (s *fakeAPIKeyStore) FindByHash(ctx context.Context, hash string) (*model.APIKey, error) {
+	key, ok := s.keys[hash]
+	if !ok {
+		return nil, nil
+	}
+	copied := *key
+	return &copied, nil
+}
+
+func (s *fakeAPIKeyStore) DeleteAllByUser(ctx context.Context, userID uuid.UUID) error {
+	for hash, key := range s.keys {
+		if key.UserID == userID {
+			delete(s.keys, hash)
+		}
+	}
+	s.revoked = append(s.revoked, userID)
+	return nil
+}
+
+func (s *fakeAPIKeyStore) TouchLastUsed(ctx context.Context, id uuid.UUID) error {
+	s.touched = append(s.touched, id)
+	return nil
+}
+
+type fakeSecurityEventStore struct {
+	events []model.SecurityEvent
+}
+
+func (s *fakeSecurityEventStore) Create(ctx context.Context, event *model.SecurityEvent) error {
+	s.events = append(s.events, *event)
+	return nil
+}
+
+// fakeLoginGuard locks a key at Policy.MaxAttempts failures, without backoff.
+type fakeLoginGuard struct {
+	failures map[string]int
+}
+
+func newFakeLoginGuard() *fakeLoginGuard {
+	return &fakeLoginGuard{failures: map[string]int{}}
+}
+
+func (g *fakeLoginGuard) Check(ctx context.Context, subjects ...loginguard.Subject) error {
+	for _, subject := range subjects {
+		if g.failures[subject.Key] >= subject.Policy.MaxAttempts {
+			return &loginguard.LockedError{RetryAfter: subject.Policy.BaseLockout}
+		}
+	}
+	return nil
+}
+
+func (g *fakeLoginGuard) Fail(ctx context.Context, subjects ...loginguard.Subject) ([]loginguard.Lockout, error) {
+	var lockouts []loginguard.Lockout
+	for _, subject := range subjects {
+		g.failures[subject.Key]++
+		if g.failures[subject.Key] == subject.Policy.MaxAttempts {
+			lockouts = append(lockouts, loginguard.Lockout{Key: subject.Key, Failures: subject.Policy.MaxAttempts})
+		}
+	}
+	return lockouts, nil
+}
+
+func (g *fakeLoginGuard) Reset(ctx context.Context, subjects ...loginguard.Subject) error {
+	for _, subject := range subjects {
+		delete(g.failures, subject.Key)
+	}
+	return nil
+}
+
+// fakeTokenIssuer issues readable tokens instead of JWTs.
+type fakeTokenIssuer struct{}
+
+func (fakeTokenIssuer) IssueAccessToken(userID uuid.UUID, sessionID uuid.UUID) (string, error) {
+	return "access:" + userID.String() + ":" + sessionID.String(), nil
+}
+
+func (fakeTokenIssuer) IssuePreAuthToken(userID uuid.UUID) (string, error) {
+	return "preauth:" + userID.String(), nil
+}
+
+func (fakeTokenIssuer) ParsePreAuthToken(tokenStr string) (uuid.UUID, error) {
+	return uuid.Parse(tokenStr[len("preauth:"):])
+}
+
+type fakeMailer struct {
+	sent []mailer.Message
+}
+
+func (m *fakeMailer) Send(msg mailer.Message) error {
+	m.sent = append(m.sent, msg)
+	return nil
+}
@@ -0,0 +1,143 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"financial-track/loginguard"
+	"financial-track/model"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Errors stores return so use cases can pick the response.
+var (
+	// ErrVersionMismatch means the current version fails If-Match.
+	ErrVersionMismatch = errors.New("expense version mismatch")
+	// ErrBulkTooLarge means the selection exceeds the bulk limit.
+	ErrBulkTooLarge = errors.New("too many expenses selected")
+)
+
+// Use case dependencies, implemented by financial-track/repository and by
+// fakes in tests.
+
+type ExpenseStore interface {
+	Create(ctx context.Context, expense *model.Expense, actor model.AuditActor) error
+	CreateBatch(ctx context.Context, expenses []model.Expense, actor model.AuditActor) error
+	GetSummary(ctx context.Context, userID uuid.UUID, startDate, endDate time.Time, page, pageSize int, sort []model.SortKey) (model.PagedSummary, error)
+	FindByID(ctx context.Context, userID, id uuid.UUID) (*model.Expense, error)
+	Update(ctx context.Context, userID, id uuid.UUID, match model.VersionMatch, change func(*model.Expense) map[string]interface{}, actor model.AuditActor) (*model.Expense, error)
+	SoftDelete(ctx context.Context, userID, id uuid.UUID, match model.VersionMatch, actor model.AuditActor) error
+	Restore(ctx context.Context, userID, id uuid.UUID, actor model.AuditActor) (*model.Expense, error)
+	Purge(ctx context.Context, userID, id uuid.UUID, actor model.AuditActor) error
+	PurgeTrashedBefore(ctx context.Context, cutoff time.Time, batchSize int) (int64, error)
+	ListTrash(ctx context.Context, userID uuid.UUID, page, pageSize int) (model.PagedSummary, error)
+	Bulk(ctx context.Context, userID uuid.UUID, ids []uuid.UUID, filter *model.ExpenseFilter, limit int, change func(*model.Expense) map[string]interface{}, dryRun bool, actor model.AuditActor) ([]model.BulkItemResult, error)
+	ListByCursor(ctx context.Context, userID uuid.UUID, filter model.ExpenseFilter, sort []model.SortKey, values []interface{}, backward bool, limit int) ([]model.Expense, bool, error)
+	Count(ctx context.Context, userID uuid.UUID, filter model.ExpenseFilter) (int64, error)
+}
+
+type UserStore interface {
+	FindByEmail(ctx context.Context, email string) (*model.User, error)
+	FindByID(ctx context.Context, id string) (*model.User, error)
+	Create(ctx context.Context, user *model.User) error
+	UpdatePassword(ctx context.Context, id uuid.UUID, hashedPassword string) error
+	MarkEmailVerified(ctx context.Context, id uuid.UUID) error
+	Update(ctx context.Context, id uuid.UUID, fields map[string]interface{}, actor model.AuditActor) error
+	Delete(ctx context.Context, id uuid.UUID, actor model.AuditActor) error
+}
+
+// TokenStore holds refresh tokens and the jti denylist.
+type TokenStore interface {
+	CreateRefreshToken(ctx context.Context, token *model.RefreshToken) error
+	FindRefreshTokenByHash(ctx context.Context, hash string) (*model.RefreshToken, error)
+	RotateRefreshToken(ctx context.Context, current *model.RefreshToken, next *model.RefreshToken) error
+	RevokeJTI(ctx context.Context, jti string, expiresAt time.Time) error
+	IsJTIRevoked(ctx context.Context, jti string) (bool, error)
+	DeleteExpiredRevocations(ctx context.Context) error
+}
+
+type SessionStore interface {
+	Create(ctx context.Context, session *model.Session) error
+	FindByID(ctx context.Context, id string) (*model.Session, error)
+	ListActiveByUser(ctx context.Context, userID string) ([]model.Session, error)
+	Touch(ctx context.Context, id uuid.UUID) error
+	Revoke(ctx context.Context, id uuid.UUID) error
+	RevokeAllExcept(ctx context.Context, userID string, keepID string) error
+	RevokeAllByUser(ctx context.Context, userID uuid.UUID) error
+}
+
+// UserTokenStore holds the single-use tokens sent by email.
+type UserTokenStore interface {
+	Create(ctx context.Context, token *model.UserToken) error
+	FindByHash(ctx context.Context, hash string, purpose model.UserTokenPurpose) (*model.UserToken, error)
+	Consume(ctx context.Context, id uuid.UUID) (bool, error)
+}
+
+type TwoFactorStore interface {
+	SaveSecret(ctx context.Context, userID uuid.UUID, secret string) error
+	Enable(ctx context.Context, userID uuid.UUID, step int64, codeHashes []string) error
+	Disable(ctx context.Context, userID uuid.UUID) error
+	ConsumeTOTPStep(ctx context.Context, userID uuid.UUID, step int64) (bool, error)
+	ConsumeRecoveryCode(ctx context.Context, userID uuid.UUID, codeHash string) (bool, error)
+}
+
+type SecurityEventStore interface {
+	Create(ctx context.Context, event *model.SecurityEvent) error
+}
+
+type APIKeyStore interface {
+	Create(ctx context.Context, key *model.APIKey) error
+	FindByHash(ctx context.Context, hash string) (*model.APIKey, error)
+	ListByUser(ctx context.Context, userID string) ([]model.APIKey, error)
+	Delete(ctx context.Context, userID string, id string) (bool, error)
+	DeleteAllByUser(ctx context.Context, userID uuid.UUID) error
+	TouchLastUsed(ctx context.Context, id uuid.UUID) error
+}
+
+type AuditStore interface {
+	ListByUser(ctx context.Context, userID uuid.UUID, entityType string, page, pageSize int) (model.PagedAuditLog, error)
+}
+
+type IdentityStore interface {
+	Create(ctx context.Context, identity *model.UserIdentity) error
+	FindByProviderSubject(ctx context.Context, provider, subject string) (*model.UserIdentity, error)
+	ListByUser(ctx context.Context, userID string) ([]model.UserIdentity, error)
+	Delete(ctx context.Context, userID string, id string) (bool, error)
+	CreateUserWithIdentity(ctx context.Context, user *model.User, identity *model.UserIdentity) error
+	CreateState(ctx context.Context, state *model.OIDCState) error
+	ConsumeState(ctx context.Context, stateHash string) (*model.OIDCState, error)
+	DeleteExpiredStates(ctx context.Context) error
+}
+
+// LoginGuard counts login failures and locks accounts and IPs
+// (*loginguard.Guard).
+type LoginGuard interface {
+	Check(ctx context.Context, subjects ...loginguard.Subject) error
+	Fail(ctx context.Context, subjects ...loginguard.Subject) ([]loginguard.Lockout, error)
+	Reset(ctx context.Context, subjects ...loginguard.Subject) error
+}
+
+// TokenIssuer issues the app's JWTs (*authtoken.Service).
+type TokenIssuer interface {
+	IssueAccessToken(userID uuid.UUID, sessionID uuid.UUID) (string, error)
+	IssuePreAuthToken(userID uuid.UUID) (string, error)
+	ParsePreAuthToken(tokenStr string) (uuid.UUID, error)
+}
+
+// CursorCodec signs and verifies pagination cursors (*utils.CursorCodec).
+type CursorCodec interface {
+	Encode(payload interface{}) (string, error)
+	Decode(cursor string, payload interface{}) error
+}
+
+// Clock returns the current time so tests can fix it.
+type Clock interface {
+	Now() time.Time
+}
+
+type SystemClock struct{}
+
+func (SystemClock) Now() time.Time {
+	return time.Now()
+}
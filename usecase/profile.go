@@ -0,0 +1,153 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"financial-track/mailer"
+	"financial-track/model"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const accountDeletionTTL = time.Hour
+
+var (
+	ErrUserNotFound    = errors.New("user not found")
+	ErrInvalidPassword = errors.New("invalid password")
+	ErrEmailInUse      = errors.New("email already in use")
+)
+
+func (u *UserUseCase) GetProfile(ctx context.Context, userID string) (*model.User, error) {
+	user, err := u.repo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+	return user, nil
+}
+
+// UpdateProfile changes name and/or email. A new email drops the previous
+// verification and gets a confirmation email.
+func (u *UserUseCase) UpdateProfile(ctx context.Context, userID string, input model.UpdateProfileInput, actor model.AuditActor) (*model.User, error) {
+	user, err := u.GetProfile(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := map[string]interface{}{}
+	if input.Name != nil {
+		name := strings.TrimSpace(*input.Name)
+		if name == "" {
+			return nil, errors.New("name cannot be empty")
+		}
+		fields["name"] = name
+	}
+
+	emailChanged := false
+	if input.Email != nil && !strings.EqualFold(*input.Email, user.Email) {
+		existing, err := u.repo.FindByEmail(ctx, *input.Email)
+		if err != nil {
+			return nil, err
+		}
+		if existing != nil {
+			return nil, ErrEmailInUse
+		}
+		fields["email"] = *input.Email
+		fields["email_verified_at"] = nil
+		emailChanged = true
+	}
+
+	if len(fields) > 0 {
+		if err := u.repo.Update(ctx, user.ID, fields, actor); err != nil {
+			return nil, err
+		}
+	}
+
+	user, err = u.GetProfile(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if emailChanged {
+		logMailError(u.sendVerificationEmail(ctx, user))
+	}
+	return user, nil
+}
+
+// ChangePassword checks the current password, revokes the other sessions and
+// all API keys.
+func (u *UserUseCase) ChangePassword(ctx context.Context, userID string, currentSessionID string, input model.ChangePasswordInput) error {
+	user, err := u.GetProfile(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(input.CurrentPassword)); err != nil {
+		return ErrInvalidPassword
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(input.NewPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return errors.New("error to hash password")
+	}
+
+	if err := u.repo.UpdatePassword(ctx, user.ID, string(hashedPassword)); err != nil {
+		return err
+	}
+
+	if _, err := uuid.Parse(currentSessionID); err != nil {
+		err = u.sessionRepo.RevokeAllByUser(ctx, user.ID)
+	} else {
+		err = u.sessionRepo.RevokeAllExcept(ctx, userID, currentSessionID)
+	}
+	if err != nil {
+		return err
+	}
+	return u.apiKeyRepo.DeleteAllByUser(ctx, user.ID)
+}
+
+// RequestAccountDeletion is the first deletion step: it checks the password and
+// emails the token DeleteAccount expects.
+func (u *UserUseCase) RequestAccountDeletion(ctx context.Context, userID string, input model.RequestAccountDeletionInput) error {
+	user, err := u.GetProfile(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(input.Password)); err != nil {
+		return ErrInvalidPassword
+	}
+
+	raw, err := u.createUserToken(ctx, user, model.AccountDeletionPurpose, accountDeletionTTL)
+	if err != nil {
+		return err
+	}
+
+	return u.mailer.Send(mailer.Message{
+		To:      user.Email,
+		Subject: "Confirm your account deletion",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nWe received a request to permanently delete your account and all of its expenses.\n"+
+				"Use the token below to confirm. It expires in 1 hour.\n\n%s\n\nIf you did not request this, change your password.\n",
+			user.Name, raw,
+		),
+	})
+}
+
+// DeleteAccount removes the user and, by cascade, all their expenses.
+func (u *UserUseCase) DeleteAccount(ctx context.Context, userID string, input model.DeleteAccountInput, actor model.AuditActor) error {
+	token, err := u.consumeUserToken(ctx, input.Token, model.AccountDeletionPurpose)
+	if err != nil {
+		return err
+	}
+	if token.UserID.String() != userID {
+		return ErrInvalidUserToken
+	}
+
+	return u.repo.Delete(ctx, token.UserID, actor)
+}
@@ -0,0 +1,209 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"financial-track/model"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var mailedTokenPattern = regexp.MustCompile(`[A-Za-z0-9_-]{43}`)
+
+// mailedToken returns the raw token carried by the last email sent.
+func mailedToken(t *testing.T, m *fakeMailer) string {
+	t.Helper()
+	if len(m.sent) == 0 {
+		t.Fatal("no email sent")
+	}
+	token := mailedTokenPattern.FindString(m.sent[len(m.sent)-1].Body)
+	if token == "" {
+		t.Fatalf("no token in %q", m.sent[len(m.sent)-1].Body)
+	}
+	return token
+}
+
+func strPtr(s string) *string {
+	return &s
+}
+
+func TestUpdateProfile(t *testing.T) {
+	verifiedAt := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name         string
+		input        model.UpdateProfileInput
+		wantErr      error
+		wantName     string
+		wantEmail    string
+		wantVerified bool
+		wantMail     bool
+	}{
+		{name: "name only", input: model.UpdateProfileInput{Name: strPtr("  Alice B  ")}, wantName: "Alice B", wantEmail: "alice@example.com", wantVerified: true},
+		{name: "same email in another case", input: model.UpdateProfileInput{Email: strPtr("ALICE@example.com")}, wantName: "Alice", wantEmail: "alice@example.com", wantVerified: true},
+		{name: "new email needs verification", input: model.UpdateProfileInput{Email: strPtr("alice@new.example.com")}, wantName: "Alice", wantEmail: "alice@new.example.com", wantMail: true},
+		{name: "email in use", input: model.UpdateProfileInput{Email: strPtr("bob@example.com")}, wantErr: ErrEmailInUse},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc, f := newUserUseCaseWithFakes(t, newFakeLoginGuard())
+			f.alice.EmailVerifiedAt = &verifiedAt
+			f.users.users["bob@example.com"] = &model.User{ID: uuid.New(), Email: "bob@example.com"}
+
+			user, err := uc.UpdateProfile(context.Background(), f.alice.ID.String(), tt.input, model.AuditActor{})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("UpdateProfile = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if user.Name != tt.wantName || user.Email != tt.wantEmail || (user.EmailVerifiedAt != nil) != tt.wantVerified {
+				t.Errorf("user = %q %q verified=%v", user.Name, user.Email, user.EmailVerifiedAt != nil)
+			}
+			if sent := len(f.mailer.sent) == 1; sent != tt.wantMail {
+				t.Fatalf("sent = %d emails", len(f.mailer.sent))
+			}
+			if !tt.wantMail {
+				return
+			}
+			if f.mailer.sent[0].To != tt.wantEmail {
+				t.Errorf("verification sent to %q", f.mailer.sent[0].To)
+			}
+			if err := uc.VerifyEmail(context.Background(), model.VerifyEmailInput{Token: mailedToken(t, f.mailer)}); err != nil {
+				t.Fatal(err)
+			}
+			if f.alice.EmailVerifiedAt == nil {
+				t.Error("new email not verified by the mailed token")
+			}
+		})
+	}
+}
+
+func TestChangePassword(t *testing.T) {
+	currentSession := uuid.NewString()
+
+	tests := []struct {
+		name        string
+		current     string
+		sessionID   string
+		wantErr     error
+		wantRevoked string
+	}{
+		{name: "keeps the current session", current: testPassword, sessionID: currentSession, wantRevoked: ":" + currentSession},
+		{name: "api key caller revokes every session", current: testPassword, sessionID: ""},
+		{name: "wrong current password", current: "nope", wantErr: ErrInvalidPassword},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc, f := newUserUseCaseWithFakes(t, newFakeLoginGuard())
+			f.apiKeys.keys["hash"] = &model.APIKey{UserID: f.alice.ID}
+			oldHash := f.alice.Password
+
+			input := model.ChangePasswordInput{CurrentPassword: tt.current, NewPassword: "new password"}
+			err := uc.ChangePassword(context.Background(), f.alice.ID.String(), tt.sessionID, input)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ChangePassword = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				if f.alice.Password != oldHash || len(f.sessions.revokedAll) != 0 || len(f.apiKeys.keys) != 1 {
+					t.Error("failed change must not touch credentials")
+				}
+				return
+			}
+			if bcrypt.CompareHashAndPassword([]byte(f.alice.Password), []byte("new password")) != nil {
+				t.Error("password not updated")
+			}
+			want := f.alice.ID.String() + tt.wantRevoked
+			if len(f.sessions.revokedAll) != 1 || f.sessions.revokedAll[0] != want {
+				t.Errorf("revoked sessions = %v, want %v", f.sessions.revokedAll, want)
+			}
+			if len(f.apiKeys.keys) != 0 {
+				t.Error("API keys survived the password change")
+			}
+		})
+	}
+}
+
+func TestResetPasswordRevokesCredentials(t *testing.T) {
+	uc, f := newUserUseCaseWithFakes(t, newFakeLoginGuard())
+	f.apiKeys.keys["hash"] = &model.APIKey{UserID: f.alice.ID}
+
+	if err := uc.ForgotPassword(context.Background(), model.ForgotPasswordInput{Email: f.alice.Email}); err != nil {
+		t.Fatal(err)
+	}
+	err := uc.ResetPassword(context.Background(), model.ResetPasswordInput{Token: mailedToken(t, f.mailer), Password: "new password"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(f.sessions.revokedAll) != 1 || f.sessions.revokedAll[0] != f.alice.ID.String() {
+		t.Errorf("revoked sessions = %v", f.sessions.revokedAll)
+	}
+	if len(f.apiKeys.keys) != 0 {
+		t.Error("API keys survived the password reset")
+	}
+}
+
+func TestDeleteAccount(t *testing.T) {
+	tests := []struct {
+		name     string
+		password string
+		tamper   func(f *userFakes, token string) (userID string, raw string)
+		// wantRequestErr is for the first step, wantErr for the confirmation.
+		wantRequestErr error
+		wantErr        error
+	}{
+		{name: "confirmed with the mailed token"},
+		{name: "wrong password", password: "nope", wantRequestErr: ErrInvalidPassword},
+		{
+			name: "token of another user",
+			tamper: func(f *userFakes, token string) (string, string) {
+				return uuid.NewString(), token
+			},
+			wantErr: ErrInvalidUserToken,
+		},
+		{
+			name: "expired token",
+			tamper: func(f *userFakes, token string) (string, string) {
+				f.clock.now = f.clock.now.Add(accountDeletionTTL + time.Second)
+				return f.alice.ID.String(), token
+			},
+			wantErr: ErrInvalidUserToken,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc, f := newUserUseCaseWithFakes(t, newFakeLoginGuard())
+			password := tt.password
+			if password == "" {
+				password = testPassword
+			}
+
+			err := uc.RequestAccountDeletion(context.Background(), f.alice.ID.String(), model.RequestAccountDeletionInput{Password: password})
+			if !errors.Is(err, tt.wantRequestErr) {
+				t.Fatalf("RequestAccountDeletion = %v, want %v", err, tt.wantRequestErr)
+			}
+			if err != nil {
+				if len(f.mailer.sent) != 0 {
+					t.Error("deletion token mailed without the password")
+				}
+				return
+			}
+
+			userID, token := f.alice.ID.String(), mailedToken(t, f.mailer)
+			if tt.tamper != nil {
+				userID, token = tt.tamper(f, token)
+			}
+			err = uc.DeleteAccount(context.Background(), userID, model.DeleteAccountInput{Token: token}, model.AuditActor{})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("DeleteAccount = %v, want %v", err, tt.wantErr)
+			}
+			if deleted := len(f.users.deleted) == 1; deleted != (tt.wantErr == nil) {
+				t.Errorf("deleted = %v", f.users.deleted)
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"financial-track/model"
+
+	"github.com/google/uuid"
+)
+
+var ErrSessionNotFound = errors.New("session not found")
+
+type SessionUseCase struct {
+	repo SessionStore
+}
+
+func NewSessionUseCase(repo SessionStore) *SessionUseCase {
+	return &SessionUseCase{repo: repo}
+}
+
+func (s *SessionUseCase) ListSessions(ctx context.Context, userID string, currentSessionID string) ([]model.SessionResponse, error) {
+	sessions, err := s.repo.ListActiveByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := make([]model.SessionResponse, 0, len(sessions))
+	for _, session := range sessions {
+		resp = append(resp, model.SessionResponse{
+			ID:         session.ID,
+			UserAgent:  session.UserAgent,
+			IP:         session.IP,
+			CreatedAt:  session.CreatedAt,
+			LastSeenAt: session.LastSeenAt,
+			Current:    session.ID.String() == currentSessionID,
+		})
+	}
+	return resp, nil
+}
+
+func (s *SessionUseCase) RevokeSession(ctx context.Context, userID string, sessionID string) error {
+	if _, err := uuid.Parse(sessionID); err != nil {
+		return ErrSessionNotFound
+	}
+
+	session, err := s.repo.FindByID(ctx, sessionID)
+	if err != nil {
+		return err
+	}
+	if session == nil || session.UserID.String() != userID || session.RevokedAt != nil {
+		return ErrSessionNotFound
+	}
+	return s.repo.Revoke(ctx, session.ID)
+}
+
+func (s *SessionUseCase) RevokeOtherSessions(ctx context.Context, userID string, currentSessionID string) error {
+	return s.repo.RevokeAllExcept(ctx, userID, currentSessionID)
+}
@@ -0,0 +1,90 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"financial-track/model"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+// newTestSessionUseCase returns the use case over alice's two sessions and
+// one of bob's.
+func newTestSessionUseCase(t *testing.T) (*SessionUseCase, *fakeSessionStore, uuid.UUID, []model.Session) {
+	t.Helper()
+	alice, bob := uuid.New(), uuid.New()
+	store := &fakeSessionStore{}
+	for _, userID := range []uuid.UUID{alice, alice, bob} {
+		if err := store.Create(context.Background(), &model.Session{UserID: userID, UserAgent: "test"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return NewSessionUseCase(store), store, alice, store.created
+}
+
+func TestListSessions(t *testing.T) {
+	uc, _, alice, sessions := newTestSessionUseCase(t)
+
+	got, err := uc.ListSessions(context.Background(), alice.String(), sessions[1].ID.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("listed %d sessions, want only alice's 2", len(got))
+	}
+	for _, session := range got {
+		if session.Current != (session.ID == sessions[1].ID) {
+			t.Errorf("session %s current = %v", session.ID, session.Current)
+		}
+	}
+}
+
+func TestRevokeSession(t *testing.T) {
+	tests := []struct {
+		name    string
+		session func(sessions []model.Session) string
+		twice   bool
+		wantErr error
+	}{
+		{name: "own session", session: func(s []model.Session) string { return s[0].ID.String() }},
+		{name: "already revoked", session: func(s []model.Session) string { return s[0].ID.String() }, twice: true, wantErr: ErrSessionNotFound},
+		{name: "another user's session", session: func(s []model.Session) string { return s[2].ID.String() }, wantErr: ErrSessionNotFound},
+		{name: "unknown session", session: func([]model.Session) string { return uuid.NewString() }, wantErr: ErrSessionNotFound},
+		{name: "malformed id", session: func([]model.Session) string { return "not-a-uuid" }, wantErr: ErrSessionNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc, store, alice, sessions := newTestSessionUseCase(t)
+			id := tt.session(sessions)
+
+			err := uc.RevokeSession(context.Background(), alice.String(), id)
+			if tt.twice {
+				err = uc.RevokeSession(context.Background(), alice.String(), id)
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("RevokeSession = %v, want %v", err, tt.wantErr)
+			}
+			wantRevoked := 0
+			if tt.wantErr == nil || tt.twice {
+				wantRevoked = 1
+			}
+			if len(store.revoked) != wantRevoked {
+				t.Errorf("revoked = %v, want %d", store.revoked, wantRevoked)
+			}
+		})
+	}
+}
+
+func TestRevokeOtherSessions(t *testing.T) {
+	uc, store, alice, sessions := newTestSessionUseCase(t)
+	current := sessions[1].ID.String()
+
+	if err := uc.RevokeOtherSessions(context.Background(), alice.String(), current); err != nil {
+		t.Fatal(err)
+	}
+	want := alice.String() + ":" + current
+	if len(store.revokedAll) != 1 || store.revokedAll[0] != want {
+		t.Errorf("revokedAll = %v, want [%s]", store.revokedAll, want)
+	}
+}
@@ -0,0 +1,246 @@
+package usecase
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"financial-track/model"
+	"financial-track/oidc"
+	"financial-track/utils"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// OIDCStateTTL bounds the state and the cookie that binds it to the browser.
+const OIDCStateTTL = 10 * time.Minute
+
+var (
+	ErrUnknownProvider     = errors.New("unknown identity provider")
+	ErrInvalidOIDCState    = errors.New("invalid or expired login state")
+	ErrOIDCLoginFailed     = errors.New("identity provider login failed")
+	ErrOIDCEmailRequired   = errors.New("identity provider did not return an email")
+	ErrOIDCEmailUnverified = errors.New("email already registered; sign in and link this provider from your profile")
+	ErrIdentityInUse       = errors.New("identity already linked to another account")
+	ErrIdentityNotFound    = errors.New("identity not found")
+)
+
+type SocialLoginUseCase struct {
+	users        *UserUseCase
+	identityRepo IdentityStore
+	providers    *oidc.Registry
+}
+
+func NewSocialLoginUseCase(users *UserUseCase, identityRepo IdentityStore, providers *oidc.Registry) *SocialLoginUseCase {
+	return &SocialLoginUseCase{users: users, identityRepo: identityRepo, providers: providers}
+}
+
+// StartLogin returns the provider's authorization URL and the state, which the
+// controller stores in a cookie to bind the flow to this browser. With
+// linkUserID the callback links the identity to that user instead of logging in.
+func (s *SocialLoginUseCase) StartLogin(ctx context.Context, providerName string, linkUserID *uuid.UUID) (authURL string, rawState string, err error) {
+	provider, ok := s.providers.Get(providerName)
+	if !ok {
+		return "", "", ErrUnknownProvider
+	}
+
+	rawState, stateHash, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return "", "", err
+	}
+	verifier, _, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return "", "", err
+	}
+	nonce, _, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return "", "", err
+	}
+
+	authURL, err = provider.AuthCodeURL(ctx, rawState, nonce, oidc.CodeChallengeS256(verifier))
+	if err != nil {
+		log.Println("⚠️ OIDC:", err)
+		return "", "", ErrOIDCLoginFailed
+	}
+
+	if err := s.identityRepo.DeleteExpiredStates(ctx); err != nil {
+		return "", "", err
+	}
+	state := model.OIDCState{
+		StateHash:    stateHash,
+		Provider:     provider.Name(),
+		CodeVerifier: verifier,
+		Nonce:        nonce,
+		UserID:       linkUserID,
+		ExpiresAt:    s.users.clock.Now().Add(OIDCStateTTL),
+	}
+	if err := s.identityRepo.CreateState(ctx, &state); err != nil {
+		return "", "", err
+	}
+
+	return authURL, rawState, nil
+}
+
+// HandleCallback finishes the flow. boundState comes from the browser cookie and
+// must equal the provider's state, or a callback started by someone else would
+// be accepted (login CSRF). Login returns a LoginResult; linking returns the new
+// identity.
+func (s *SocialLoginUseCase) HandleCallback(ctx context.Context, providerName, code, rawState, boundState string, meta model.SessionMetadata) (*model.LoginResult, *model.UserIdentity, error) {
+	provider, ok := s.providers.Get(providerName)
+	if !ok {
+		return nil, nil, ErrUnknownProvider
+	}
+	if rawState == "" || subtle.ConstantTimeCompare([]byte(rawState), []byte(boundState)) != 1 {
+		return nil, nil, ErrInvalidOIDCState
+	}
+
+	state, err := s.identityRepo.ConsumeState(ctx, utils.HashToken(rawState))
+	if err != nil {
+		return nil, nil, err
+	}
+	if state == nil || state.Provider != provider.Name() {
+		return nil, nil, ErrInvalidOIDCState
+	}
+
+	claims, err := provider.Exchange(ctx, code, state.CodeVerifier, state.Nonce)
+	if err != nil {
+		log.Println("⚠️ OIDC:", err)
+		return nil, nil, ErrOIDCLoginFailed
+	}
+
+	identity, err := s.identityRepo.FindByProviderSubject(ctx, provider.Name(), claims.Subject)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if state.UserID != nil {
+		identity, err := s.link(ctx, *state.UserID, identity, provider.Name(), claims)
+		return nil, identity, err
+	}
+
+	user, err := s.resolveUser(ctx, identity, provider.Name(), claims)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	result, err := s.users.completeLogin(ctx, user, meta)
+	return result, nil, err
+}
+
+func (s *SocialLoginUseCase) ListIdentities(ctx context.Context, userID string) ([]model.UserIdentity, error) {
+	return s.identityRepo.ListByUser(ctx, userID)
+}
+
+func (s *SocialLoginUseCase) UnlinkIdentity(ctx context.Context, userID string, identityID string) error {
+	if _, err := uuid.Parse(identityID); err != nil {
+		return ErrIdentityNotFound
+	}
+	deleted, err := s.identityRepo.Delete(ctx, userID, identityID)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrIdentityNotFound
+	}
+	return nil
+}
+
+func (s *SocialLoginUseCase) link(ctx context.Context, userID uuid.UUID, existing *model.UserIdentity, provider string, claims *oidc.Claims) (*model.UserIdentity, error) {
+	if existing != nil {
+		if existing.UserID != userID {
+			return nil, ErrIdentityInUse
+		}
+		return existing, nil
+	}
+
+	identity := model.UserIdentity{
+		UserID:   userID,
+		Provider: provider,
+		Subject:  claims.Subject,
+		Email:    claims.Email,
+	}
+	if err := s.identityRepo.Create(ctx, &identity); err != nil {
+		return nil, err
+	}
+	return &identity, nil
+}
+
+// resolveUser finds the identity's user. Without a link it matches by email only
+// when both the provider and the local account have verified it, and creates an
+// account when none has the email.
+func (s *SocialLoginUseCase) resolveUser(ctx context.Context, identity *model.UserIdentity, provider string, claims *oidc.Claims) (*model.User, error) {
+	if identity != nil {
+		user, err := s.users.repo.FindByID(ctx, identity.UserID.String())
+		if err != nil {
+			return nil, err
+		}
+		if user == nil {
+			return nil, ErrUserNotFound
+		}
+		return user, nil
+	}
+
+	if claims.Email == "" {
+		return nil, ErrOIDCEmailRequired
+	}
+
+	user, err := s.users.repo.FindByEmail(ctx, claims.Email)
+	if err != nil {
+		return nil, err
+	}
+
+	if user != nil {
+		// An unverified local account may have been registered by someone else
+		// with the victim's email; linking it would hand them the provider login.
+		// The owner must sign in with a password and link from the profile.
+		if !claims.EmailVerified || user.EmailVerifiedAt == nil {
+			return nil, ErrOIDCEmailUnverified
+		}
+		if _, err := s.link(ctx, user.ID, nil, provider, claims); err != nil {
+			return nil, err
+		}
+		return user, nil
+	}
+
+	// OIDC accounts get a random password; the user can set one through the
+	// reset flow.
+	randomPassword, _, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(randomPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, errors.New("error to hash password")
+	}
+
+	name := strings.TrimSpace(claims.Name)
+	if name == "" {
+		name = strings.Split(claims.Email, "@")[0]
+	}
+
+	user = &model.User{
+		Name:     name,
+		Email:    claims.Email,
+		Password: string(hashedPassword),
+	}
+	if claims.EmailVerified {
+		now := s.users.clock.Now()
+		user.EmailVerifiedAt = &now
+	}
+
+	identity = &model.UserIdentity{
+		Provider: provider,
+		Subject:  claims.Subject,
+		Email:    claims.Email,
+	}
+	if err := s.identityRepo.CreateUserWithIdentity(ctx, user, identity); err != nil {
+		return nil, err
+	}
+	if !claims.EmailVerified {
+		logMailError(s.users.sendVerificationEmail(ctx, user))
+	}
+	return user, nil
+}
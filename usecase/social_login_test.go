@@ -0,0 +1,154 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"financial-track/model"
+	"financial-track/oidc"
+	"financial-track/utils"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+// newSocialLoginUseCase uses a "mock" provider whose issuer only serves
+// discovery: enough to start the flow, but the code exchange always fails.
+func newSocialLoginUseCase(t *testing.T) (*SocialLoginUseCase, *fakeIdentityStore, time.Time) {
+	t.Helper()
+	var issuer *httptest.Server
+	issuer = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/.well-known/openid-configuration" {
+			http.NotFound(w, r)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 issuer.URL,
+			"authorization_endpoint": issuer.URL + "/authorize",
+			"token_endpoint":         issuer.URL + "/token",
+			"jwks_uri":               issuer.URL + "/jwks",
+		})
+	}))
+	t.Cleanup(issuer.Close)
+
+	now := time.Date(2025, 10, 15, 12, 0, 0, 0, time.UTC)
+	users := NewUserUseCase(&fakeUserStore{}, newFakeTokenStore(), &fakeSessionStore{}, newFakeAPIKeyStore(), nil, nil, &fakeSecurityEventStore{},
+		&fakeMailer{}, newFakeLoginGuard(), fakeTokenIssuer{}, &fixedClock{now: now}, AppInfo{})
+	providers := oidc.NewRegistry([]oidc.Config{
+		{Name: "mock", Issuer: issuer.URL, ClientID: "client-id", RedirectURL: "http://localhost:81/auth/oidc/mock/callback"},
+		{Name: "other", Issuer: issuer.URL, ClientID: "client-id", RedirectURL: "http://localhost:81/auth/oidc/other/callback"},
+	})
+	identities := newFakeIdentityStore()
+	return NewSocialLoginUseCase(users, identities, providers), identities, now
+}
+
+func TestStartLoginStoresHashedStateAndVerifier(t *testing.T) {
+	uc, identities, now := newSocialLoginUseCase(t)
+
+	authURL, rawState, err := uc.StartLogin(context.Background(), "mock", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	state, ok := identities.states[utils.HashToken(rawState)]
+	if !ok || len(identities.states) != 1 {
+		t.Fatalf("states = %+v, want one stored by the hash of the raw state", identities.states)
+	}
+	if !state.ExpiresAt.Equal(now.Add(OIDCStateTTL)) || state.Provider != "mock" || state.UserID != nil {
+		t.Errorf("state = %+v", state)
+	}
+
+	u, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q := u.Query()
+	if q.Get("state") != rawState || q.Get("nonce") != state.Nonce {
+		t.Errorf("authorization url %s does not carry the stored state and nonce", authURL)
+	}
+	if q.Get("code_challenge") != oidc.CodeChallengeS256(state.CodeVerifier) || q.Get("code_challenge") == state.CodeVerifier {
+		t.Errorf("code_challenge %q is not the S256 of the stored verifier", q.Get("code_challenge"))
+	}
+}
+
+func TestHandleCallbackStateBinding(t *testing.T) {
+	tests := []struct {
+		name     string
+		provider string
+		// state and cookie return the callback state and the cookie state.
+		state, cookie func(raw string) string
+		want          error
+		wantConsumed  bool
+	}{
+		{
+			name:     "state bound to this browser",
+			provider: "mock",
+			state:    func(raw string) string { return raw },
+			cookie:   func(raw string) string { return raw },
+			// The state is accepted; only the code exchange fails.
+			want:         ErrOIDCLoginFailed,
+			wantConsumed: true,
+		},
+		{
+			name:     "cookie from another login",
+			provider: "mock",
+			state:    func(raw string) string { return raw },
+			cookie:   func(raw string) string { return "another-state" },
+			want:     ErrInvalidOIDCState,
+		},
+		{
+			name:     "missing cookie",
+			provider: "mock",
+			state:    func(raw string) string { return raw },
+			cookie:   func(raw string) string { return "" },
+			want:     ErrInvalidOIDCState,
+		},
+		{
+			name:     "empty state",
+			provider: "mock",
+			state:    func(raw string) string { return "" },
+			cookie:   func(raw string) string { return "" },
+			want:     ErrInvalidOIDCState,
+		},
+		{
+			name:     "unknown state",
+			provider: "mock",
+			state:    func(raw string) string { return "forged" },
+			cookie:   func(raw string) string { return "forged" },
+			want:     ErrInvalidOIDCState,
+		},
+		{
+			name:         "state issued for another provider",
+			provider:     "other",
+			state:        func(raw string) string { return raw },
+			cookie:       func(raw string) string { return raw },
+			want:         ErrInvalidOIDCState,
+			wantConsumed: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc, identities, _ := newSocialLoginUseCase(t)
+			_, raw, err := uc.StartLogin(context.Background(), "mock", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			_, _, err = uc.HandleCallback(context.Background(), tt.provider, "auth-code", tt.state(raw), tt.cookie(raw), model.SessionMetadata{})
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("HandleCallback = %v, want %v", err, tt.want)
+			}
+			if _, kept := identities.states[utils.HashToken(raw)]; kept == tt.wantConsumed {
+				t.Fatalf("state kept = %v, want consumed = %v", kept, tt.wantConsumed)
+			}
+
+			// A consumed state is never accepted again.
+			if tt.wantConsumed {
+				if _, _, err := uc.HandleCallback(context.Background(), "mock", "auth-code", raw, raw, model.SessionMetadata{}); !errors.Is(err, ErrInvalidOIDCState) {
+					t.Fatalf("replayed callback = %v, want ErrInvalidOIDCState", err)
+				}
+			}
+		})
+	}
+}
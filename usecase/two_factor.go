@@ -0,0 +1,157 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"financial-track/loginguard"
+	"financial-track/model"
+	"financial-track/utils"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+const recoveryCodeCount = 10
+
+var (
+	ErrTwoFactorAlreadyEnabled = errors.New("two-factor authentication is already enabled")
+	ErrTwoFactorNotEnabled     = errors.New("two-factor authentication is not enabled")
+	ErrTwoFactorNotSetUp       = errors.New("two-factor setup has not been started")
+	ErrInvalidTwoFactorCode    = errors.New("invalid two-factor code")
+	ErrInvalidPreAuthToken     = errors.New("invalid or expired pre-auth token")
+)
+
+// SetupTwoFactor creates a TOTP secret that only applies after EnableTwoFactor.
+func (u *UserUseCase) SetupTwoFactor(ctx context.Context, userID string) (*model.TwoFactorSetupResponse, error) {
+	user, err := u.GetProfile(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.TwoFactorEnabled {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+	if err := u.twoFactorRepo.SaveSecret(ctx, user.ID, secret); err != nil {
+		return nil, err
+	}
+
+	return &model.TwoFactorSetupResponse{
+		Secret:     secret,
+		OtpauthURI: utils.TOTPURI(u.app.Name, user.Email, secret),
+	}, nil
+}
+
+// EnableTwoFactor confirms the secret with a valid code and returns the recovery
+// codes, shown only in this response.
+func (u *UserUseCase) EnableTwoFactor(ctx context.Context, userID string, input model.EnableTwoFactorInput) ([]string, error) {
+	user, err := u.GetProfile(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.TwoFactorEnabled {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+	if user.TOTPSecret == "" {
+		return nil, ErrTwoFactorNotSetUp
+	}
+
+	step, ok := utils.ValidateTOTP(user.TOTPSecret, input.Code, u.clock.Now())
+	if !ok {
+		return nil, ErrInvalidTwoFactorCode
+	}
+
+	codes, err := utils.GenerateRecoveryCodes(recoveryCodeCount)
+	if err != nil {
+		return nil, err
+	}
+	hashes := make([]string, 0, len(codes))
+	for _, code := range codes {
+		hashes = append(hashes, utils.HashToken(code))
+	}
+
+	if err := u.twoFactorRepo.Enable(ctx, user.ID, step, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// DisableTwoFactor needs the password and a TOTP or recovery code.
+func (u *UserUseCase) DisableTwoFactor(ctx context.Context, userID string, input model.DisableTwoFactorInput) error {
+	user, err := u.GetProfile(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !user.TwoFactorEnabled {
+		return ErrTwoFactorNotEnabled
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(input.Password)); err != nil {
+		return ErrInvalidPassword
+	}
+	if err := u.checkSecondFactor(ctx, user, input.Code); err != nil {
+		return err
+	}
+
+	return u.twoFactorRepo.Disable(ctx, user.ID)
+}
+
+// VerifyTwoFactor is the second login step: it exchanges the pre-auth token and
+// code for the session tokens.
+func (u *UserUseCase) VerifyTwoFactor(ctx context.Context, input model.VerifyTwoFactorInput, meta model.SessionMetadata) (*model.AuthTokens, error) {
+	userID, err := u.tokens.ParsePreAuthToken(input.PreAuthToken)
+	if err != nil {
+		return nil, ErrInvalidPreAuthToken
+	}
+
+	user, err := u.repo.FindByID(ctx, userID.String())
+	if err != nil {
+		return nil, err
+	}
+	if user == nil || !user.TwoFactorEnabled {
+		return nil, ErrInvalidPreAuthToken
+	}
+
+	account := loginguard.Account("2fa:" + user.ID.String())
+	subjects := []loginguard.Subject{account, loginguard.IP(meta.IP)}
+	if err := u.guard.Check(ctx, subjects...); err != nil {
+		return nil, err
+	}
+
+	if err := u.checkSecondFactor(ctx, user, input.Code); err != nil {
+		if errors.Is(err, ErrInvalidTwoFactorCode) {
+			u.registerLoginFailure(ctx, user, meta, subjects)
+		}
+		return nil, err
+	}
+
+	if err := u.guard.Reset(ctx, account); err != nil {
+		return nil, err
+	}
+
+	return u.startSession(ctx, user.ID, meta)
+}
+
+func (u *UserUseCase) checkSecondFactor(ctx context.Context, user *model.User, code string) error {
+	if step, ok := utils.ValidateTOTP(user.TOTPSecret, code, u.clock.Now()); ok {
+		consumed, err := u.twoFactorRepo.ConsumeTOTPStep(ctx, user.ID, step)
+		if err != nil {
+			return err
+		}
+		if !consumed {
+			return ErrInvalidTwoFactorCode
+		}
+		return nil
+	}
+
+	consumed, err := u.twoFactorRepo.ConsumeRecoveryCode(ctx, user.ID, utils.HashToken(utils.NormalizeRecoveryCode(code)))
+	if err != nil {
+		return err
+	}
+	if !consumed {
+		return ErrInvalidTwoFactorCode
+	}
+	return nil
+}
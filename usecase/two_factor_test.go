@@ -0,0 +1,101 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"financial-track/model"
+	"financial-track/utils"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RFC 6238 vector: with this secret the code at t=59s is 287082 (step 1).
+const (
+	testTOTPSecret   = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+	testTOTPCode     = "287082"
+	testRecoveryCode = "abcde-fghij"
+)
+
+func newTwoFactorUseCase(t *testing.T) (*UserUseCase, *model.User, *fakeLoginGuard) {
+	t.Helper()
+	user := &model.User{ID: uuid.New(), Email: "alice@example.com", TwoFactorEnabled: true, TOTPSecret: testTOTPSecret}
+	guard := newFakeLoginGuard()
+	uc := NewUserUseCase(
+		&fakeUserStore{users: map[string]*model.User{user.Email: user}},
+		newFakeTokenStore(),
+		&fakeSessionStore{},
+		newFakeAPIKeyStore(),
+		nil,
+		newFakeTwoFactorStore(utils.HashToken(testRecoveryCode)),
+		&fakeSecurityEventStore{},
+		&fakeMailer{},
+		guard,
+		fakeTokenIssuer{},
+		&fixedClock{now: time.Unix(59, 0)},
+		AppInfo{Name: "Financial Track", URL: "http://localhost:81"},
+	)
+	return uc, user, guard
+}
+
+func TestVerifyTwoFactor(t *testing.T) {
+	tests := []struct {
+		name     string
+		codes    []string
+		wantLast error
+	}{
+		{name: "totp code", codes: []string{testTOTPCode}},
+		{name: "totp code replayed", codes: []string{testTOTPCode, testTOTPCode}, wantLast: ErrInvalidTwoFactorCode},
+		{name: "wrong code", codes: []string{"000000"}, wantLast: ErrInvalidTwoFactorCode},
+		{name: "recovery code", codes: []string{" ABCDE-FGHIJ "}},
+		{name: "recovery code used twice", codes: []string{testRecoveryCode, testRecoveryCode}, wantLast: ErrInvalidTwoFactorCode},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc, user, _ := newTwoFactorUseCase(t)
+			preAuth, _ := fakeTokenIssuer{}.IssuePreAuthToken(user.ID)
+
+			var tokens *model.AuthTokens
+			var err error
+			for _, code := range tt.codes {
+				tokens, err = uc.VerifyTwoFactor(context.Background(), model.VerifyTwoFactorInput{PreAuthToken: preAuth, Code: code}, model.SessionMetadata{IP: "203.0.113.7"})
+			}
+			if !errors.Is(err, tt.wantLast) {
+				t.Fatalf("VerifyTwoFactor = %v, want %v", err, tt.wantLast)
+			}
+			if tt.wantLast == nil && (tokens == nil || tokens.AccessToken == "") {
+				t.Fatalf("VerifyTwoFactor returned %+v, want tokens", tokens)
+			}
+		})
+	}
+}
+
+func TestVerifyTwoFactorCountsFailures(t *testing.T) {
+	uc, user, guard := newTwoFactorUseCase(t)
+	preAuth, _ := fakeTokenIssuer{}.IssuePreAuthToken(user.ID)
+	input := model.VerifyTwoFactorInput{PreAuthToken: preAuth, Code: "000000"}
+
+	if _, err := uc.VerifyTwoFactor(context.Background(), input, model.SessionMetadata{IP: "203.0.113.7"}); !errors.Is(err, ErrInvalidTwoFactorCode) {
+		t.Fatalf("VerifyTwoFactor = %v, want ErrInvalidTwoFactorCode", err)
+	}
+	if guard.failures["account:2fa:"+user.ID.String()] != 1 {
+		t.Fatalf("failures = %v, want one for the 2FA account", guard.failures)
+	}
+}
+
+func TestLoginWithTwoFactorReturnsPreAuthToken(t *testing.T) {
+	uc, user, _ := newTwoFactorUseCase(t)
+	tokens := uc.tokens
+
+	result, err := uc.completeLogin(context.Background(), user, model.SessionMetadata{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.TwoFactorRequired || result.Tokens != nil {
+		t.Fatalf("completeLogin = %+v, want only a pre-auth token", result)
+	}
+	if id, err := tokens.ParsePreAuthToken(result.PreAuthToken); err != nil || id != user.ID {
+		t.Fatalf("pre-auth token for %s (%v), want %s", id, err, user.ID)
+	}
+}
@@ -1,29 +1,80 @@
 package usecase
 
 import (
+	"context"
 	"errors"
+	"financial-track/authtoken"
+	"financial-track/loginguard"
+	"financial-track/mailer"
 	"financial-track/model"
-	"financial-track/repository"
 	"financial-track/utils"
+	"fmt"
+	"log"
+	"strings"
+	"time"
 
+	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidRefreshToken = errors.New("invalid or expired refresh token")
+	ErrRefreshTokenReused  = errors.New("refresh token reuse detected, session revoked")
 )
 
 type UserUseCase struct {
-	repo *repository.UserRepository
+	repo          UserStore
+	tokenRepo     TokenStore
+	sessionRepo   SessionStore
+	apiKeyRepo    APIKeyStore
+	userTokenRepo UserTokenStore
+	twoFactorRepo TwoFactorStore
+	eventRepo     SecurityEventStore
+	mailer        mailer.Mailer
+	guard         LoginGuard
+	tokens        TokenIssuer
+	clock         Clock
+	app           AppInfo
 }
 
-func NewUserUseCase(repo *repository.UserRepository) *UserUseCase {
-	return &UserUseCase{repo: repo}
+func NewUserUseCase(
+	repo UserStore,
+	tokenRepo TokenStore,
+	sessionRepo SessionStore,
+	apiKeyRepo APIKeyStore,
+	userTokenRepo UserTokenStore,
+	twoFactorRepo TwoFactorStore,
+	eventRepo SecurityEventStore,
+	mailer mailer.Mailer,
+	guard LoginGuard,
+	tokens TokenIssuer,
+	clock Clock,
+	app AppInfo,
+) *UserUseCase {
+	return &UserUseCase{
+		repo:          repo,
+		tokenRepo:     tokenRepo,
+		sessionRepo:   sessionRepo,
+		apiKeyRepo:    apiKeyRepo,
+		userTokenRepo: userTokenRepo,
+		twoFactorRepo: twoFactorRepo,
+		eventRepo:     eventRepo,
+		mailer:        mailer,
+		guard:         guard,
+		tokens:        tokens,
+		clock:         clock,
+		app:           app,
+	}
 }
 
-func (u *UserUseCase) RegisterUser(input model.CreateUserInput) (*model.User, error) {
-	existing, err := u.repo.FindByEmail(input.Email)
+func (u *UserUseCase) RegisterUser(ctx context.Context, input model.CreateUserInput) (*model.User, error) {
+	existing, err := u.repo.FindByEmail(ctx, input.Email)
 	if err != nil {
 		return nil, err
 	}
 	if existing != nil {
-		return nil, errors.New("email already in use")
+		return nil, ErrEmailInUse
 	}
 
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(input.Password), bcrypt.DefaultCost)
@@ -37,31 +88,203 @@ func (u *UserUseCase) RegisterUser(input model.CreateUserInput) (*model.User, er
 		Password: string(hashedPassword),
 	}
 
-	if err := u.repo.Create(&user); err != nil {
+	if err := u.repo.Create(ctx, &user); err != nil {
 		return nil, err
 	}
 
+	// A failed send does not block sign-up; the user can ask for a resend.
+	logMailError(u.sendVerificationEmail(ctx, &user))
+
 	user.Password = ""
 	return &user, nil
 }
 
-func (u *UserUseCase) LoginUser(input model.LoginUserInput) (string, error) {
-	user, err := u.repo.FindByEmail(input.Email)
+// dummyPasswordHash is a bcrypt hash (default cost) of nobody's password, used
+// by LoginUser when the email does not exist.
+const dummyPasswordHash = "$2a$10$HRDiYEy1NTK1ka4KlSvuFeV1As./d2n7QxhSgdg1Esbrm3f5Isz6q"
+
+// LoginUser checks credentials. With 2FA on it returns only a pre-auth token
+// to exchange in VerifyTwoFactor with the code. Failures count per account and
+// per IP; past the limit the key is locked (loginguard.LockedError).
+func (u *UserUseCase) LoginUser(ctx context.Context, input model.LoginUserInput, meta model.SessionMetadata) (*model.LoginResult, error) {
+	account := loginguard.Account(strings.ToLower(input.Email))
+	subjects := []loginguard.Subject{account, loginguard.IP(meta.IP)}
+	if err := u.guard.Check(ctx, subjects...); err != nil {
+		return nil, err
+	}
+
+	user, err := u.repo.FindByEmail(ctx, input.Email)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 	if user == nil {
-		return "", errors.New("invalid credentials")
+		// Compare against a fixed hash so unknown emails take as long as a
+		// wrong password and cannot be found by timing.
+		_ = bcrypt.CompareHashAndPassword([]byte(dummyPasswordHash), []byte(input.Password))
+		u.registerLoginFailure(ctx, nil, meta, subjects)
+		return nil, errors.New("invalid credentials")
 	}
 
 	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(input.Password)); err != nil {
-		return "", errors.New("invalid credentials")
+		u.registerLoginFailure(ctx, user, meta, subjects)
+		return nil, errors.New("invalid credentials")
+	}
+
+	if err := u.guard.Reset(ctx, account); err != nil {
+		return nil, err
+	}
+
+	return u.completeLogin(ctx, user, meta)
+}
+
+// registerLoginFailure counts the failure and records a security event for each
+// lockout. Errors are only logged so they do not mask the invalid credentials
+// response.
+func (u *UserUseCase) registerLoginFailure(ctx context.Context, user *model.User, meta model.SessionMetadata, subjects []loginguard.Subject) {
+	lockouts, err := u.guard.Fail(ctx, subjects...)
+	if err != nil {
+		log.Println("⚠️ Failed to register login failure:", err)
+		return
+	}
+
+	for _, lockout := range lockouts {
+		event := model.SecurityEvent{
+			Type:    model.LoginLockoutEvent,
+			Subject: lockout.Key,
+			IP:      meta.IP,
+			Details: fmt.Sprintf("locked until %s after %d failed attempts", lockout.Until.Format(time.RFC3339), lockout.Failures),
+		}
+		if user != nil && !strings.HasPrefix(lockout.Key, "ip:") {
+			event.UserID = &user.ID
+		}
+		log.Printf("🔒 %s: %s", event.Subject, event.Details)
+		if err := u.eventRepo.Create(ctx, &event); err != nil {
+			log.Println("⚠️ Failed to record security event:", err)
+		}
+	}
+}
+
+// completeLogin opens the session or, with 2FA on, issues the pre-auth token.
+func (u *UserUseCase) completeLogin(ctx context.Context, user *model.User, meta model.SessionMetadata) (*model.LoginResult, error) {
+	if user.TwoFactorEnabled {
+		preAuthToken, err := u.tokens.IssuePreAuthToken(user.ID)
+		if err != nil {
+			return nil, err
+		}
+		return &model.LoginResult{TwoFactorRequired: true, PreAuthToken: preAuthToken}, nil
+	}
+
+	tokens, err := u.startSession(ctx, user.ID, meta)
+	if err != nil {
+		return nil, err
+	}
+	return &model.LoginResult{Tokens: tokens}, nil
+}
+
+func (u *UserUseCase) startSession(ctx context.Context, userID uuid.UUID, meta model.SessionMetadata) (*model.AuthTokens, error) {
+	session := model.Session{
+		UserID:     userID,
+		UserAgent:  meta.UserAgent,
+		IP:         meta.IP,
+		LastSeenAt: u.clock.Now(),
+	}
+	if err := u.sessionRepo.Create(ctx, &session); err != nil {
+		return nil, err
+	}
+
+	rawRefresh, refresh, err := u.newRefreshToken(userID, session.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := u.tokenRepo.CreateRefreshToken(ctx, refresh); err != nil {
+		return nil, err
+	}
+
+	return u.issueTokens(userID, session.ID, rawRefresh)
+}
+
+// RefreshToken rotates a valid refresh token into a new token pair. Replaying
+// an already rotated token revokes the whole session.
+func (u *UserUseCase) RefreshToken(ctx context.Context, input model.RefreshTokenInput) (*model.AuthTokens, error) {
+	current, err := u.tokenRepo.FindRefreshTokenByHash(ctx, utils.HashToken(input.RefreshToken))
+	if err != nil {
+		return nil, err
+	}
+	if current == nil {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	if current.RevokedAt != nil {
+		if current.ReplacedByID != nil {
+			if err := u.sessionRepo.Revoke(ctx, current.SessionID); err != nil {
+				return nil, err
+			}
+			return nil, ErrRefreshTokenReused
+		}
+		return nil, ErrInvalidRefreshToken
+	}
+	if u.clock.Now().After(current.ExpiresAt) {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	rawRefresh, next, err := u.newRefreshToken(current.UserID, current.SessionID)
+	if err != nil {
+		return nil, err
+	}
+	if err := u.tokenRepo.RotateRefreshToken(ctx, current, next); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			if err := u.sessionRepo.Revoke(ctx, current.SessionID); err != nil {
+				return nil, err
+			}
+			return nil, ErrRefreshTokenReused
+		}
+		return nil, err
+	}
+
+	if err := u.sessionRepo.Touch(ctx, current.SessionID); err != nil {
+		return nil, err
+	}
+
+	return u.issueTokens(current.UserID, current.SessionID, rawRefresh)
+}
+
+// Logout denylists the current access token (jti) and ends its session.
+func (u *UserUseCase) Logout(ctx context.Context, sessionID string, jti string, expiresAt time.Time) error {
+	if err := u.tokenRepo.RevokeJTI(ctx, jti, expiresAt); err != nil {
+		return err
 	}
 
-	token, err := utils.GenerateToken(user.ID)
+	if id, err := uuid.Parse(sessionID); err == nil {
+		if err := u.sessionRepo.Revoke(ctx, id); err != nil {
+			return err
+		}
+	}
+
+	return u.tokenRepo.DeleteExpiredRevocations(ctx)
+}
+
+func (u *UserUseCase) newRefreshToken(userID uuid.UUID, sessionID uuid.UUID) (string, *model.RefreshToken, error) {
+	raw, hash, err := utils.GenerateOpaqueToken()
 	if err != nil {
-		return "", err
+		return "", nil, err
 	}
+	return raw, &model.RefreshToken{
+		UserID:    userID,
+		SessionID: sessionID,
+		TokenHash: hash,
+		ExpiresAt: u.clock.Now().Add(authtoken.RefreshTokenTTL),
+	}, nil
+}
 
-	return token, nil
+func (u *UserUseCase) issueTokens(userID uuid.UUID, sessionID uuid.UUID, rawRefresh string) (*model.AuthTokens, error) {
+	accessToken, err := u.tokens.IssueAccessToken(userID, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	return &model.AuthTokens{
+		AccessToken:  accessToken,
+		RefreshToken: rawRefresh,
+		TokenType:    "Bearer",
+		ExpiresIn:    int64(authtoken.AccessTokenTTL.Seconds()),
+	}, nil
 }
@@ -0,0 +1,239 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"financial-track/loginguard"
+	"financial-track/model"
+	"financial-track/utils"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const testPassword = "correct horse battery"
+
+// userFakes are the stores behind a test UserUseCase.
+type userFakes struct {
+	alice      *model.User
+	users      *fakeUserStore
+	tokens     *fakeTokenStore
+	sessions   *fakeSessionStore
+	apiKeys    *fakeAPIKeyStore
+	userTokens *fakeUserTokenStore
+	events     *fakeSecurityEventStore
+	mailer     *fakeMailer
+	clock      *fixedClock
+}
+
+// newUserUseCaseWithFakes builds a UserUseCase over fakes holding
+// alice@example.com with password testPassword.
+func newUserUseCaseWithFakes(t *testing.T, guard LoginGuard) (*UserUseCase, *userFakes) {
+	t.Helper()
+	hash, err := bcrypt.GenerateFromPassword([]byte(testPassword), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	alice := &model.User{ID: uuid.New(), Name: "Alice", Email: "alice@example.com", Password: string(hash)}
+	f := &userFakes{
+		alice:      alice,
+		users:      &fakeUserStore{users: map[string]*model.User{alice.Email: alice}},
+		tokens:     newFakeTokenStore(),
+		sessions:   &fakeSessionStore{},
+		apiKeys:    newFakeAPIKeyStore(),
+		userTokens: newFakeUserTokenStore(),
+		events:     &fakeSecurityEventStore{},
+		mailer:     &fakeMailer{},
+		clock:      &fixedClock{now: time.Date(2025, 10, 15, 12, 0, 0, 0, time.UTC)},
+	}
+
+	uc := NewUserUseCase(f.users, f.tokens, f.sessions, f.apiKeys, f.userTokens, nil, f.events, f.mailer, guard, fakeTokenIssuer{}, f.clock,
+		AppInfo{Name: "Financial Track", URL: "http://localhost:81"})
+	return uc, f
+}
+
+func newTestUserUseCase(t *testing.T, guard LoginGuard) (*UserUseCase, *fakeTokenStore, *fakeSessionStore, *fakeSecurityEventStore) {
+	t.Helper()
+	uc, f := newUserUseCaseWithFakes(t, guard)
+	return uc, f.tokens, f.sessions, f.events
+}
+
+func TestLoginUser(t *testing.T) {
+	meta := model.SessionMetadata{IP: "203.0.113.7", UserAgent: "test"}
+
+	tests := []struct {
+		name         string
+		attempts     []string
+		wantLocked   bool
+		wantSession  bool
+		wantLockouts int
+	}{
+		{name: "correct password", attempts: []string{testPassword}, wantSession: true},
+		{name: "wrong password", attempts: []string{"nope"}},
+		{name: "success resets failures", attempts: repeat("nope", loginguard.AccountPolicy.MaxAttempts-1, testPassword, "nope", testPassword), wantSession: true},
+		{name: "locked after limit", attempts: repeat("nope", loginguard.AccountPolicy.MaxAttempts, testPassword), wantLocked: true, wantLockouts: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc, _, sessions, events := newTestUserUseCase(t, newFakeLoginGuard())
+
+			var result *model.LoginResult
+			var err error
+			for _, password := range tt.attempts {
+				result, err = uc.LoginUser(context.Background(), model.LoginUserInput{Email: "alice@example.com", Password: password}, meta)
+			}
+
+			if _, locked := loginguard.IsLocked(err); locked != tt.wantLocked {
+				t.Fatalf("last attempt err = %v, locked = %v, want %v", err, locked, tt.wantLocked)
+			}
+			if tt.wantSession {
+				if err != nil || result.Tokens == nil || result.Tokens.RefreshToken == "" {
+					t.Fatalf("LoginUser = %+v, %v, want tokens", result, err)
+				}
+			} else if err == nil {
+				t.Fatal("LoginUser succeeded, want error")
+			}
+			if (len(sessions.created) > 0) != tt.wantSession {
+				t.Errorf("sessions created = %d", len(sessions.created))
+			}
+			if len(events.events) != tt.wantLockouts {
+				t.Errorf("security events = %d, want %d", len(events.events), tt.wantLockouts)
+			}
+		})
+	}
+}
+
+func TestLoginUserUnknownEmailCountsAsFailure(t *testing.T) {
+	guard := newFakeLoginGuard()
+	uc, _, _, _ := newTestUserUseCase(t, guard)
+
+	_, err := uc.LoginUser(context.Background(), model.LoginUserInput{Email: "Bob@example.com", Password: "x"}, model.SessionMetadata{IP: "203.0.113.7"})
+	if err == nil {
+		t.Fatal("login with unknown email succeeded")
+	}
+	if guard.failures["account:bob@example.com"] != 1 || guard.failures["ip:203.0.113.7"] != 1 {
+		t.Fatalf("failures = %v, want one per account and IP", guard.failures)
+	}
+}
+
+// repeat returns n copies of attempt followed by then.
+func repeat(attempt string, n int, then ...string) []string {
+	attempts := make([]string, 0, n+len(then))
+	for i := 0; i < n; i++ {
+		attempts = append(attempts, attempt)
+	}
+	return append(attempts, then...)
+}
+
+// loginForRefresh opens a session and returns its refresh token.
+func loginForRefresh(t *testing.T, uc *UserUseCase) string {
+	t.Helper()
+	result, err := uc.LoginUser(context.Background(), model.LoginUserInput{Email: "alice@example.com", Password: testPassword}, model.SessionMetadata{IP: "203.0.113.7"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return result.Tokens.RefreshToken
+}
+
+// staleTokenStore returns the refresh token as it was before rotation, like two
+// concurrent requests with the same token.
+type staleTokenStore struct {
+	*fakeTokenStore
+	snapshot *model.RefreshToken
+}
+
+func (s *staleTokenStore) FindRefreshTokenByHash(ctx context.Context, hash string) (*model.RefreshToken, error) {
+	if s.snapshot != nil && s.snapshot.TokenHash == hash {
+		copied := *s.snapshot
+		return &copied, nil
+	}
+	return s.fakeTokenStore.FindRefreshTokenByHash(ctx, hash)
+}
+
+func TestRefreshToken(t *testing.T) {
+	tests := []struct {
+		name string
+		// setup returns the refresh token presented after login.
+		setup       func(t *testing.T, uc *UserUseCase, tokens *fakeTokenStore, first string) string
+		want        error
+		wantRevoked bool
+	}{
+		{
+			name:  "rotates a valid token",
+			setup: func(t *testing.T, uc *UserUseCase, tokens *fakeTokenStore, first string) string { return first },
+		},
+		{
+			name: "unknown token",
+			setup: func(t *testing.T, uc *UserUseCase, tokens *fakeTokenStore, first string) string {
+				return "not-issued"
+			},
+			want: ErrInvalidRefreshToken,
+		},
+		{
+			name: "expired token",
+			setup: func(t *testing.T, uc *UserUseCase, tokens *fakeTokenStore, first string) string {
+				tokens.tokens[utils.HashToken(first)].ExpiresAt = uc.clock.Now().Add(-time.Second)
+				return first
+			},
+			want: ErrInvalidRefreshToken,
+		},
+		{
+			name: "reused after rotation revokes the session",
+			setup: func(t *testing.T, uc *UserUseCase, tokens *fakeTokenStore, first string) string {
+				if _, err := uc.RefreshToken(context.Background(), model.RefreshTokenInput{RefreshToken: first}); err != nil {
+					t.Fatal(err)
+				}
+				return first
+			},
+			want:        ErrRefreshTokenReused,
+			wantRevoked: true,
+		},
+		{
+			name: "concurrent rotation revokes the session",
+			setup: func(t *testing.T, uc *UserUseCase, tokens *fakeTokenStore, first string) string {
+				snapshot, _ := tokens.FindRefreshTokenByHash(context.Background(), utils.HashToken(first))
+				if _, err := uc.RefreshToken(context.Background(), model.RefreshTokenInput{RefreshToken: first}); err != nil {
+					t.Fatal(err)
+				}
+				uc.tokenRepo = &staleTokenStore{fakeTokenStore: tokens, snapshot: snapshot}
+				return first
+			},
+			want:        ErrRefreshTokenReused,
+			wantRevoked: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc, tokens, sessions, _ := newTestUserUseCase(t, newFakeLoginGuard())
+			first := loginForRefresh(t, uc)
+			sessionID := sessions.created[0].ID
+			presented := tt.setup(t, uc, tokens, first)
+
+			got, err := uc.RefreshToken(context.Background(), model.RefreshTokenInput{RefreshToken: presented})
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("RefreshToken = %v, want %v", err, tt.want)
+			}
+			revoked := len(sessions.revoked) == 1 && sessions.revoked[0] == sessionID
+			if revoked != tt.wantRevoked {
+				t.Fatalf("session revoked = %v (%v), want %v", revoked, sessions.revoked, tt.wantRevoked)
+			}
+			if tt.want != nil {
+				return
+			}
+
+			if got.RefreshToken == "" || got.RefreshToken == first {
+				t.Fatalf("refresh token not rotated: %q", got.RefreshToken)
+			}
+			old := tokens.tokens[utils.HashToken(first)]
+			next := tokens.tokens[utils.HashToken(got.RefreshToken)]
+			if old.RevokedAt == nil || old.ReplacedByID == nil || next == nil || *old.ReplacedByID != next.ID {
+				t.Fatalf("old token = %+v, next = %+v, want old replaced by next", old, next)
+			}
+			if next.SessionID != sessionID {
+				t.Errorf("next token in session %s, want %s", next.SessionID, sessionID)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package utils
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+type testCursor struct {
+	Sort   string        `json:"s"`
+	Values []interface{} `json:"v"`
+}
+
+func TestCursorCodecRoundTrip(t *testing.T) {
+	codec := NewCursorCodec([]byte("0123456789abcdef0123456789abcdef"))
+
+	cursor, err := codec.Encode(testCursor{Sort: "-transactionAt,id", Values: []interface{}{"2025-10-05T17:19:00Z", "a1"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got testCursor
+	if err := codec.Decode(cursor, &got); err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	if got.Sort != "-transactionAt,id" || len(got.Values) != 2 || got.Values[1] != "a1" {
+		t.Fatalf("Decode = %+v", got)
+	}
+}
+
+func TestCursorCodecRejectsInvalidCursors(t *testing.T) {
+	codec := NewCursorCodec([]byte("0123456789abcdef0123456789abcdef"))
+	cursor, err := codec.Encode(testCursor{Sort: "amount,id", Values: []interface{}{10.5, "a1"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, sig, _ := strings.Cut(cursor, ".")
+	forged, err := NewCursorCodec([]byte("another-secret-another-secret-00")).Encode(testCursor{Sort: "amount,id"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		cursor string
+	}{
+		{"empty", ""},
+		{"without signature", body},
+		{"tampered body", "x" + body + "." + sig},
+		{"tampered signature", body + "." + strings.ToUpper(sig)},
+		{"signed with another secret", forged},
+		{"not json", "bm90LWpzb24." + codec.sign("bm90LWpzb24")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got testCursor
+			if err := codec.Decode(tt.cursor, &got); !errors.Is(err, ErrInvalidCursor) {
+				t.Fatalf("Decode(%q) = %v, want ErrInvalidCursor", tt.cursor, err)
+			}
+		})
+	}
+}
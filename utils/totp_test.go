@@ -0,0 +1,83 @@
+package utils
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfc6238Secret is the RFC 6238 SHA1 test key ("12345678901234567890") in
+// base32.
+const rfc6238Secret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestValidateTOTP(t *testing.T) {
+	tests := []struct {
+		name     string
+		secret   string
+		code     string
+		now      int64
+		wantStep int64
+		wantOK   bool
+	}{
+		// The RFC vectors have 8 digits; the last 6 are the 6-digit code.
+		{name: "rfc vector 59", secret: rfc6238Secret, code: "287082", now: 59, wantStep: 1, wantOK: true},
+		{name: "rfc vector 1111111109", secret: rfc6238Secret, code: "081804", now: 1111111109, wantStep: 37037036, wantOK: true},
+		{name: "rfc vector 1234567890", secret: rfc6238Secret, code: "005924", now: 1234567890, wantStep: 41152263, wantOK: true},
+		{name: "lowercase secret and spaces", secret: "gezdgnbvgy3tqojqgezdgnbvgy3tqojq", code: " 287082 ", now: 59, wantStep: 1, wantOK: true},
+		{name: "previous step accepted", secret: rfc6238Secret, code: "287082", now: 59 + 30, wantStep: 1, wantOK: true},
+		{name: "next step accepted", secret: rfc6238Secret, code: "287082", now: 59 - 30, wantStep: 1, wantOK: true},
+		{name: "two steps late", secret: rfc6238Secret, code: "287082", now: 59 + 60},
+		{name: "wrong code", secret: rfc6238Secret, code: "287083", now: 59},
+		{name: "wrong length", secret: rfc6238Secret, code: "94287082", now: 59},
+		{name: "invalid secret", secret: "not base32!", code: "287082", now: 59},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			step, ok := ValidateTOTP(tt.secret, tt.code, time.Unix(tt.now, 0))
+			if ok != tt.wantOK || step != tt.wantStep {
+				t.Fatalf("ValidateTOTP = %d, %v, want %d, %v", step, ok, tt.wantStep, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestGenerateTOTPSecretValidatesItsOwnCodes(t *testing.T) {
+	secret, err := GenerateTOTPSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := base32NoPadding.DecodeString(secret)
+	if err != nil || len(key) != 20 {
+		t.Fatalf("secret %q decodes to %d bytes (%v), want 20", secret, len(key), err)
+	}
+
+	now := time.Now()
+	if _, ok := ValidateTOTP(secret, totpCode(key, now.Unix()/totpPeriod), now); !ok {
+		t.Fatal("current code rejected")
+	}
+}
+
+func TestRecoveryCodes(t *testing.T) {
+	codes, err := GenerateRecoveryCodes(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	format := regexp.MustCompile(`^[a-z2-7]{5}-[a-z2-7]{5}$`)
+	seen := map[string]bool{}
+	for _, code := range codes {
+		if !format.MatchString(code) {
+			t.Errorf("code %q does not match xxxxx-xxxxx", code)
+		}
+		if seen[code] {
+			t.Errorf("duplicated code %q", code)
+		}
+		seen[code] = true
+		if NormalizeRecoveryCode(" "+strings.ToUpper(code)+" ") != code {
+			t.Errorf("NormalizeRecoveryCode does not recover %q", code)
+		}
+	}
+	if len(codes) != 10 {
+		t.Fatalf("got %d codes, want 10", len(codes))
+	}
+}
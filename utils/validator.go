@@ -9,61 +9,90 @@ import (
 	"financial-track/model"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
 	"github.com/go-playground/validator/v10"
 )
 
 func ValidateJSON(ctx *gin.Context, obj interface{}) map[string]string {
 	if err := ctx.ShouldBind(obj); err != nil {
-		out := make(map[string]string)
+		return bindingErrors(obj, err)
+	}
 
-		var ve validator.ValidationErrors
-		if errors.As(err, &ve) {
-			for _, fe := range ve {
-				out[fe.Field()] = validationMessage(fe)
-			}
-			// Complementa com campos required que não apareceram no ValidationErrors
-			val := reflect.ValueOf(obj).Elem()
-			typ := val.Type()
-			for i := 0; i < val.NumField(); i++ {
-				field := typ.Field(i)
-				tag := field.Tag.Get("binding")
-				if (tag == "required" || containsRequired(tag)) && out[field.Name] == "" {
-					out[field.Name] = "This field is required"
-				}
-			}
-			return out
-		}
+	return nil
+}
 
-		var tpe *time.ParseError
-		if errors.As(err, &tpe) {
-			addTimeFormatErrors(obj, out)
-			return out
-		}
+// ValidateJSONBytes decodes data into obj and checks its binding tags, with
+// errors shaped like ValidateJSON's. Used for array items.
+func ValidateJSONBytes(data []byte, obj interface{}) map[string]string {
+	err := json.Unmarshal(data, obj)
+	if err == nil {
+		err = binding.Validator.ValidateStruct(obj)
+	}
+	if err != nil {
+		return bindingErrors(obj, err)
+	}
 
-		var ute *json.UnmarshalTypeError
-		if errors.As(err, &ute) {
-			field := ute.Field
-			if field == "" {
-				field = "body"
-			}
-			out[field] = "Invalid type"
-			return out
-		}
+	return nil
+}
 
+// ValidateStruct checks the binding tags of an already filled obj (e.g. by the
+// CLI).
+func ValidateStruct(obj interface{}) map[string]string {
+	if err := binding.Validator.ValidateStruct(obj); err != nil {
+		return bindingErrors(obj, err)
+	}
+
+	return nil
+}
+
+func bindingErrors(obj interface{}, err error) map[string]string {
+	out := make(map[string]string)
+
+	var ve validator.ValidationErrors
+	if errors.As(err, &ve) {
+		for _, fe := range ve {
+			out[fe.Field()] = validationMessage(fe)
+		}
+		// Complementa com campos required que não apareceram no ValidationErrors
 		val := reflect.ValueOf(obj).Elem()
 		typ := val.Type()
 		for i := 0; i < val.NumField(); i++ {
 			field := typ.Field(i)
 			tag := field.Tag.Get("binding")
-			if tag == "required" || containsRequired(tag) {
+			if (tag == "required" || containsRequired(tag)) && out[field.Name] == "" {
 				out[field.Name] = "This field is required"
 			}
 		}
+		return out
+	}
 
+	var tpe *time.ParseError
+	if errors.As(err, &tpe) {
+		addTimeFormatErrors(obj, out)
 		return out
 	}
 
-	return nil
+	var ute *json.UnmarshalTypeError
+	if errors.As(err, &ute) {
+		field := ute.Field
+		if field == "" {
+			field = "body"
+		}
+		out[field] = "Invalid type"
+		return out
+	}
+
+	val := reflect.ValueOf(obj).Elem()
+	typ := val.Type()
+	for i := 0; i < val.NumField(); i++ {
+		field := typ.Field(i)
+		tag := field.Tag.Get("binding")
+		if tag == "required" || containsRequired(tag) {
+			out[field.Name] = "This field is required"
+		}
+	}
+
+	return out
 }
 
 func containsRequired(tag string) bool {